@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+type saveConflict struct {
+	Error          string `json:"error"`
+	CurrentSHA256  string `json:"current_sha256"`
+	Merged         string `json:"merged"`
+	MergeConflicts bool   `json:"merge_conflicts"`
+	Diff           string `json:"diff"`
+}
+
+// editAsset appends line to an editable asset and saves it back, the way
+// the app's editor does. delay simulates the user typing, so a save that
+// races another edit can be tried.
+func editAsset(hostAddr, path, line string, delay time.Duration) {
+	fullURL := fmt.Sprintf("http://%s%s", hostAddr, path)
+	resp, err := http.Get(fullURL)
+	if err != nil {
+		log.Printf("  [Edit] FAILED: %v", err)
+		return
+	}
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	log.Printf("  [Edit] Opened %d bytes, version %s", len(content), etag)
+
+	time.Sleep(delay)
+	content = append(content, []byte(line+"\n")...)
+	for attempt := 0; attempt < 2; attempt++ {
+		req, _ := http.NewRequest(http.MethodPut, fullURL, bytes.NewReader(content))
+		req.Header.Set("If-Match", etag)
+		req.Header.Set("X-Zelland-PSK", *psk)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Printf("  [Edit] Save FAILED: %v", err)
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusConflict {
+			log.Printf("  [Edit] Save status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+			return
+		}
+		var conflict saveConflict
+		json.Unmarshal(body, &conflict)
+		log.Printf("  [Edit] CONFLICT: %s\n%s", conflict.Error, conflict.Diff)
+		if conflict.Merged == "" || conflict.MergeConflicts {
+			log.Printf("  [Edit] Merge needs a person:\n%s", conflict.Merged)
+			return
+		}
+		// Accept the clean merge, as a user tapping "keep both" would
+		log.Printf("  [Edit] Saving the merged version")
+		content, etag = []byte(conflict.Merged), `"`+conflict.CurrentSHA256+`"`
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,8 +19,30 @@ import (
 )
 
 var (
-	host = flag.String("host", "localhost", "Daemon host")
-	port = flag.Int("port", 8083, "Daemon port")
+	host        = flag.String("host", "localhost", "Daemon host")
+	port        = flag.Int("port", 8083, "Daemon port")
+	logFilter   = flag.String("log-filter", "", "Regex filter applied to log views")
+	lastSeq     = flag.Uint64("resume", 0, "Resume from this envelope seq (replays missed messages)")
+	noAck       = flag.Bool("no-ack", false, "Do not acknowledge requests (simulates a frozen app)")
+	deviceName  = flag.String("name", "mock", "Device name sent in Hello")
+	deviceID    = flag.String("id", "mock-device", "Stable device ID sent in Hello")
+	session     = flag.String("session", "", "Zellij session the simulated terminal tab is attached to")
+	psk         = flag.String("psk", "", "Pre-shared key sent in the X-Zelland-PSK header")
+	zellijWeb   = flag.String("zellij-web", "", "Send a zellij web request after connecting: status, start, stop, restart or create_token")
+	capture     = flag.String("capture", "", "Request a scrollback capture of this Zellij session after connecting")
+	actionCmd   = flag.String("action", "", "Send a zellij action after connecting, e.g. \"new-pane --floating\" or \"run -- htop\"")
+	quickCmd    = flag.String("quick", "", "Run a quick action after connecting: \"name\", \"name confirm\" or \"name cancel\"")
+	answer      = flag.String("answer", "", "Answer prompts with this (default: the first choice; \"-\" dismisses)")
+	clipboard   = flag.String("clipboard", "mock clipboard", "Initial clipboard shared on paste requests (\"-\" declines them)")
+	uploadPath  = flag.String("upload", "", "Upload this file to the inbox after connecting, and in answer to `zelland receive`")
+	chunkSize   = flag.Int64("chunk", 64*1024, "Upload chunk size in bytes")
+	browseCmd   = flag.String("browse", "", "Send a browse request after connecting: \"list\", \"list ROOT [PATH]\", \"search ROOT QUERY\" or \"open ROOT PATH\"")
+	annUser     = flag.String("ann-user", "", "Claim this user on the simulated annotation (the daemon rejects any but the client's own)")
+	annotateAll = flag.Bool("annotate-all", false, "Also annotate image, PDF and code views, with rect, page and line anchors")
+	thread      = flag.Bool("thread", false, "Reply to, resolve and prune the simulated annotation")
+	editLine    = flag.String("edit", "", "Append this line to markdown and code views and save them back")
+	editDelay   = flag.Duration("edit-delay", 0, "Wait this long between opening and saving an edit")
+	sessionCmd  = flag.String("sessions", "", "Send a session request after connecting, e.g. \"list\", \"create work compact\", \"rename work play\", \"kill work\", \"delete work\"")
 )
 
 func main() {
@@ -31,12 +55,40 @@ func main() {
 	u := url.URL{Scheme: "ws", Host: addr, Path: "/ws"}
 	log.Printf("Connecting to %s", u.String())
 
-	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	header := http.Header{}
+	if *psk != "" {
+		header.Set("X-Zelland-PSK", *psk)
+	}
+	c, _, err := websocket.DefaultDialer.Dial(u.String(), header)
 	if err != nil {
 		log.Fatal("dial:", err)
 	}
 	defer c.Close()
 
+	sendHello(c, *lastSeq)
+	sendStatus(c)
+	if *zellijWeb != "" {
+		sendZellijWeb(c, *zellijWeb)
+	}
+	if *capture != "" {
+		sendCaptureRequest(c, *capture)
+	}
+	if *actionCmd != "" {
+		sendActionRequest(c, strings.Fields(*actionCmd))
+	}
+	if *quickCmd != "" {
+		sendRunQuickAction(c, strings.Fields(*quickCmd))
+	}
+	if *sessionCmd != "" {
+		sendSessionRequest(c, strings.Fields(*sessionCmd))
+	}
+	if *uploadPath != "" {
+		go uploadFile(addr, *uploadPath, "")
+	}
+	if *browseCmd != "" {
+		sendBrowseRequest(c, strings.Fields(*browseCmd))
+	}
+
 	done := make(chan struct{})
 
 	go func() {
@@ -47,7 +99,7 @@ func main() {
 				log.Println("read:", err)
 				return
 			}
-			
+
 			var env pb.Envelope
 			if err := proto.Unmarshal(message, &env); err != nil {
 				log.Println("unmarshal:", err)
@@ -79,6 +131,10 @@ func main() {
 }
 
 func handleMessage(c *websocket.Conn, env *pb.Envelope, hostAddr string) {
+	log.Printf("[SEQ %d]", env.Seq)
+	if env.RequestId != "" && !*noAck && !isReply(env) {
+		defer sendAck(c, env.RequestId)
+	}
 	switch payload := env.Payload.(type) {
 	case *pb.Envelope_Ping:
 		log.Printf("[PING] Timestamp: %d", payload.Ping.Timestamp)
@@ -100,27 +156,211 @@ func handleMessage(c *websocket.Conn, env *pb.Envelope, hostAddr string) {
 				log.Println("  [Sim] User reading...")
 				time.Sleep(2 * time.Second)
 				log.Println("  [Sim] User creating annotation...")
-				sendAnnotation(c, payload.OpenView.AssetId)
+				sendAnnotation(c, payload.OpenView.AssetId, nil)
 			}()
+		} else if anchor := simulatedAnchor(payload.OpenView.FileType); anchor != nil && *annotateAll {
+			go func() {
+				time.Sleep(time.Second)
+				log.Printf("  [Sim] User marking %s...", anchor.Kind)
+				sendAnnotation(c, payload.OpenView.AssetId, anchor)
+			}()
+		}
+
+		ft := payload.OpenView.FileType
+		if *editLine != "" && (ft == pb.OpenViewRequest_MARKDOWN || ft == pb.OpenViewRequest_CODE) {
+			go editAsset(hostAddr, payload.OpenView.Url, *editLine, *editDelay)
+		}
+
+		// Log views stream over the WebSocket once subscribed
+		if payload.OpenView.FileType == pb.OpenViewRequest_LOG {
+			sendLogSubscribe(c, payload.OpenView.AssetId)
+		}
+
+	case *pb.Envelope_Log:
+		for _, line := range payload.Log.Lines {
+			log.Printf("[LOG %s #%d] %s", payload.Log.StreamId, line.Seq, line.Text)
+		}
+		if payload.Log.Event != pb.LogChunk_NONE {
+			log.Printf("[LOG %s] %s", payload.Log.StreamId, payload.Log.Event)
+		}
+
+	case *pb.Envelope_Welcome:
+		log.Printf("[WELCOME] Server %s, protocol v%d, features %v, actions %v",
+			payload.Welcome.ServerVersion, payload.Welcome.ProtocolVersion, payload.Welcome.Features, payload.Welcome.ZellijActions)
+		for _, qa := range payload.Welcome.QuickActions {
+			log.Printf("  [QUICK] %s: %s (confirm %v, output %s)", qa.Name, qa.Command, qa.Confirm, qa.Output)
+		}
+
+	case *pb.Envelope_Notification:
+		log.Printf(">>> NOTIFICATION (%s) <<<", payload.Notification.Urgency)
+		log.Printf("  Title: %s", payload.Notification.Title)
+		log.Printf("  Body:  %s", payload.Notification.Body)
+		if payload.Notification.Action != nil {
+			log.Printf("  Opens: %s", payload.Notification.Action.Url)
 		}
 
 	case *pb.Envelope_Annotation:
 		log.Printf(">>> ANNOTATION RECEIVED <<<")
 		log.Printf("  File: %s", payload.Annotation.FilePath)
 		log.Printf("  Type: %s", payload.Annotation.Type)
-		if payload.Annotation.Data != nil {
-			log.Printf("  Body: %s", payload.Annotation.Data.Body)
+		if d := payload.Annotation.Data; d != nil {
+			log.Printf("  Body: %s (by %s)", d.Body, d.User)
+			log.Printf("  Status: %s %s", d.Status, d.ResolvedBy)
+			if d.Anchor != nil {
+				log.Printf("  Anchor: %v", d.Anchor)
+			}
+			for _, r := range d.Replies {
+				log.Printf("    Reply %s to %s by %s: %s", r.Id, r.ParentId, r.User, r.Body)
+			}
+		}
+
+	case *pb.Envelope_ZellijWebResponse:
+		r := payload.ZellijWebResponse
+		log.Printf(">>> ZELLIJ WEB (request %s) <<<", env.RequestId)
+		log.Printf("  Running: %v (supervised %v, pid %d, port %d, restarts %d)", r.Running, r.Supervised, r.Pid, r.Port, r.Restarts)
+		if r.LastExit != "" {
+			log.Printf("  Last exit: %s", r.LastExit)
+		}
+		if r.Token != "" {
+			log.Printf("  Token: %s = %s", r.TokenName, r.Token)
+		}
+		for _, line := range r.LogLines {
+			log.Printf("  | %s", line)
+		}
+		if r.Error != "" {
+			log.Printf("  Error: %s", r.Error)
+		}
+
+	case *pb.Envelope_SessionResponse:
+		log.Printf(">>> SESSIONS (request %s) <<<", env.RequestId)
+		for _, sess := range payload.SessionResponse.Sessions {
+			logSession("  ", sess)
+		}
+		if payload.SessionResponse.Error != "" {
+			log.Printf("  Error: %s", payload.SessionResponse.Error)
+		}
+
+	case *pb.Envelope_CaptureResponse:
+		log.Printf(">>> CAPTURE (request %s) <<<", env.RequestId)
+		if v := payload.CaptureResponse.View; v != nil {
+			log.Printf("  Title: %s", v.Title)
+			log.Printf("  URL:   %s", v.Url)
+			go verifyAsset(hostAddr, v.Url)
+		}
+		if payload.CaptureResponse.Error != "" {
+			log.Printf("  Error: %s", payload.CaptureResponse.Error)
+		}
+
+	case *pb.Envelope_BrowseResponse:
+		r := payload.BrowseResponse
+		log.Printf(">>> BROWSE %s %q (request %s), %d of %d entries <<<", r.Root, r.Path, env.RequestId, len(r.Entries), r.Total)
+		log.Printf("  Roots: %v", r.Roots)
+		for _, e := range r.Entries {
+			kind := e.FileType.String()
+			if e.IsDir {
+				kind = "DIR"
+			}
+			log.Printf("  %-8s %10d %s  %s", kind, e.Size, time.Unix(e.ModifiedAt, 0).Format(time.DateTime), e.Path)
+		}
+		if v := r.View; v != nil {
+			log.Printf("  Open %s as %s: %s", v.Title, v.FileType, v.Url)
+			go verifyAsset(hostAddr, v.Url)
+		}
+		if r.Error != "" {
+			log.Printf("  Error: %s", r.Error)
+		}
+
+	case *pb.Envelope_ActionResponse:
+		r := payload.ActionResponse
+		log.Printf(">>> ACTION (request %s) exit %d <<<", env.RequestId, r.ExitCode)
+		if r.Output != "" {
+			log.Printf("  Output: %s", r.Output)
+		}
+		if r.Error != "" {
+			log.Printf("  Error: %s", r.Error)
+		}
+
+	case *pb.Envelope_QuickActionResult:
+		r := payload.QuickActionResult
+		log.Printf(">>> QUICK ACTION %s (request %s) exit %d after %dms <<<", r.Name, env.RequestId, r.ExitCode, r.DurationMs)
+		if r.Error != "" {
+			log.Printf("  Error: %s", r.Error)
+		}
+
+	case *pb.Envelope_Prompt:
+		p := payload.Prompt
+		log.Printf(">>> PROMPT %s <<<", p.Id)
+		log.Printf("  Question: %s", p.Question)
+		log.Printf("  Choices:  %v", p.Choices)
+		go func() {
+			time.Sleep(time.Second) // Simulate the user thinking
+			sendPromptResponse(c, p)
+		}()
+
+	case *pb.Envelope_PromptClosed:
+		log.Printf("[PROMPT %s closed]", payload.PromptClosed.Id)
+
+	case *pb.Envelope_ClipboardSet:
+		cs := payload.ClipboardSet
+		log.Printf(">>> CLIPBOARD SET %s (%s, %d bytes) <<<", cs.Id, cs.MimeType, len(cs.Data))
+		if strings.HasPrefix(cs.MimeType, "text/") {
+			log.Printf("  Text: %q", cs.Data)
+		}
+		clipboardMu.Lock()
+		clipboardType, clipboardData = cs.MimeType, cs.Data
+		clipboardMu.Unlock()
+
+	case *pb.Envelope_ClipboardRequest:
+		cr := payload.ClipboardRequest
+		log.Printf(">>> CLIPBOARD REQUEST %s (max %d bytes) <<<", cr.Id, cr.MaxBytes)
+		go func() {
+			time.Sleep(time.Second) // Simulate the user confirming
+			sendClipboardContent(c, cr)
+		}()
+
+	case *pb.Envelope_ReceiveRequest:
+		rr := payload.ReceiveRequest
+		log.Printf(">>> RECEIVE REQUEST %s into %s <<<", rr.Id, rr.Dir)
+		if *uploadPath != "" {
+			go uploadFile(hostAddr, *uploadPath, rr.Id)
 		}
 
+	case *pb.Envelope_AssetUpdated:
+		au := payload.AssetUpdated
+		log.Printf("[ASSET UPDATED] %s is now %s (%d bytes), saved by %s", au.AssetId, au.Sha256, au.Size, au.UpdatedBy)
+
+	case *pb.Envelope_AnnotationWarning:
+		aw := payload.AnnotationWarning
+		log.Printf("[ANNOTATION WARNING] %s: %s", aw.FilePath, aw.Message)
+
+	case *pb.Envelope_SessionEvent:
+		logSession("[SESSION "+payload.SessionEvent.Type.String()+"] ", payload.SessionEvent.Session)
+
 	default:
 		log.Printf("Received unknown message: %T", payload)
 	}
 }
 
+func logSession(prefix string, sess *pb.ZellijSession) {
+	log.Printf("%s%s (created %s, %d attached, exited %v)", prefix, sess.Name,
+		time.Unix(sess.CreatedAt, 0).Format(time.DateTime), sess.AttachedClients, sess.Exited)
+}
+
+// isReply reports whether env answers one of our own requests, which are
+// not acknowledged.
+func isReply(env *pb.Envelope) bool {
+	switch env.Payload.(type) {
+	case *pb.Envelope_ZellijWebResponse, *pb.Envelope_SessionResponse, *pb.Envelope_CaptureResponse,
+		*pb.Envelope_ActionResponse, *pb.Envelope_QuickActionResult, *pb.Envelope_BrowseResponse:
+		return true
+	}
+	return false
+}
+
 func verifyAsset(hostAddr, path string) {
 	fullURL := fmt.Sprintf("http://%s%s", hostAddr, path)
 	log.Printf("  [Verify] Fetching %s...", fullURL)
-	
+
 	resp, err := http.Get(fullURL)
 	if err != nil {
 		log.Printf("  [Verify] FAILED: %v", err)
@@ -137,27 +377,338 @@ func verifyAsset(hostAddr, path string) {
 	log.Printf("  [Verify] SUCCESS: Status %d, Size: %d bytes", resp.StatusCode, len(body))
 }
 
-func sendAnnotation(c *websocket.Conn, assetID string) {
+// simulatedAnchor returns where the simulated user marks a view of type ft,
+// or nil for types that are annotated as text or not at all.
+func simulatedAnchor(ft pb.OpenViewRequest_FileType) *pb.Anchor {
+	switch ft {
+	case pb.OpenViewRequest_IMAGE:
+		return &pb.Anchor{Kind: pb.Anchor_RECT, X: 0.25, Y: 0.25, W: 0.5, H: 0.5}
+	case pb.OpenViewRequest_PDF:
+		return &pb.Anchor{Kind: pb.Anchor_PAGE, Page: 1, X: 0.1, Y: 0.1, W: 0.8, H: 0.2}
+	case pb.OpenViewRequest_CODE:
+		return &pb.Anchor{Kind: pb.Anchor_LINES, StartLine: 1, EndLine: 1}
+	}
+	return nil
+}
+
+func sendAnnotation(c *websocket.Conn, assetID string, anchor *pb.Anchor) {
+	id := "simulated-ann-" + time.Now().Format("150405")
+	data := &pb.AnnotationData{
+		Id:          id,
+		TargetText:  "This is interesting",
+		ContextHash: "sha256:dummy",
+		Body:        "Simulated annotation from Mock Client",
+		Timestamp:   time.Now().Unix(),
+		User:        *annUser,
+	}
+	if anchor != nil {
+		data.TargetText, data.ContextHash, data.Anchor = "", "", anchor
+	}
+	sendAnnotationAction(c, assetID, pb.AnnotationAction_CREATE, data)
+	if !*thread {
+		return
+	}
+
+	// Discuss, settle and reconsider the note, as a reviewer would
+	time.Sleep(500 * time.Millisecond)
+	sendAnnotationAction(c, assetID, pb.AnnotationAction_REPLY, &pb.AnnotationData{
+		Id: id + "-r1", ParentId: id, Body: "Simulated reply",
+	})
+	sendAnnotationAction(c, assetID, pb.AnnotationAction_REPLY, &pb.AnnotationData{
+		Id: id + "-r2", ParentId: id + "-r1", Body: "Simulated answer to the reply",
+	})
+	sendAnnotationAction(c, assetID, pb.AnnotationAction_RESOLVE, &pb.AnnotationData{Id: id})
+	sendAnnotationAction(c, assetID, pb.AnnotationAction_DELETE, &pb.AnnotationData{Id: id + "-r2"})
+}
+
+func sendAnnotationAction(c *websocket.Conn, assetID string, typ pb.AnnotationAction_ActionType, data *pb.AnnotationData) {
 	ann := &pb.Envelope{
 		Payload: &pb.Envelope_Annotation{
 			Annotation: &pb.AnnotationAction{
-				Type:     pb.AnnotationAction_CREATE,
+				Type:     typ,
 				FilePath: assetID, // Using AssetID as reference
-				Data: &pb.AnnotationData{
-					Id:          "simulated-ann-" + time.Now().Format("150405"),
-					TargetText:  "This is interesting",
-					ContextHash: "sha256:dummy",
-					Body:        "Simulated annotation from Mock Client",
-					Timestamp:   time.Now().Unix(),
+				Data:     data,
+			},
+		},
+	}
+
+	msg, _ := proto.Marshal(ann)
+	if err := c.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+		log.Printf("Failed to send annotation: %v", err)
+	} else {
+		log.Printf("  [Sent] Annotation %s %s.", typ, data.Id)
+	}
+}
+
+func sendLogSubscribe(c *websocket.Conn, streamID string) {
+	sub := &pb.Envelope{
+		Payload: &pb.Envelope_LogSubscribe{
+			LogSubscribe: &pb.LogSubscribe{
+				StreamId: streamID,
+				Filter:   *logFilter,
+			},
+		},
+	}
+
+	data, _ := proto.Marshal(sub)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to subscribe to log: %v", err)
+	}
+}
+
+func sendHello(c *websocket.Conn, seq uint64) {
+	hello := &pb.Envelope{
+		Payload: &pb.Envelope_Hello{
+			Hello: &pb.Hello{
+				ProtocolVersion: 1,
+				AppVersion:      "mock",
+				DeviceName:      *deviceName,
+				SupportedFileTypes: []pb.OpenViewRequest_FileType{
+					pb.OpenViewRequest_IMAGE,
+					pb.OpenViewRequest_MARKDOWN,
+					pb.OpenViewRequest_PDF,
+					pb.OpenViewRequest_LOG,
+					pb.OpenViewRequest_TEXT,
+					pb.OpenViewRequest_CODE,
 				},
+				Features: []string{"ack", "notifications", "log_stream", "prompts", "sessions", "clipboard", "upload", "edit"},
+				LastSeq:  seq,
+				DeviceId: *deviceID,
 			},
 		},
 	}
-	
-	data, _ := proto.Marshal(ann)
+
+	data, _ := proto.Marshal(hello)
 	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
-		log.Printf("Failed to send annotation: %v", err)
+		log.Printf("Failed to send hello: %v", err)
+	}
+}
+
+func sendAck(c *websocket.Conn, requestID string) {
+	ack := &pb.Envelope{
+		Payload: &pb.Envelope_Ack{
+			Ack: &pb.Ack{
+				RequestId: requestID,
+				Status:    pb.Ack_DISPLAYED,
+			},
+		},
+	}
+
+	data, _ := proto.Marshal(ack)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send ack: %v", err)
+	}
+}
+
+func sendStatus(c *websocket.Conn) {
+	status := &pb.Envelope{
+		Payload: &pb.Envelope_Status{
+			Status: &pb.ClientStatus{
+				State:         pb.ClientStatus_TERMINAL,
+				Foreground:    true,
+				ZellijSession: *session,
+			},
+		},
+	}
+
+	data, _ := proto.Marshal(status)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send status: %v", err)
+	}
+}
+
+func sendZellijWeb(c *websocket.Conn, action string) {
+	a, ok := pb.ZellijWebRequest_Action_value[strings.ToUpper(action)]
+	if !ok {
+		log.Fatalf("unknown zellij web action %q", action)
+	}
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload: &pb.Envelope_ZellijWebRequest{
+			ZellijWebRequest: &pb.ZellijWebRequest{
+				Action:   pb.ZellijWebRequest_Action(a),
+				LogLines: 20,
+			},
+		},
+	}
+
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send zellij web request: %v", err)
+	}
+}
+
+func sendSessionRequest(c *websocket.Conn, args []string) {
+	a, ok := pb.SessionRequest_Action_value[strings.ToUpper(args[0])]
+	if !ok {
+		log.Fatalf("unknown session action %q", args[0])
+	}
+	sr := &pb.SessionRequest{Action: pb.SessionRequest_Action(a)}
+	if len(args) > 1 {
+		sr.Name = args[1]
+	}
+	if len(args) > 2 {
+		switch sr.Action {
+		case pb.SessionRequest_CREATE:
+			sr.Layout = args[2]
+		case pb.SessionRequest_RENAME:
+			sr.NewName = args[2]
+		case pb.SessionRequest_DELETE:
+			sr.Force = args[2] == "force"
+		}
+	}
+
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload:   &pb.Envelope_SessionRequest{SessionRequest: sr},
+	}
+
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send session request: %v", err)
+	}
+}
+
+func sendCaptureRequest(c *websocket.Conn, session string) {
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload: &pb.Envelope_CaptureRequest{
+			CaptureRequest: &pb.CaptureRequest{Session: session},
+		},
+	}
+
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send capture request: %v", err)
+	}
+}
+
+func sendBrowseRequest(c *websocket.Conn, args []string) {
+	br := &pb.BrowseRequest{}
+	switch args[0] {
+	case "list":
+	case "search":
+		br.Action = pb.BrowseRequest_SEARCH
+	case "open":
+		br.Action = pb.BrowseRequest_OPEN
+	default:
+		log.Fatalf("Unknown browse action %q", args[0])
+	}
+	if len(args) > 1 {
+		br.Root = args[1]
+	}
+	if len(args) > 2 {
+		if br.Action == pb.BrowseRequest_SEARCH {
+			br.Query = args[2]
+		} else {
+			br.Path = args[2]
+		}
+	}
+
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload:   &pb.Envelope_BrowseRequest{BrowseRequest: br},
+	}
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send browse request: %v", err)
+	}
+}
+
+func sendActionRequest(c *websocket.Conn, args []string) {
+	ar := &pb.ActionRequest{Session: *session, Action: args[0]}
+	for i, a := range args[1:] {
+		if a == "--" && ar.Action == "run" {
+			ar.Command = args[i+2:]
+			break
+		}
+		ar.Args = append(ar.Args, a)
+	}
+
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload:   &pb.Envelope_ActionRequest{ActionRequest: ar},
+	}
+
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send action request: %v", err)
+	}
+}
+
+func sendRunQuickAction(c *websocket.Conn, args []string) {
+	run := &pb.RunQuickAction{Name: args[0]}
+	for _, a := range args[1:] {
+		switch a {
+		case "confirm":
+			run.Confirmed = true
+		case "cancel":
+			run.Cancel = true
+		}
+	}
+
+	req := &pb.Envelope{
+		RequestId: "mock-" + time.Now().Format("150405.000"),
+		Payload:   &pb.Envelope_RunQuickAction{RunQuickAction: run},
+	}
+
+	data, _ := proto.Marshal(req)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send quick action: %v", err)
+	}
+}
+
+// The simulated device clipboard, replaced by ClipboardSet
+var (
+	clipboardMu   sync.Mutex
+	clipboardType = "text/plain; charset=utf-8"
+	clipboardData []byte
+)
+
+func sendClipboardContent(c *websocket.Conn, cr *pb.ClipboardRequest) {
+	clipboardMu.Lock()
+	resp := &pb.ClipboardContent{Id: cr.Id, MimeType: clipboardType, Data: clipboardData}
+	clipboardMu.Unlock()
+	switch {
+	case *clipboard == "-":
+		resp = &pb.ClipboardContent{Id: cr.Id, Denied: true}
+	case resp.Data == nil:
+		resp.Data = []byte(*clipboard)
+	}
+	if !resp.Denied && int64(len(resp.Data)) > cr.MaxBytes {
+		resp = &pb.ClipboardContent{Id: cr.Id, Error: fmt.Sprintf("clipboard is %d bytes, over the limit", len(resp.Data))}
+	}
+
+	env := &pb.Envelope{
+		Payload: &pb.Envelope_ClipboardContent{ClipboardContent: resp},
+	}
+	data, _ := proto.Marshal(env)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to send clipboard: %v", err)
 	} else {
-		log.Printf("  [Sent] Annotation created.")
+		log.Printf("  [Sent] Clipboard (%d bytes, denied %v)", len(resp.Data), resp.Denied)
+	}
+}
+
+func sendPromptResponse(c *websocket.Conn, p *pb.Prompt) {
+	resp := &pb.PromptResponse{Id: p.Id, Answer: *answer}
+	switch {
+	case *answer == "-":
+		resp.Answer = ""
+		resp.Dismissed = true
+	case *answer == "" && len(p.Choices) > 0:
+		resp.Answer = p.Choices[0]
+	case *answer == "":
+		resp.Answer = "ok"
 	}
-}
\ No newline at end of file
+
+	env := &pb.Envelope{
+		Payload: &pb.Envelope_PromptResponse{PromptResponse: resp},
+	}
+	data, _ := proto.Marshal(env)
+	if err := c.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Failed to answer prompt: %v", err)
+	} else {
+		log.Printf("  [Sent] Answer %q", resp.Answer)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type uploadStatus struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	Offset     int64  `json:"offset"`
+	ChunkBytes int64  `json:"chunk_bytes"`
+	Done       bool   `json:"done"`
+	Path       string `json:"path"`
+}
+
+// uploadFile sends path to the daemon in chunks, the way the app uploads a
+// shared file. receiveID answers a `zelland receive`; empty goes to the inbox.
+func uploadFile(hostAddr, path, receiveID string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Upload failed: %v", err)
+		return
+	}
+	base := "http://" + hostAddr + "/api/v1/uploads"
+	sum := sha256.Sum256(data)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":       filepath.Base(path),
+		"size":       len(data),
+		"sha256":     hex.EncodeToString(sum[:]),
+		"receive_id": receiveID,
+	})
+	var st uploadStatus
+	if err := uploadRequest(http.MethodPost, base, "application/json", bytes.NewReader(body), &st); err != nil {
+		log.Printf("Upload failed: %v", err)
+		return
+	}
+	log.Printf("  [Upload %s] %s, %d bytes in chunks of %d", st.ID, st.Name, st.Size, *chunkSize)
+
+	for !st.Done {
+		end := min(st.Offset+*chunkSize, int64(len(data)))
+		chunk := data[st.Offset:end]
+		chunkSum := sha256.Sum256(chunk)
+
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		mw.WriteField("offset", strconv.FormatInt(st.Offset, 10))
+		mw.WriteField("sha256", hex.EncodeToString(chunkSum[:]))
+		fw, _ := mw.CreateFormFile("chunk", st.Name)
+		fw.Write(chunk)
+		mw.Close()
+
+		if err := uploadRequest(http.MethodPost, base+"/"+st.ID, mw.FormDataContentType(), &buf, &st); err != nil {
+			// Ask where to resume, as the app would after a dropped connection
+			log.Printf("  [Upload %s] chunk at %d failed: %v", st.ID, st.Offset, err)
+			if err := uploadRequest(http.MethodGet, base+"/"+st.ID, "", nil, &st); err != nil {
+				log.Printf("Upload failed: %v", err)
+				return
+			}
+			continue
+		}
+		log.Printf("  [Upload %s] %d/%d", st.ID, st.Offset, st.Size)
+	}
+	log.Printf("  [Upload %s] saved as %s", st.ID, st.Path)
+}
+
+func uploadRequest(method, url, contentType string, body io.Reader, out *uploadStatus) error {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Zelland-PSK", *psk)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zelland/daemon/internal/anchor"
+	"github.com/zelland/daemon/internal/config"
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/sidecar"
+	"github.com/zelland/daemon/internal/store"
+)
+
+// The ann commands read annotations straight from where the daemon stores
+// them, so they work whether or not it is running. Only ann add goes
+// through the daemon when it can.
+
+func handleAnn(args []string) {
+	if len(args) < 1 {
+		annUsage()
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "list":
+		handleAnnList(args[1:])
+	case "search":
+		handleAnnSearch(args[1:])
+	case "export":
+		handleAnnExport(args[1:])
+	case "add":
+		handleAnnAdd(args[1:])
+	default:
+		fmt.Printf("Unknown ann command: %s\n", args[0])
+		annUsage()
+		os.Exit(1)
+	}
+}
+
+func annUsage() {
+	fmt.Println("Usage: zelland ann <command> [flags]")
+	fmt.Println("Commands:")
+	fmt.Println("  list [path...]            List annotations on files, or files under directories (default .)")
+	fmt.Println("  search <query> [path...]  List annotations whose quote, note or replies match query")
+	fmt.Println("  export -format md|json|csv [path...]  Write annotations with their replies")
+	fmt.Println("  add <file> -quote text -body note  Annotate text in a file")
+	fmt.Println("Flags for list, search and export: -author name -status open|resolved -since date -until date -config file")
+}
+
+// annFlags are the flags shared by the ann commands.
+type annFlags struct {
+	config       *string
+	author       *string
+	status       *string
+	since, until *string
+}
+
+func newAnnFlags(fs *flag.FlagSet) *annFlags {
+	return &annFlags{
+		config: fs.String("config", os.Getenv("ZELLAND_CONFIG"), "The daemon's config file, for where annotations are stored (default $ZELLAND_CONFIG)"),
+		author: fs.String("author", "", "Only threads this user started or replied to"),
+		status: fs.String("status", "", "Only open or resolved threads"),
+		since:  fs.String("since", "", "Only threads started on or after this date (2006-01-02, RFC 3339, or a duration ago like 36h or 7d)"),
+		until:  fs.String("until", "", "Only threads started on or before this date"),
+	}
+}
+
+// filter selects threads.
+type filter struct {
+	author       string
+	status       string
+	since, until time.Time
+}
+
+func (f *annFlags) filter() (filter, error) {
+	flt := filter{author: *f.author, status: *f.status}
+	switch flt.status {
+	case "", kdl.StatusOpen, kdl.StatusResolved:
+	default:
+		return flt, fmt.Errorf("unknown status %q (want %s or %s)", flt.status, kdl.StatusOpen, kdl.StatusResolved)
+	}
+	var err error
+	if flt.since, err = parseWhen(*f.since, false); err != nil {
+		return flt, err
+	}
+	if flt.until, err = parseWhen(*f.until, true); err != nil {
+		return flt, err
+	}
+	return flt, nil
+}
+
+// parseWhen parses a date, a time or a duration before now. A date given
+// as an upper bound includes the whole day.
+func parseWhen(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a date, time or duration", s)
+}
+
+func (f filter) match(a kdl.Annotation) bool {
+	if f.status != "" && status(a) != f.status {
+		return false
+	}
+	at := time.Unix(a.Timestamp, 0)
+	if !f.since.IsZero() && at.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && at.After(f.until) {
+		return false
+	}
+	if f.author == "" || a.User == f.author {
+		return true
+	}
+	for _, r := range a.Replies {
+		if r.User == f.author {
+			return true
+		}
+	}
+	return false
+}
+
+func status(a kdl.Annotation) string {
+	if a.Resolved() {
+		return kdl.StatusResolved
+	}
+	return kdl.StatusOpen
+}
+
+// openStore opens the annotation store configured in the daemon's config
+// file, or the default one.
+func (f *annFlags) openStore() (store.AnnotationStore, error) {
+	cfg := config.Default()
+	if *f.config != "" {
+		var err error
+		if cfg, err = config.Load(*f.config); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	a := cfg.Annotations
+	return store.Open(a.Backend, a.Storage, a.DataDir, a.Database)
+}
+
+// thread is an annotation with what it points at now.
+type thread struct {
+	File string
+	kdl.Annotation
+	Current anchor.Resolution
+}
+
+// collect returns the threads on the files at or under paths that pass
+// keep, ordered by file.
+func collect(st store.AnnotationStore, paths []string, keep func(kdl.Annotation) bool) ([]thread, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, p := range paths {
+		found, err := st.Files(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var threads []thread
+	for _, file := range files {
+		anns, err := st.List(file)
+		if err != nil {
+			// One broken sidecar should not hide the rest
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", file, err)
+			continue
+		}
+		threads = append(threads, resolve(file, anns, keep)...)
+	}
+	return threads, nil
+}
+
+// resolve pairs the threads on file that pass keep with what they point at
+// in its current content.
+func resolve(file string, anns []kdl.Annotation, keep func(kdl.Annotation) bool) []thread {
+	var threads []thread
+	var content []byte
+	var readErr error
+	read := false
+	for _, a := range anns {
+		if !keep(a) {
+			continue
+		}
+		if !read {
+			content, readErr = os.ReadFile(file)
+			read = true
+		}
+		t := thread{File: file, Annotation: a, Current: anchor.Resolution{State: anchor.Missing}}
+		if readErr == nil {
+			t.Current = anchor.Resolve(content, a)
+		}
+		threads = append(threads, t)
+	}
+	return threads
+}
+
+// underAny reports whether file is one of roots or under one of them.
+func underAny(file string, roots []string) bool {
+	for _, root := range roots {
+		if file == root || strings.HasPrefix(file, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			return nil, err
+		}
+		c, err := sidecar.Canonical(p)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+func handleAnnList(args []string) {
+	fs := flag.NewFlagSet("ann list", flag.ExitOnError)
+	flags := newAnnFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland ann list [flags] [path...]")
+		fmt.Fprintln(os.Stderr, "Lists the annotations on files, and on files under directories (default .).")
+		fs.PrintDefaults()
+	}
+	paths := parseInterspersed(fs, args)
+
+	threads := loadThreads(flags, paths)
+	printThreads(threads)
+}
+
+// loadThreads returns the threads selected by flags on paths, exiting on
+// errors.
+func loadThreads(flags *annFlags, paths []string) []thread {
+	flt, err := flags.filter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	roots, err := canonicalPaths(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	st, err := flags.openStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	threads, err := collect(st, roots, flt.match)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return threads
+}
+
+func handleAnnSearch(args []string) {
+	fs := flag.NewFlagSet("ann search", flag.ExitOnError)
+	flags := newAnnFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland ann search [flags] <query> [path...]")
+		fmt.Fprintln(os.Stderr, "Lists the annotations whose quote, note or replies match query, on files at or")
+		fmt.Fprintln(os.Stderr, "under paths (default .). With sidecars, every word of query must appear, in any")
+		fmt.Fprintln(os.Stderr, "case. With the sqlite backend, query is an FTS4 query like `typo OR \"quick fox\"`.")
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	query, paths := positional[0], positional[1:]
+
+	flt, err := flags.filter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	roots, err := canonicalPaths(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	st, err := flags.openStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	var threads []thread
+	if searcher, ok := st.(store.Searcher); ok {
+		matches, err := searcher.Search(query)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Search failed: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range matches {
+			if underAny(m.File, roots) {
+				threads = append(threads, resolve(m.File, []kdl.Annotation{m.Annotation}, flt.match)...)
+			}
+		}
+	} else {
+		words := strings.Fields(strings.ToLower(query))
+		threads, err = collect(st, roots, func(a kdl.Annotation) bool {
+			text := strings.ToLower(store.Text(a))
+			for _, w := range words {
+				if !strings.Contains(text, w) {
+					return false
+				}
+			}
+			return flt.match(a)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	printThreads(threads)
+}
+
+// printThreads lists threads, one per line.
+func printThreads(threads []thread) {
+	if len(threads) == 0 {
+		fmt.Println("No annotations found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tID\tAUTHOR\tDATE\tSTATUS\tANCHOR\tNOTE")
+	for _, t := range threads {
+		note := firstLine(t.Body)
+		if n := len(t.Replies); n > 0 {
+			note = fmt.Sprintf("%s (+%d %s)", note, n, plural(n, "reply", "replies"))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			displayPath(t.File), t.ID, orDash(t.User), date(t.Timestamp), status(t.Annotation),
+			describeAnchor(t), truncate(note, 60))
+	}
+	tw.Flush()
+}
+
+// describeAnchor says briefly what a thread is about and whether it still
+// fits the file.
+func describeAnchor(t thread) string {
+	var where string
+	switch t.Anchor.Kind {
+	case kdl.AnchorRect:
+		where = "region"
+	case kdl.AnchorPage:
+		where = fmt.Sprintf("p. %d", t.Anchor.Page)
+		if t.Anchor.Quote != "" {
+			where += " " + strconv.Quote(truncate(firstLine(t.Anchor.Quote), 30))
+		}
+	case kdl.AnchorLines:
+		where = fmt.Sprintf("lines %d-%d", t.Anchor.StartLine, t.Anchor.EndLine)
+	default:
+		where = strconv.Quote(truncate(strings.Join(strings.Fields(t.TargetText), " "), 30))
+	}
+	switch t.Current.State {
+	case anchor.Moved, anchor.Missing:
+		where += " (" + string(t.Current.State) + ")"
+	}
+	return where
+}
+
+// displayPath shows paths under the working directory relative to it.
+func displayPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	if wd, err = sidecar.Canonical(wd); err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
+
+func date(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).Format("2006-01-02")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
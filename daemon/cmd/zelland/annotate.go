@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/zelland/daemon/internal/anchor"
+	"github.com/zelland/daemon/internal/assets"
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+// AnnotateRequest matches the server's /api/v1/trigger/annotate body
+type AnnotateRequest struct {
+	FilePath    string `json:"file_path"`
+	ID          string `json:"id"`
+	User        string `json:"user"`
+	TargetText  string `json:"target_text"`
+	ContextHash string `json:"context_hash"`
+	Body        string `json:"body"`
+}
+
+type AnnotateResponse struct {
+	ID     string `json:"id"`
+	SentTo int    `json:"sent_to"`
+}
+
+func handleAnnAdd(args []string) {
+	fs := flag.NewFlagSet("ann add", flag.ExitOnError)
+	quote := fs.String("quote", "", "Text in the file the note is about; it must appear in one paragraph only")
+	body := fs.String("body", "", "The note")
+	author := fs.String("user", currentUser(), "Who the note is from")
+	configPath := fs.String("config", os.Getenv("ZELLAND_CONFIG"), "The daemon's config file, for where to save when it is not running (default $ZELLAND_CONFIG)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland ann add <file> -quote text -body note [-user name]")
+		fmt.Fprintln(os.Stderr, "Annotates text in a file. Devices viewing it show the note right away; without")
+		fmt.Fprintln(os.Stderr, "the daemon, the note is saved directly and shows when the file is next opened.")
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) != 1 || *quote == "" || *body == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	file, err := filepath.Abs(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving path: %v\n", err)
+		os.Exit(1)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	paragraph, err := anchor.Context(string(content), *quote)
+	switch {
+	case errors.Is(err, anchor.ErrAmbiguous):
+		fmt.Fprintf(os.Stderr, "%q is in more than one paragraph of %s; quote more of it\n", *quote, positional[0])
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "%q is not in %s\n", *quote, positional[0])
+		os.Exit(1)
+	}
+
+	req := AnnotateRequest{
+		FilePath:    file,
+		ID:          assets.NewID(),
+		User:        *author,
+		TargetText:  *quote,
+		ContextHash: anchor.ContextHash(paragraph),
+		Body:        *body,
+	}
+
+	reply, err := postJSON("/api/v1/trigger/annotate", req)
+	var netErr *url.Error
+	if errors.As(err, &netErr) {
+		// The daemon is not running; save where it would have
+		flags := &annFlags{config: configPath}
+		if err := saveDirectly(flags, req); err != nil {
+			fmt.Fprintf(os.Stderr, "The daemon is not running, and saving directly failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved annotation %s on %s; the daemon is not running, so devices see it when they next open the file.\n", req.ID, positional[0])
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var resp AnnotateResponse
+	if err := json.Unmarshal([]byte(reply), &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected reply from daemon: %s\n", reply)
+		os.Exit(1)
+	}
+	fmt.Printf("Added annotation %s on %s; sent to %d device(s) viewing it.\n", resp.ID, positional[0], resp.SentTo)
+}
+
+func saveDirectly(flags *annFlags, req AnnotateRequest) error {
+	st, err := flags.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	return st.Put(req.FilePath, kdl.Annotation{
+		ID:          req.ID,
+		User:        req.User,
+		Timestamp:   time.Now().Unix(),
+		ContextHash: req.ContextHash,
+		TargetText:  req.TargetText,
+		Body:        req.Body,
+	})
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Exit codes of `zelland ask`, so scripts can branch on the answer
+const (
+	askFirstChoice = 0 // The first choice, or any free-text answer
+	askOtherChoice = 1 // Another choice, or the user dismissed the prompt
+	askError       = 2
+	askTimedOut    = 124 // Same as timeout(1)
+)
+
+// AskRequest matches the daemon's ask endpoint
+type AskRequest struct {
+	Question  string   `json:"question"`
+	Choices   []string `json:"choices,omitempty"`
+	TimeoutMs int64    `json:"timeout_ms,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Origin
+}
+
+type AskResponse struct {
+	Answer    string `json:"answer"`
+	Device    string `json:"device"`
+	Dismissed bool   `json:"dismissed"`
+	TimedOut  bool   `json:"timed_out"`
+}
+
+func handleAsk(args []string) {
+	fs := flag.NewFlagSet("ask", flag.ExitOnError)
+	choices := fs.String("choices", "", "Comma-separated answers to offer, e.g. yes,no (default: free text)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "Give up after this long (0 waits forever)")
+	to := fs.String("to", "", "Device name or ID to ask (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland ask <question> [-choices yes,no] [-timeout 10m] [-to device]")
+		fmt.Fprintln(os.Stderr, "Prints the answer. Exits 0 for the first choice (or any text answer),")
+		fmt.Fprintln(os.Stderr, "1 for another choice or a dismissal, 124 on timeout and 2 on error.")
+		fs.PrintDefaults()
+	}
+	question := strings.Join(parseInterspersed(fs, args), " ")
+	if question == "" {
+		fs.Usage()
+		os.Exit(askError)
+	}
+
+	req := AskRequest{
+		Question:  question,
+		TimeoutMs: timeout.Milliseconds(),
+		To:        *to,
+		Origin:    currentOrigin(),
+	}
+	if *choices != "" {
+		for _, c := range strings.Split(*choices, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				req.Choices = append(req.Choices, c)
+			}
+		}
+	}
+
+	body, _ := json.Marshal(req)
+	// No client timeout: the daemon holds the request until there is an answer
+	httpResp, err := http.Post("http://localhost:8083/api/v1/trigger/ask", "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to daemon: %v (is zellandd running?)\n", err)
+		os.Exit(askError)
+	}
+	defer httpResp.Body.Close()
+	reply, _ := io.ReadAll(httpResp.Body)
+	if httpResp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Error from daemon (Status %d): %s\n", httpResp.StatusCode, strings.TrimSpace(string(reply)))
+		os.Exit(askError)
+	}
+
+	var resp AskResponse
+	if err := json.Unmarshal(reply, &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected reply from daemon: %s\n", reply)
+		os.Exit(askError)
+	}
+
+	switch {
+	case resp.TimedOut:
+		fmt.Fprintf(os.Stderr, "No answer within %s\n", *timeout)
+		os.Exit(askTimedOut)
+	case resp.Dismissed:
+		fmt.Fprintf(os.Stderr, "Dismissed on %s\n", resp.Device)
+		os.Exit(askOtherChoice)
+	}
+
+	fmt.Println(resp.Answer)
+	if len(req.Choices) > 0 && resp.Answer != req.Choices[0] {
+		os.Exit(askOtherChoice)
+	}
+	os.Exit(askFirstChoice)
+}
+
+// parseInterspersed parses flags that may appear before or after the
+// positional arguments, as in `zelland ask "Deploy?" --choices yes,no`.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
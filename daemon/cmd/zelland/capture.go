@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// CaptureRequest matches the server's /api/v1/trigger/capture body
+type CaptureRequest struct {
+	Session    string `json:"session,omitempty"`
+	ScreenOnly bool   `json:"screen_only,omitempty"`
+	Title      string `json:"title"`
+	WaitMs     int64  `json:"wait_ms,omitempty"`
+	To         string `json:"to,omitempty"`
+	Origin
+}
+
+func handleCapture(args []string) {
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	session := fs.String("session", "", "Zellij session to capture (default: the current one)")
+	screen := fs.Bool("screen", false, "Capture only the visible screen, not the whole scrollback")
+	title := fs.String("title", "", "Title shown on the device")
+	wait := fs.Duration("wait", 0, "Wait this long for a device to confirm it displayed the capture")
+	to := fs.String("to", "", "Device name or ID to send to (see `zelland devices`)")
+	fs.Parse(args)
+
+	origin := currentOrigin()
+	if *session == "" && origin.ZellijSession == "" {
+		fmt.Println("Not running inside Zellij; use -session to name the session to capture.")
+		os.Exit(1)
+	}
+
+	reply, err := postJSON("/api/v1/trigger/capture", CaptureRequest{
+		Session:    *session,
+		ScreenOnly: *screen,
+		Title:      *title,
+		WaitMs:     wait.Milliseconds(),
+		To:         *to,
+		Origin:     origin,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	reportTrigger(reply, "the capture", "capture", *wait)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// CopyRequest matches the server's /api/v1/trigger/copy body
+type CopyRequest struct {
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type,omitempty"`
+	To       string `json:"to,omitempty"`
+	Origin
+}
+
+type CopyResponse struct {
+	MimeType string `json:"mime_type"`
+	SentTo   int    `json:"sent_to"`
+}
+
+// PasteRequest matches the server's /api/v1/trigger/paste body
+type PasteRequest struct {
+	MaxBytes  int64  `json:"max_bytes,omitempty"`
+	TimeoutMs int64  `json:"timeout_ms,omitempty"`
+	To        string `json:"to,omitempty"`
+	Origin
+}
+
+type PasteResponse struct {
+	MimeType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+	Device   string `json:"device"`
+	Denied   bool   `json:"denied"`
+	TimedOut bool   `json:"timed_out"`
+	Error    string `json:"error"`
+}
+
+func handleCopy(args []string) {
+	fs := flag.NewFlagSet("copy", flag.ExitOnError)
+	mimeType := fs.String("type", "", "MIME type of the content (default: detected)")
+	to := fs.String("to", "", "Device name or ID to send to (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland copy [-type mime] [-to device] [text...]")
+		fmt.Fprintln(os.Stderr, "Puts the text, or stdin if none is given, on the device's clipboard.")
+		fs.PrintDefaults()
+	}
+	text := parseInterspersed(fs, args)
+
+	var data []byte
+	if len(text) > 0 {
+		data = []byte(strings.Join(text, " "))
+	} else {
+		var err error
+		data, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Printf("Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if len(data) == 0 {
+		fmt.Println("Nothing to copy.")
+		os.Exit(1)
+	}
+
+	reply, err := postJSON("/api/v1/trigger/copy", CopyRequest{
+		Data:     data,
+		MimeType: *mimeType,
+		To:       *to,
+		Origin:   currentOrigin(),
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var resp CopyResponse
+	if err := json.Unmarshal([]byte(reply), &resp); err != nil {
+		fmt.Printf("Unexpected reply from daemon: %s\n", reply)
+		os.Exit(1)
+	}
+	if resp.SentTo == 0 {
+		// Clipboard content is never queued for later
+		fmt.Println("No connected device supports the clipboard; nothing was copied.")
+		os.Exit(1)
+	}
+	fmt.Printf("Copied %d bytes (%s) to %d device(s).\n", len(data), resp.MimeType, resp.SentTo)
+}
+
+func handlePaste(args []string) {
+	fs := flag.NewFlagSet("paste", flag.ExitOnError)
+	output := fs.String("o", "", "Write the content to this file instead of stdout")
+	maxBytes := fs.Int64("max", 0, "Refuse content larger than this many bytes (default: the daemon's limit)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Give up after this long (0 waits forever)")
+	to := fs.String("to", "", "Device name or ID to ask (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland paste [-o file] [-max bytes] [-timeout 2m] [-to device]")
+		fmt.Fprintln(os.Stderr, "Asks the device for its clipboard; the user must allow it on the device.")
+		fmt.Fprintln(os.Stderr, "Exits 1 if the user declines, 124 on timeout and 2 on error.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	reply, err := postJSON("/api/v1/trigger/paste", PasteRequest{
+		MaxBytes:  *maxBytes,
+		TimeoutMs: timeout.Milliseconds(),
+		To:        *to,
+		Origin:    currentOrigin(),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(askError)
+	}
+
+	var resp PasteResponse
+	if err := json.Unmarshal([]byte(reply), &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected reply from daemon: %s\n", reply)
+		os.Exit(askError)
+	}
+
+	// Same exit codes as `zelland ask`
+	switch {
+	case resp.TimedOut:
+		fmt.Fprintf(os.Stderr, "No answer within %s\n", *timeout)
+		os.Exit(askTimedOut)
+	case resp.Denied:
+		fmt.Fprintf(os.Stderr, "Declined on %s\n", resp.Device)
+		os.Exit(askOtherChoice)
+	case resp.Error != "":
+		fmt.Fprintf(os.Stderr, "%s: %s\n", resp.Device, resp.Error)
+		os.Exit(askError)
+	}
+
+	if *output != "" {
+		err = os.WriteFile(*output, resp.Data, 0644)
+	} else if !strings.HasPrefix(resp.MimeType, "text/") && isTerminal(os.Stdout) {
+		err = fmt.Errorf("the clipboard holds %s; use -o to save it to a file", resp.MimeType)
+	} else {
+		_, err = os.Stdout.Write(resp.Data)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(askError)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// DeviceInfo matches the daemon's /api/v1/devices entries
+type DeviceInfo struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	AppVersion    string    `json:"app_version"`
+	State         string    `json:"state"`
+	ActiveAssetID string    `json:"active_asset_id"`
+	Foreground    bool      `json:"foreground"`
+	LastActive    time.Time `json:"last_active"`
+	ZellijSession string    `json:"zellij_session"`
+}
+
+func handleDevices(args []string) {
+	resp, err := http.Get("http://localhost:8083/api/v1/devices")
+	if err != nil {
+		fmt.Printf("Error connecting to daemon: %v\nIs zellandd running?\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Printf("Error from daemon (Status %d): %s\n", resp.StatusCode, string(body))
+		os.Exit(1)
+	}
+
+	var devices []DeviceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
+		fmt.Printf("Unexpected reply from daemon: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("No devices connected.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tAPP\tSTATE\tSESSION\tVIEWING\tLAST ACTIVE")
+	for _, d := range devices {
+		state := d.State
+		if d.Foreground {
+			state += " (foreground)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s ago\n",
+			d.Name, d.ID, d.AppVersion, state, orDash(d.ZellijSession), orDash(d.ActiveAssetID),
+			time.Since(d.LastActive).Round(time.Second))
+	}
+	tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/anchor"
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+// exportedThread is a thread in `zelland ann export -format json`.
+type exportedThread struct {
+	File        string          `json:"file"`
+	ID          string          `json:"id"`
+	User        string          `json:"user"`
+	Time        time.Time       `json:"time"`
+	Status      string          `json:"status"`
+	ResolvedBy  string          `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time      `json:"resolved_at,omitempty"`
+	TargetText  string          `json:"target_text,omitempty"`
+	ContextHash string          `json:"context_hash,omitempty"`
+	Anchor      *exportedAnchor `json:"anchor,omitempty"`
+	AnchorState anchor.State    `json:"anchor_state"`
+	CurrentText string          `json:"current_text,omitempty"`
+	Body        string          `json:"body"`
+	Replies     []exportedReply `json:"replies"`
+}
+
+type exportedAnchor struct {
+	Kind      string  `json:"kind"`
+	X         float64 `json:"x,omitempty"`
+	Y         float64 `json:"y,omitempty"`
+	W         float64 `json:"w,omitempty"`
+	H         float64 `json:"h,omitempty"`
+	Page      int     `json:"page,omitempty"`
+	StartLine int     `json:"start_line,omitempty"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Quote     string  `json:"quote,omitempty"`
+}
+
+type exportedReply struct {
+	ID     string    `json:"id"`
+	User   string    `json:"user"`
+	Time   time.Time `json:"time"`
+	Parent string    `json:"parent,omitempty"`
+	Body   string    `json:"body"`
+}
+
+func handleAnnExport(args []string) {
+	fs := flag.NewFlagSet("ann export", flag.ExitOnError)
+	flags := newAnnFlags(fs)
+	format := fs.String("format", "md", "md, json or csv")
+	output := fs.String("o", "", "Write to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland ann export [-format md|json|csv] [-o file] [flags] [path...]")
+		fmt.Fprintln(os.Stderr, "Writes the annotations on files at or under paths (default .), with their replies")
+		fmt.Fprintln(os.Stderr, "and the text they point at now.")
+		fs.PrintDefaults()
+	}
+	paths := parseInterspersed(fs, args)
+
+	var write func(io.Writer, []thread) error
+	switch *format {
+	case "md":
+		write = exportMarkdown
+	case "json":
+		write = exportJSON
+	case "csv":
+		write = exportCSV
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q (want md, json or csv)\n", *format)
+		os.Exit(1)
+	}
+
+	threads := loadThreads(flags, paths)
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := write(out, threads); err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func exportJSON(w io.Writer, threads []thread) error {
+	out := make([]exportedThread, 0, len(threads))
+	for _, t := range threads {
+		e := exportedThread{
+			File:        t.File,
+			ID:          t.ID,
+			User:        t.User,
+			Time:        time.Unix(t.Timestamp, 0),
+			Status:      status(t.Annotation),
+			ResolvedBy:  t.ResolvedBy,
+			TargetText:  t.TargetText,
+			ContextHash: t.ContextHash,
+			AnchorState: t.Current.State,
+			CurrentText: t.Current.Text,
+			Body:        t.Body,
+			Replies:     []exportedReply{},
+		}
+		if t.ResolvedAt != 0 {
+			at := time.Unix(t.ResolvedAt, 0)
+			e.ResolvedAt = &at
+		}
+		if t.Anchor.Kind != "" {
+			a := exportedAnchor(t.Anchor)
+			e.Anchor = &a
+		}
+		for _, r := range t.Replies {
+			e.Replies = append(e.Replies, exportedReply{
+				ID:     r.ID,
+				User:   r.User,
+				Time:   time.Unix(r.Timestamp, 0),
+				Parent: r.Parent,
+				Body:   r.Body,
+			})
+		}
+		out = append(out, e)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// exportCSV writes a row per thread followed by a row per reply, which
+// carries the thread's ID in the thread column.
+func exportCSV(w io.Writer, threads []thread) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "thread", "id", "parent", "user", "time", "status", "anchor", "anchor_state", "quote", "current_text", "body"})
+	for _, t := range threads {
+		cw.Write([]string{
+			t.File, t.ID, t.ID, "", t.User, csvTime(t.Timestamp), status(t.Annotation),
+			anchorKind(t.Anchor), string(t.Current.State), quote(t.Annotation), t.Current.Text, t.Body,
+		})
+		for _, r := range t.Replies {
+			parent := r.Parent
+			if parent == "" {
+				parent = t.ID
+			}
+			cw.Write([]string{t.File, t.ID, r.ID, parent, r.User, csvTime(r.Timestamp), "", "", "", "", "", r.Body})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvTime(unix int64) string {
+	return time.Unix(unix, 0).Format(time.RFC3339)
+}
+
+func anchorKind(a kdl.Anchor) string {
+	if a.Kind == "" {
+		return "text"
+	}
+	return a.Kind
+}
+
+// quote returns the text a thread was made on, if it has any.
+func quote(a kdl.Annotation) string {
+	if a.Anchor.Kind == kdl.AnchorPage {
+		return a.Anchor.Quote
+	}
+	return a.TargetText
+}
+
+// exportMarkdown writes a section per file and one per thread, with replies
+// nested under the messages they answer.
+func exportMarkdown(w io.Writer, threads []thread) error {
+	var b strings.Builder
+	b.WriteString("# Annotations\n")
+	if len(threads) == 0 {
+		b.WriteString("\nNo annotations found.\n")
+	}
+	file := ""
+	for _, t := range threads {
+		if t.File != file {
+			file = t.File
+			fmt.Fprintf(&b, "\n## %s\n", displayPath(file))
+		}
+
+		fmt.Fprintf(&b, "\n### %s\n\n", describeAnchor(t))
+		fmt.Fprintf(&b, "*%s, %s", orDash(t.User), date(t.Timestamp))
+		if t.Resolved() {
+			fmt.Fprintf(&b, ", resolved by %s on %s", orDash(t.ResolvedBy), date(t.ResolvedAt))
+		}
+		b.WriteString("*\n\n")
+		if t.Current.Text != "" {
+			b.WriteString(blockquote(t.Current.Text))
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.TrimSpace(t.Body) + "\n")
+
+		if len(t.Replies) > 0 {
+			b.WriteString("\n")
+			depth := map[string]int{t.ID: 0}
+			for _, r := range t.Replies {
+				parent := r.Parent
+				if parent == "" {
+					parent = t.ID
+				}
+				d := depth[parent] + 1
+				depth[r.ID] = d
+				indent := strings.Repeat("  ", d-1)
+				body := strings.ReplaceAll(strings.TrimSpace(r.Body), "\n", "\n"+indent+"  ")
+				fmt.Fprintf(&b, "%s- **%s** (%s): %s\n", indent, orDash(r.User), date(r.Timestamp), body)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func blockquote(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("> "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -3,17 +3,50 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // IPC Request structure matching the server
 type ShowRequest struct {
 	FilePath string `json:"file_path"`
 	Title    string `json:"title"`
+	WaitMs   int64  `json:"wait_ms,omitempty"`
+	To       string `json:"to,omitempty"`
+	Origin
+}
+
+// Origin tells the daemon which Zellij session and pane the CLI runs in, so
+// the view goes to the device showing that session.
+type Origin struct {
+	ZellijSession string `json:"zellij_session,omitempty"`
+	ZellijPaneID  string `json:"zellij_pane_id,omitempty"`
+}
+
+func currentOrigin() Origin {
+	return Origin{
+		ZellijSession: os.Getenv("ZELLIJ_SESSION_NAME"),
+		ZellijPaneID:  os.Getenv("ZELLIJ_PANE_ID"),
+	}
+}
+
+// IPC Response structure matching the server
+type TriggerResponse struct {
+	AssetID    string `json:"asset_id"`
+	RequestID  string `json:"request_id"`
+	SentTo     int    `json:"sent_to"`
+	AckCapable int    `json:"ack_capable"`
+	Acks       []struct {
+		Device string `json:"device"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"acks"`
 }
 
 func main() {
@@ -28,6 +61,26 @@ func main() {
 		handleShow(os.Args[2:])
 	case "md":
 		handleMarkdown(os.Args[2:])
+	case "tail":
+		handleTail(os.Args[2:])
+	case "notify":
+		handleNotify(os.Args[2:])
+	case "done":
+		handleDone(os.Args[2:])
+	case "ask":
+		handleAsk(os.Args[2:])
+	case "capture":
+		handleCapture(os.Args[2:])
+	case "copy":
+		handleCopy(os.Args[2:])
+	case "paste":
+		handlePaste(os.Args[2:])
+	case "receive":
+		handleReceive(os.Args[2:])
+	case "devices":
+		handleDevices(os.Args[2:])
+	case "ann":
+		handleAnn(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -38,8 +91,18 @@ func main() {
 func printUsage() {
 	fmt.Println("Usage: zelland <command> [args]")
 	fmt.Println("Commands:")
-	fmt.Println("  show <file>   Display a file on the connected device")
-	fmt.Println("  md   <file>   Open a markdown session with annotations")
+	fmt.Println("  show [-wait 5s] <file>   Display a file on the connected device")
+	fmt.Println("  md   [-wait 5s] <file>   Open a markdown session with annotations")
+	fmt.Println("  tail <file|-> Follow a log file (or stdin) in a live log view")
+	fmt.Println("  notify <title> [body]  Send a notification to the device")
+	fmt.Println("  done -- <cmd> [args]   Run a command and notify when it finishes")
+	fmt.Println("  ask <question> [-choices a,b] Ask on the device and print the answer")
+	fmt.Println("  capture [-screen]      Send the current pane's scrollback as a searchable document")
+	fmt.Println("  copy [text]            Put text (or stdin) on the device's clipboard")
+	fmt.Println("  paste [-o file]        Print the device's clipboard after the user allows it")
+	fmt.Println("  receive [dir]          Wait for a file uploaded from the device and print its path")
+	fmt.Println("  devices                List connected devices")
+	fmt.Println("  ann list|search|export|add List, search, export or add annotations (see zelland ann)")
 }
 
 func handleShow(args []string) {
@@ -51,12 +114,17 @@ func handleMarkdown(args []string) {
 }
 
 func trigger(args []string, endpointType string) {
-	if len(args) < 1 {
-		fmt.Printf("Usage: zelland %s <filename>\n", endpointType)
+	fs := flag.NewFlagSet(endpointType, flag.ExitOnError)
+	wait := fs.Duration("wait", 0, "Wait this long for a device to confirm it displayed the file")
+	to := fs.String("to", "", "Device name or ID to send to (see `zelland devices`)")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Printf("Usage: zelland %s [-wait 5s] [-to device] <filename>\n", endpointType)
 		os.Exit(1)
 	}
 
-	filename := args[0]
+	filename := fs.Arg(0)
 	absPath, err := filepath.Abs(filename)
 	if err != nil {
 		fmt.Printf("Error resolving path: %v\n", err)
@@ -66,16 +134,118 @@ func trigger(args []string, endpointType string) {
 	reqBody := ShowRequest{
 		FilePath: absPath,
 		Title:    filepath.Base(filename),
+		WaitMs:   wait.Milliseconds(),
+		To:       *to,
+		Origin:   currentOrigin(),
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	reply, err := postJSON(fmt.Sprintf("/api/v1/trigger/%s", endpointType), reqBody)
 	if err != nil {
-		fmt.Printf("Error marshaling request: %v\n", err)
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	url := fmt.Sprintf("http://localhost:8083/api/v1/trigger/%s", endpointType)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	reportTrigger(reply, filename, endpointType, *wait)
+}
+
+// reportTrigger prints the outcome of a show-like trigger and exits non-zero
+// if nothing was shown.
+func reportTrigger(reply, filename, via string, wait time.Duration) {
+	var resp TriggerResponse
+	if err := json.Unmarshal([]byte(reply), &resp); err != nil {
+		fmt.Printf("Unexpected reply from daemon: %s\n", reply)
+		os.Exit(1)
+	}
+
+	if resp.SentTo == 0 {
+		fmt.Printf("No device connected; %s will be shown when one connects.\n", filename)
+		os.Exit(1)
+	}
+
+	if wait == 0 {
+		fmt.Printf("Sent %s to %d device(s) via %s.\n", filename, resp.SentTo, via)
+		return
+	}
+	if resp.AckCapable == 0 {
+		fmt.Printf("Sent %s to %d device(s); none of them can confirm display.\n", filename, resp.SentTo)
+		return
+	}
+
+	displayed := 0
+	for _, ack := range resp.Acks {
+		switch ack.Status {
+		case "DISPLAYED":
+			displayed++
+			fmt.Printf("Displayed %s on %s.\n", filename, ack.Device)
+		case "FAILED":
+			fmt.Printf("%s failed to display %s: %s\n", ack.Device, filename, ack.Error)
+		}
+	}
+	if displayed == 0 {
+		fmt.Printf("No device displayed %s within %s.\n", filename, wait)
+		os.Exit(1)
+	}
+}
+
+// postJSON sends v to a daemon endpoint and returns the response body. A
+// non-200 response is returned as an error.
+func postJSON(path string, v interface{}) (string, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post("http://localhost:8083"+path, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error connecting to daemon: %w (is zellandd running?)", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error from daemon (Status %d): %s", resp.StatusCode, string(body))
+	}
+	return string(body), nil
+}
+
+func handleTail(args []string) {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	to := fs.String("to", "", "Device name or ID to send to (see `zelland devices`)")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: zelland tail [-to device] <filename|->")
+		os.Exit(1)
+	}
+	args = fs.Args()
+
+	var resp *http.Response
+	var err error
+	if args[0] == "-" {
+		// Pass stdin through to the terminal while streaming it to the daemon.
+		origin := currentOrigin()
+		query := url.Values{
+			"title":          {"stdin"},
+			"to":             {*to},
+			"zellij_session": {origin.ZellijSession},
+			"zellij_pane_id": {origin.ZellijPaneID},
+		}
+		endpoint := "http://localhost:8083/api/v1/trigger/tail/stdin?" + query.Encode()
+		resp, err = http.Post(endpoint, "text/plain", io.TeeReader(os.Stdin, os.Stdout))
+	} else {
+		absPath, absErr := filepath.Abs(args[0])
+		if absErr != nil {
+			fmt.Printf("Error resolving path: %v\n", absErr)
+			os.Exit(1)
+		}
+		jsonData, _ := json.Marshal(ShowRequest{
+			FilePath: absPath,
+			Title:    filepath.Base(args[0]),
+			To:       *to,
+			Origin:   currentOrigin(),
+		})
+		resp, err = http.Post("http://localhost:8083/api/v1/trigger/tail", "application/json", bytes.NewBuffer(jsonData))
+	}
 	if err != nil {
 		fmt.Printf("Error connecting to daemon: %v\nIs zellandd running?\n", err)
 		os.Exit(1)
@@ -88,5 +258,11 @@ func trigger(args []string, endpointType string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Sent %s to device via %s.\n", filename, endpointType)
+	// For files the daemon keeps the response open while following; it ends
+	// when the user interrupts the CLI.
+	if args[0] == "-" {
+		io.Copy(io.Discard, resp.Body)
+		return
+	}
+	io.Copy(os.Stdout, resp.Body)
 }
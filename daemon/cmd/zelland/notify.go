@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// NotifyRequest matches the daemon's notify endpoint
+type NotifyRequest struct {
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	Urgency  string `json:"urgency,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+	To       string `json:"to,omitempty"`
+	Origin
+}
+
+func handleNotify(args []string) {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	urgency := fs.String("urgency", "normal", "low, normal or high")
+	open := fs.String("open", "", "File to open when the notification is tapped")
+	to := fs.String("to", "", "Device name or ID to send to (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Println("Usage: zelland notify [-urgency low|normal|high] [-open file] [-to device] <title> [body]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	req := NotifyRequest{
+		Title:   fs.Arg(0),
+		Body:    strings.Join(fs.Args()[1:], " "),
+		Urgency: *urgency,
+		To:      *to,
+		Origin:  currentOrigin(),
+	}
+	if *open != "" {
+		absPath, err := filepath.Abs(*open)
+		if err != nil {
+			fmt.Printf("Error resolving path: %v\n", err)
+			os.Exit(1)
+		}
+		req.FilePath = absPath
+	}
+
+	reply, err := postJSON("/api/v1/trigger/notify", req)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(reply)
+}
+
+// Number of trailing output lines included in a `done` notification
+const doneTailLines = 10
+
+func handleDone(args []string) {
+	fs := flag.NewFlagSet("done", flag.ExitOnError)
+	title := fs.String("title", "", "Name to use for the command in the notification")
+	to := fs.String("to", "", "Device name or ID to notify (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Println("Usage: zelland done [-title name] [-to device] -- <cmd> [args]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	cmdArgs := fs.Args()
+	name := *title
+	if name == "" {
+		name = strings.Join(cmdArgs, " ")
+	}
+
+	// Ctrl-C is delivered to the whole process group; let the command handle
+	// it and still report how it ended.
+	signal.Ignore(os.Interrupt, syscall.SIGQUIT)
+
+	tail := &tailBuffer{max: 8 * 1024}
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+
+	start := time.Now()
+	runErr := cmd.Run()
+	elapsed := time.Since(start).Round(time.Second)
+
+	code := exitCode(runErr)
+	req := NotifyRequest{
+		Title:   fmt.Sprintf("%s succeeded", name),
+		Body:    fmt.Sprintf("Finished in %s", elapsed),
+		Urgency: "normal",
+		To:      *to,
+		Origin:  currentOrigin(),
+	}
+	if code != 0 {
+		req.Title = fmt.Sprintf("%s failed (exit %d)", name, code)
+		req.Urgency = "high"
+		if runErr != nil && code == 127 {
+			req.Body += "\n\n" + runErr.Error()
+		}
+	}
+	if lines := tail.lastLines(doneTailLines); lines != "" {
+		req.Body += "\n\n" + lines
+	}
+
+	if _, err := postJSON("/api/v1/trigger/notify", req); err != nil {
+		fmt.Fprintf(os.Stderr, "zelland: failed to send notification: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// exitCode maps the result of cmd.Run to a shell-style exit status.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	// The command could not be started at all
+	fmt.Fprintf(os.Stderr, "zelland: %v\n", err)
+	return 127
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) lastLines(n int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := bytes.Split(bytes.TrimRight(t.buf, "\n"), []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return string(bytes.Join(lines, []byte("\n")))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReceiveRequest matches the server's /api/v1/trigger/receive body
+type ReceiveRequest struct {
+	Dir       string `json:"dir,omitempty"`
+	TimeoutMs int64  `json:"timeout_ms,omitempty"`
+	To        string `json:"to,omitempty"`
+	Origin
+}
+
+type ReceiveResponse struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	From     string `json:"from"`
+	TimedOut bool   `json:"timed_out"`
+}
+
+func handleReceive(args []string) {
+	fs := flag.NewFlagSet("receive", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Minute, "Give up after this long (0 waits forever)")
+	to := fs.String("to", "", "Device name or ID to ask (see `zelland devices`)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: zelland receive [-timeout 10m] [-to device] [dir]")
+		fmt.Fprintln(os.Stderr, "Asks the device for a file, saves it in dir (default: the daemon's inbox)")
+		fmt.Fprintln(os.Stderr, "and prints its path. Exits 124 on timeout and 2 on error.")
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) > 1 {
+		fs.Usage()
+		os.Exit(askError)
+	}
+
+	req := ReceiveRequest{
+		TimeoutMs: timeout.Milliseconds(),
+		To:        *to,
+		Origin:    currentOrigin(),
+	}
+	if len(positional) == 1 {
+		dir, err := filepath.Abs(positional[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(askError)
+		}
+		req.Dir = dir
+	}
+
+	reply, err := postJSON("/api/v1/trigger/receive", req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(askError)
+	}
+
+	var resp ReceiveResponse
+	if err := json.Unmarshal([]byte(reply), &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected reply from daemon: %s\n", reply)
+		os.Exit(askError)
+	}
+	if resp.TimedOut {
+		fmt.Fprintf(os.Stderr, "No file received within %s\n", *timeout)
+		os.Exit(askTimedOut)
+	}
+	if resp.Path == "" {
+		os.Exit(askError)
+	}
+	fmt.Fprintf(os.Stderr, "Received %d bytes from %s\n", resp.Size, resp.From)
+	fmt.Println(resp.Path)
+}
@@ -26,7 +26,10 @@ func main() {
 		cfg.Port = *port
 	}
 
-	srv := server.New(cfg.Port, cfg.CertFile, cfg.KeyFile)
+	srv, err := server.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 	if err := srv.Start(); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
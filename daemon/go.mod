@@ -4,7 +4,7 @@ go 1.25.6
 
 require (
 	github.com/gorilla/websocket v1.5.3
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/sblinch/kdl-go v0.0.0-20260120205643-17a91a33fe63
 	google.golang.org/protobuf v1.36.11
 )
-
-require github.com/sblinch/kdl-go v0.0.0-20260120205643-17a91a33fe63 // indirect
@@ -0,0 +1,130 @@
+// Package anchor finds what annotations point at in the current content of
+// their files.
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+var (
+	ErrNotFound  = errors.New("quote not found")
+	ErrAmbiguous = errors.New("quote found in more than one paragraph")
+)
+
+// Paragraphs splits text into paragraphs: runs of lines that are not
+// blank, without trailing whitespace.
+func Paragraphs(text string) []string {
+	var paras, lines []string
+	flush := func() {
+		if len(lines) > 0 {
+			paras = append(paras, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return paras
+}
+
+// ContextHash returns the context_hash of an annotation made in paragraph.
+func ContextHash(paragraph string) string {
+	sum := sha256.Sum256([]byte(paragraph))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Contains reports whether paragraph contains quote. Runs of whitespace
+// match each other, since a quote selected from rendered markdown has
+// spaces where the source wraps lines.
+func Contains(paragraph, quote string) bool {
+	quote = collapse(quote)
+	return quote != "" && strings.Contains(collapse(paragraph), quote)
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Context returns the one paragraph of text containing quote.
+func Context(text, quote string) (string, error) {
+	var found []string
+	for _, p := range Paragraphs(text) {
+		if Contains(p, quote) {
+			found = append(found, p)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return found[0], nil
+	}
+	return "", ErrAmbiguous
+}
+
+// State says how well an annotation still fits its file.
+type State string
+
+const (
+	// The annotated text is where it was
+	Anchored State = "anchored"
+	// The quote is still there, but its paragraph has changed
+	Moved State = "moved"
+	// The annotated text or lines are gone
+	Missing State = "missing"
+	// Images and PDFs are not checked
+	Unchecked State = "unchecked"
+)
+
+// Resolution is what an annotation points at now.
+type Resolution struct {
+	State State
+	// The paragraph holding the quote, or the annotated lines
+	Text string
+}
+
+// Resolve finds what a points at in content, the current text of its file.
+func Resolve(content []byte, a kdl.Annotation) Resolution {
+	text := string(content)
+	switch a.Anchor.Kind {
+	case kdl.AnchorRect, kdl.AnchorPage:
+		return Resolution{State: Unchecked}
+	case kdl.AnchorLines:
+		lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+		if strings.HasSuffix(text, "\n") {
+			lines = lines[:len(lines)-1]
+		}
+		if a.Anchor.StartLine < 1 || a.Anchor.EndLine > len(lines) || a.Anchor.StartLine > a.Anchor.EndLine {
+			return Resolution{State: Missing}
+		}
+		return Resolution{State: Anchored, Text: strings.Join(lines[a.Anchor.StartLine-1:a.Anchor.EndLine], "\n")}
+	}
+
+	var moved string
+	for _, p := range Paragraphs(text) {
+		if !Contains(p, a.TargetText) {
+			continue
+		}
+		if ContextHash(p) == a.ContextHash {
+			return Resolution{State: Anchored, Text: p}
+		}
+		if moved == "" {
+			moved = p
+		}
+	}
+	if moved != "" {
+		return Resolution{State: Moved, Text: moved}
+	}
+	return Resolution{State: Missing}
+}
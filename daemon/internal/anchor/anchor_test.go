@@ -0,0 +1,66 @@
+package anchor
+
+import (
+	"testing"
+
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+const doc = "# Install\r\n\r\nRun the install steps\nin order.  \n\n\n```sh\nmake\n```\n"
+
+func TestParagraphs(t *testing.T) {
+	paras := Paragraphs(doc)
+	want := []string{"# Install", "Run the install steps\nin order.", "```sh\nmake\n```"}
+	if len(paras) != len(want) {
+		t.Fatalf("Paragraphs = %q, want %q", paras, want)
+	}
+	for i := range want {
+		if paras[i] != want[i] {
+			t.Errorf("Paragraph %d = %q, want %q", i, paras[i], want[i])
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	// Quoted from the rendered text, across the source's line break
+	p, err := Context(doc, "install  steps in")
+	if err != nil || p != "Run the install steps\nin order." {
+		t.Errorf("Context = %q, %v", p, err)
+	}
+	if p, err := Context(doc, "Install"); err != nil || p != "# Install" {
+		t.Errorf("Context of a heading = %q, %v", p, err)
+	}
+	if _, err := Context(doc+"\nMore install steps\n", "install steps"); err != ErrAmbiguous {
+		t.Errorf("Context of a repeated quote error = %v, want ErrAmbiguous", err)
+	}
+	if _, err := Context(doc, "uninstall"); err != ErrNotFound {
+		t.Errorf("Context of a missing quote error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	para := "Run the install steps\nin order."
+	ann := kdl.Annotation{TargetText: "install steps", ContextHash: ContextHash(para)}
+
+	if r := Resolve([]byte(doc), ann); r.State != Anchored || r.Text != para {
+		t.Errorf("Resolve = %+v, want anchored", r)
+	}
+	edited := []byte("# Install\n\nRun the install steps\nin any order.\n")
+	if r := Resolve(edited, ann); r.State != Moved || r.Text != "Run the install steps\nin any order." {
+		t.Errorf("Resolve after an edit = %+v, want moved", r)
+	}
+	if r := Resolve([]byte("# Install\n\nJust run make.\n"), ann); r.State != Missing {
+		t.Errorf("Resolve without the quote = %+v, want missing", r)
+	}
+
+	lines := kdl.Annotation{Anchor: kdl.Anchor{Kind: kdl.AnchorLines, StartLine: 2, EndLine: 3}}
+	if r := Resolve([]byte("a\nb\nc\n"), lines); r.State != Anchored || r.Text != "b\nc" {
+		t.Errorf("Resolve lines = %+v", r)
+	}
+	if r := Resolve([]byte("a\nb\n"), lines); r.State != Missing {
+		t.Errorf("Resolve lines past the end = %+v, want missing", r)
+	}
+	if r := Resolve(nil, kdl.Annotation{Anchor: kdl.Anchor{Kind: kdl.AnchorRect}}); r.State != Unchecked {
+		t.Errorf("Resolve rect = %+v, want unchecked", r)
+	}
+}
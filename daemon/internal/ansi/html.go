@@ -0,0 +1,232 @@
+// Package ansi converts terminal output with ANSI escape sequences into
+// plain text or styled HTML.
+package ansi
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// escapeRe matches CSI sequences (colours, cursor movement, ...), OSC
+// sequences (titles, hyperlinks) and other two-byte escapes.
+var escapeRe = regexp.MustCompile("\x1b(?:\\[[0-9;:?<=>]*[ -/]*[@-~]|\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|[@-Z\\\\-_])")
+
+// Strip removes all escape sequences from s.
+func Strip(s string) string {
+	return escapeRe.ReplaceAllString(s, "")
+}
+
+// palette is the xterm palette for the 16 basic colours.
+var palette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// style is the SGR state in effect for a run of text.
+type style struct {
+	fg, bg                            string
+	bold, dim, italic, under, inverse bool
+}
+
+func (st style) css() string {
+	fg, bg := st.fg, st.bg
+	if st.inverse {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = "var(--bg)"
+		}
+		if bg == "" {
+			bg = "var(--fg)"
+		}
+	}
+	var b strings.Builder
+	if fg != "" {
+		fmt.Fprintf(&b, "color:%s;", fg)
+	}
+	if bg != "" {
+		fmt.Fprintf(&b, "background:%s;", bg)
+	}
+	if st.bold {
+		b.WriteString("font-weight:bold;")
+	}
+	if st.dim {
+		b.WriteString("opacity:.6;")
+	}
+	if st.italic {
+		b.WriteString("font-style:italic;")
+	}
+	if st.under {
+		b.WriteString("text-decoration:underline;")
+	}
+	return b.String()
+}
+
+// HTML converts terminal output into a <pre> block, turning SGR colour and
+// style sequences into inline styles and dropping every other sequence.
+func HTML(s string) string {
+	var b strings.Builder
+	b.WriteString("<pre>")
+
+	var cur style
+	open := false
+	emit := func(text string) {
+		if text == "" {
+			return
+		}
+		b.WriteString(html.EscapeString(text))
+	}
+
+	last := 0
+	for _, loc := range escapeRe.FindAllStringIndex(s, -1) {
+		emit(s[last:loc[0]])
+		last = loc[1]
+
+		seq := s[loc[0]:loc[1]]
+		if !strings.HasPrefix(seq, "\x1b[") || !strings.HasSuffix(seq, "m") {
+			continue
+		}
+		next := applySGR(cur, seq[2:len(seq)-1])
+		if next == cur {
+			continue
+		}
+		cur = next
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		if css := cur.css(); css != "" {
+			fmt.Fprintf(&b, `<span style="%s">`, css)
+			open = true
+		}
+	}
+	emit(s[last:])
+	if open {
+		b.WriteString("</span>")
+	}
+
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// Document wraps HTML(s) in a standalone page with a dark terminal theme.
+func Document(title, s string) string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>` + html.EscapeString(title) + `</title>
+<style>
+:root { --fg: #e5e5e5; --bg: #1e1e1e; }
+body { margin: 0; background: var(--bg); color: var(--fg); }
+pre { margin: 0; padding: 8px; font: 13px/1.35 monospace; white-space: pre-wrap; word-break: break-all; }
+</style>
+</head>
+<body>
+` + HTML(s) + `
+</body>
+</html>
+`
+}
+
+// applySGR returns st updated by the parameters of an SGR sequence.
+func applySGR(st style, params string) style {
+	if params == "" {
+		return style{}
+	}
+	codes := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	for i := 0; i < len(codes); i++ {
+		n, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			st = style{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.dim = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.under = true
+		case n == 7:
+			st.inverse = true
+		case n == 22:
+			st.bold, st.dim = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.under = false
+		case n == 27:
+			st.inverse = false
+		case n >= 30 && n <= 37:
+			st.fg = palette[n-30]
+		case n >= 90 && n <= 97:
+			st.fg = palette[n-90+8]
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = palette[n-40]
+		case n >= 100 && n <= 107:
+			st.bg = palette[n-100+8]
+		case n == 49:
+			st.bg = ""
+		case n == 38 || n == 48:
+			color, used := extendedColor(codes[i+1:])
+			i += used
+			if n == 38 {
+				st.fg = color
+			} else {
+				st.bg = color
+			}
+		}
+	}
+	return st
+}
+
+// extendedColor parses the arguments of a 38/48 sequence ("5;n" or
+// "2;r;g;b") and returns the colour and how many codes it consumed.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+	num := func(i int) int {
+		if i >= len(codes) {
+			return 0
+		}
+		n, _ := strconv.Atoi(codes[i])
+		return max(0, min(n, 255))
+	}
+	switch codes[0] {
+	case "5":
+		return color256(num(1)), min(2, len(codes))
+	case "2":
+		return fmt.Sprintf("#%02x%02x%02x", num(1), num(2), num(3)), min(4, len(codes))
+	}
+	return "", 1
+}
+
+// color256 maps an xterm 256-colour index to a CSS colour.
+func color256(n int) string {
+	switch {
+	case n < 16:
+		return palette[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		g := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+}
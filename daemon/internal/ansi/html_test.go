@@ -0,0 +1,25 @@
+package ansi
+
+import "testing"
+
+func TestStrip(t *testing.T) {
+	in := "\x1b[1;32mok\x1b[0m \x1b]0;title\x07done\x1b[2K"
+	if got := Strip(in); got != "ok done" {
+		t.Errorf("Strip = %q", got)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"a < b", "<pre>a &lt; b</pre>"},
+		{"\x1b[31mred\x1b[0m plain", `<pre><span style="color:#cd0000;">red</span> plain</pre>`},
+		{"\x1b[1;38;5;196mhot\x1b[m", `<pre><span style="color:#ff0000;font-weight:bold;">hot</span></pre>`},
+		{"\x1b[48;2;1;2;3mbg", `<pre><span style="background:#010203;">bg</span></pre>`},
+		{"\x1b[?25lcursor\x1b[H", "<pre>cursor</pre>"},
+	}
+	for _, c := range cases {
+		if got := HTML(c.in); got != c.want {
+			t.Errorf("HTML(%q)\n got  %s\n want %s", c.in, got, c.want)
+		}
+	}
+}
@@ -40,7 +40,7 @@ func (m *Manager) Register(filePath string) (string, error) {
 		return "", err
 	}
 
-	id := generateID()
+	id := NewID()
 
 	m.mu.Lock()
 	m.assets[id] = assetEntry{
@@ -52,23 +52,31 @@ func (m *Manager) Register(filePath string) (string, error) {
 	return id, nil
 }
 
-// ServeHTTP handles requests for /assets/{id}
-func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	id := filepath.Base(r.URL.Path)
-
+// Lookup returns the file path of a registered asset that has not expired.
+func (m *Manager) Lookup(id string) (string, bool) {
 	m.mu.RLock()
 	entry, ok := m.assets[id]
 	m.mu.RUnlock()
 
 	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.filePath, true
+}
+
+// ServeHTTP handles requests for /assets/{id}
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, ok := m.Lookup(filepath.Base(r.URL.Path))
+	if !ok {
 		http.NotFound(w, r)
 		return
 	}
 
-	http.ServeFile(w, r, entry.filePath)
+	http.ServeFile(w, r, filePath)
 }
 
-func generateID() string {
+// NewID returns a random, unguessable identifier suitable for capability URLs.
+func NewID() string {
 	b := make([]byte, 8) // Increased to 8 bytes for more entropy
 	rand.Read(b)
 	return hex.EncodeToString(b)
@@ -86,4 +94,4 @@ func (m *Manager) cleanupRoutine() {
 		}
 		m.mu.Unlock()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,260 @@
+// Package browse lists and searches files under a set of configured root
+// directories, refusing anything that resolves outside them.
+package browse
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxSearchVisits bounds how many entries one search walks.
+const maxSearchVisits = 100000
+
+var (
+	ErrNoRoots      = errors.New("no browse_roots are configured")
+	ErrUnknownRoot  = errors.New("not a browse root")
+	ErrOutsideRoots = errors.New("path is outside the browse roots")
+)
+
+// Entry is a file or directory under a root.
+type Entry struct {
+	Name string
+	// Relative to the root, "/"-separated
+	Path    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+	// Score of the search match; higher is better
+	Score int
+}
+
+// Browser gives access to files under its roots.
+type Browser struct {
+	roots []string
+}
+
+// New returns a Browser for roots, which must be absolute paths.
+func New(roots []string) (*Browser, error) {
+	b := &Browser{}
+	for _, r := range roots {
+		if !filepath.IsAbs(r) {
+			return nil, fmt.Errorf("browse root %q is not an absolute path", r)
+		}
+		b.roots = append(b.roots, filepath.Clean(r))
+	}
+	return b, nil
+}
+
+// Roots returns the configured roots, which identify them in other calls.
+func (b *Browser) Roots() []string {
+	return b.roots
+}
+
+// Resolve returns the real path of rel under root, with symlinks resolved.
+// It fails if root is not one of the roots or the result lies outside it.
+func (b *Browser) Resolve(root, rel string) (string, error) {
+	_, real, err := b.resolve(root, rel)
+	return real, err
+}
+
+// resolve is Resolve, also returning the real path of the root.
+func (b *Browser) resolve(root, rel string) (string, string, error) {
+	if len(b.roots) == 0 {
+		return "", "", ErrNoRoots
+	}
+	found := false
+	for _, r := range b.roots {
+		if r == filepath.Clean(root) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", "", fmt.Errorf("%s: %w", root, ErrUnknownRoot)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", "", err
+	}
+	real, err := filepath.EvalSymlinks(filepath.Join(realRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", "", err
+	}
+	if !within(realRoot, real) {
+		return "", "", fmt.Errorf("%s: %w", rel, ErrOutsideRoots)
+	}
+	return realRoot, real, nil
+}
+
+// List returns the entries of the directory rel under root, directories
+// first, then by name.
+func (b *Browser) List(root, rel string) ([]Entry, error) {
+	realRoot, dir, err := b.resolve(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	dirents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := relPrefix(realRoot, dir)
+	entries := make([]Entry, 0, len(dirents))
+	for _, d := range dirents {
+		fi, err := d.Info()
+		if err != nil {
+			continue
+		}
+		e := entryFor(prefix+d.Name(), fi)
+		// Report what a symlink points at, so it can be opened or entered
+		if d.Type()&fs.ModeSymlink != 0 {
+			if target, err := os.Stat(filepath.Join(dir, d.Name())); err == nil {
+				e = entryFor(prefix+d.Name(), target)
+			}
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// Search walks the directory rel under root and returns the entries whose
+// names fuzzily match query, best first. Hidden directories are skipped
+// unless the query itself starts with a dot.
+func (b *Browser) Search(root, rel, query string) ([]Entry, error) {
+	realRoot, dir, err := b.resolve(root, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := relPrefix(realRoot, dir)
+	hidden := strings.HasPrefix(query, ".")
+	visits := 0
+	var matches []Entry
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return nil
+		}
+		if visits++; visits > maxSearchVisits {
+			return filepath.SkipAll
+		}
+		if !hidden && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		score, ok := Match(query, d.Name())
+		if !ok {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		sub, _ := filepath.Rel(dir, path)
+		e := entryFor(prefix+filepath.ToSlash(sub), fi)
+		e.Score = score
+		matches = append(matches, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	return matches, nil
+}
+
+// Match reports whether the letters of pattern appear in name in order,
+// ignoring case, and scores the match. Matches at word starts and runs of
+// consecutive letters score higher, as do shorter names.
+func Match(pattern, name string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	n := []rune(name)
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score, pi, prev := 0, 0, -2
+	for i, r := range n {
+		if pi == len(p) {
+			break
+		}
+		if unicode.ToLower(r) != p[pi] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 5
+		}
+		if i == 0 || isBoundary(n[i-1], r) {
+			score += 8
+		}
+		prev = i
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	if strings.Contains(strings.ToLower(name), string(p)) {
+		score += 10
+	}
+	return score - len(n)/8, true
+}
+
+// isBoundary reports whether cur starts a word after prev, as in "foo_bar",
+// "foo.bar" or "fooBar".
+func isBoundary(prev, cur rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+func entryFor(rel string, fi fs.FileInfo) Entry {
+	e := Entry{
+		// As listed, not the name of a symlink's target
+		Name:    rel[strings.LastIndex(rel, "/")+1:],
+		Path:    rel,
+		IsDir:   fi.IsDir(),
+		ModTime: fi.ModTime(),
+	}
+	if !e.IsDir {
+		e.Size = fi.Size()
+	}
+	return e
+}
+
+// relPrefix returns dir relative to root with a trailing slash, or "" for
+// the root itself.
+func relPrefix(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel) + "/"
+}
+
+func within(root, path string) bool {
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
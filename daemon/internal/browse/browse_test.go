@@ -0,0 +1,96 @@
+package browse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "docs"), 0755)
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0644)
+	os.Symlink(outside, filepath.Join(root, "escape"))
+
+	b, err := New([]string{root})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := b.Resolve(root, "docs"); err != nil {
+		t.Errorf("Resolve(docs) failed: %v", err)
+	}
+	for _, rel := range []string{"../" + filepath.Base(outside) + "/secret", "escape/secret", "escape"} {
+		if _, err := b.Resolve(root, rel); !errors.Is(err, ErrOutsideRoots) {
+			t.Errorf("Resolve(%q): expected ErrOutsideRoots, got %v", rel, err)
+		}
+	}
+	if _, err := b.Resolve(outside, ""); !errors.Is(err, ErrUnknownRoot) {
+		t.Errorf("Expected ErrUnknownRoot, got %v", err)
+	}
+	if _, err := New([]string{"relative/dir"}); err == nil {
+		t.Errorf("Expected relative root to be rejected")
+	}
+}
+
+func TestListAndSearch(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"README.md", "docs/design_notes.md", "docs/deploy.sh", "src/main.go", ".git/config"} {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("hello"), 0644)
+	}
+	b, _ := New([]string{root})
+
+	entries, err := b.List(root, "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	// Directories first
+	if len(names) != 4 || names[0] != ".git" || names[1] != "docs" || names[3] != "README.md" {
+		t.Errorf("Unexpected listing %v", names)
+	}
+	if entries[3].Size != 5 || entries[3].Path != "README.md" {
+		t.Errorf("Unexpected entry %+v", entries[3])
+	}
+
+	entries, _ = b.List(root, "docs")
+	if entries[0].Path != "docs/deploy.sh" {
+		t.Errorf("Expected paths relative to the root, got %s", entries[0].Path)
+	}
+
+	matches, err := b.Search(root, "", "dn")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) == 0 || matches[0].Path != "docs/design_notes.md" {
+		t.Errorf("Expected design_notes.md first, got %+v", matches)
+	}
+	if matches, _ := b.Search(root, "", "config"); len(matches) != 0 {
+		t.Errorf("Expected hidden directories to be skipped, got %+v", matches)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	if _, ok := Match("mgo", "main.go"); !ok {
+		t.Errorf("Expected mgo to match main.go")
+	}
+	if _, ok := Match("gom", "main.go"); ok {
+		t.Errorf("Expected gom not to match main.go")
+	}
+	word, _ := Match("dn", "design_notes.md")
+	mid, _ := Match("dn", "addendum.md")
+	if word <= mid {
+		t.Errorf("Expected word starts to score higher: %d vs %d", word, mid)
+	}
+	exact, _ := Match("notes", "notes.md")
+	scattered, _ := Match("notes", "not_every_step.md")
+	if exact <= scattered {
+		t.Errorf("Expected a substring to score higher: %d vs %d", exact, scattered)
+	}
+}
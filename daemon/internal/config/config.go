@@ -2,13 +2,135 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 type Config struct {
 	Port     int    `json:"port"`
 	CertFile string `json:"cert_file"`
 	KeyFile  string `json:"key_file"`
+	// Directory for persistent daemon state (outbox, etc.)
+	StateDir string       `json:"state_dir"`
+	Outbox   OutboxConfig `json:"outbox"`
+	// Which device receives a view when the CLI does not name one with
+	// --to: "all" (default), "last_active" or "focused".
+	DefaultTarget string `json:"default_target"`
+	// Client name -> pre-shared key. When set, WebSocket clients must send
+	// one in the X-Zelland-PSK header (or as a Bearer token), and RPCs that
+	// control the host are only accepted from authenticated clients.
+	Tokens map[string]string `json:"tokens"`
+	Zellij ZellijConfig      `json:"zellij"`
+	// Named commands the app shows as buttons
+	QuickActions []QuickAction   `json:"quick_actions"`
+	Clipboard    ClipboardConfig `json:"clipboard"`
+	Uploads      UploadConfig    `json:"uploads"`
+	// Absolute directories clients may browse and open files from
+	BrowseRoots []string         `json:"browse_roots"`
+	Edit        EditConfig       `json:"edit"`
+	Annotations AnnotationConfig `json:"annotations"`
+}
+
+// AnnotationConfig controls where annotations are kept.
+type AnnotationConfig struct {
+	// "kdl" (sidecar files, default) or "sqlite" (one database, searchable;
+	// needs a daemon built with cgo)
+	Backend string `json:"backend"`
+	// Where kdl sidecars go: "sibling" (notes.md.zelland.kdl, default),
+	// "hidden" (.zelland/notes.md.kdl) or "central" (under DataDir, keyed by
+	// a hash of the file's path)
+	Storage string `json:"storage"`
+	// Central sidecars, those of files in read-only directories, and the
+	// SQLite database unless Database is set
+	DataDir  string `json:"data_dir"`
+	Database string `json:"database"`
+}
+
+// EditConfig controls saving MARKDOWN and CODE assets edited on a client.
+type EditConfig struct {
+	// Keep the previous content of a saved file as <file>.bak
+	Backup bool `json:"backup"`
+}
+
+// UploadConfig controls files sent from clients to the host.
+type UploadConfig struct {
+	// Where uploads go unless `zelland receive` names a directory
+	InboxDir     string `json:"inbox_dir"`
+	MaxFileBytes int64  `json:"max_file_bytes"`
+	// Total size of the inbox, including unfinished uploads; 0 is unlimited.
+	// Directories named by `zelland receive` are not limited
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// ClipboardConfig limits `zelland copy` and `zelland paste`.
+type ClipboardConfig struct {
+	// Largest content accepted in either direction
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+// QuickAction is a command clients can run by name.
+type QuickAction struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Run with sh -c
+	Command string            `json:"command"`
+	Dir     string            `json:"dir"`
+	Env     map[string]string `json:"env"`
+	// The app must ask the user before running it
+	Confirm bool `json:"confirm"`
+	// "log" (default) opens the output as a LOG view on the requesting
+	// device; "notify" only sends a notification with the last lines when
+	// the command finishes
+	Output string `json:"output"`
+}
+
+// ZellijConfig describes how the daemon drives the local Zellij install.
+type ZellijConfig struct {
+	Binary string          `json:"binary"`
+	Web    ZellijWebConfig `json:"web"`
+	// `zellij action` names clients may trigger. "run" allows starting any
+	// command in a new pane, so it is not allowed by default and has to be
+	// added here to use it
+	AllowedActions []string `json:"allowed_actions"`
+}
+
+// ZellijWebConfig controls the supervised `zellij web` server.
+type ZellijWebConfig struct {
+	// Start `zellij web` with the daemon and restart it if it exits
+	Autostart bool     `json:"autostart"`
+	Port      int      `json:"port"`
+	Args      []string `json:"args"`
+}
+
+// OutboxConfig controls which outbound messages are kept for clients that
+// reconnect later.
+type OutboxConfig struct {
+	MaxMessages int `json:"max_messages"`
+	// Envelope payload name (e.g. "open_view", "notification") -> how long
+	// to keep it. Payloads not listed are not retained.
+	Retention map[string]Duration `json:"retention"`
+}
+
+// Duration is a time.Duration that reads from JSON strings like "10m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
 
 func Load(path string) (*Config, error) {
@@ -17,12 +139,13 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	// Start from the defaults so a partial file only overrides what it sets
+	cfg := Default()
+	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 func Default() *Config {
@@ -30,5 +153,72 @@ func Default() *Config {
 		Port:     8083,
 		CertFile: "",
 		KeyFile:  "",
+		StateDir: defaultStateDir(),
+		Outbox: OutboxConfig{
+			MaxMessages: 500,
+			Retention: map[string]Duration{
+				"open_view":    Duration(10 * time.Minute),
+				"notification": Duration(24 * time.Hour),
+				"annotation":   Duration(10 * time.Minute),
+			},
+		},
+		DefaultTarget: "all",
+		Zellij: ZellijConfig{
+			Binary: "zellij",
+			Web: ZellijWebConfig{
+				Port: 8082,
+			},
+			AllowedActions: []string{
+				"new-pane", "close-pane", "focus-next-pane", "focus-previous-pane",
+				"toggle-floating-panes", "toggle-fullscreen", "new-tab",
+				"go-to-next-tab", "go-to-previous-tab",
+			},
+		},
+		Clipboard: ClipboardConfig{
+			MaxBytes: 1 << 20,
+		},
+		Annotations: AnnotationConfig{
+			Backend: "kdl",
+			Storage: "sibling",
+			DataDir: defaultAnnotationDir(),
+		},
+		Uploads: UploadConfig{
+			InboxDir:     defaultInboxDir(),
+			MaxFileBytes: 512 << 20,
+			QuotaBytes:   4 << 30,
+		},
+	}
+}
+
+func defaultStateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "zelland")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "zelland")
+}
+
+func defaultInboxDir() string {
+	if dir := os.Getenv("XDG_DOWNLOAD_DIR"); dir != "" {
+		return filepath.Join(dir, "zelland")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "zelland-inbox")
+	}
+	return filepath.Join(home, "Downloads", "zelland")
+}
+
+func defaultAnnotationDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "zelland", "annotations")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "zelland-annotations")
 	}
+	return filepath.Join(home, ".local", "share", "zelland", "annotations")
 }
@@ -2,29 +2,153 @@ package kdl
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/sblinch/kdl-go"
 )
 
+// Status values of an annotation thread. Sidecars written before threads
+// existed have no status, which means open.
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+)
+
 type Annotation struct {
 	ID        string `kdl:"id,prop"`
 	User      string `kdl:"user,prop,optional"`
 	Timestamp int64  `kdl:"timestamp,prop,optional"`
+	// Thread state; resolved_by and resolved_at are set while resolved
+	Status     string `kdl:"status,prop,optional,omitempty"`
+	ResolvedBy string `kdl:"resolved_by,prop,optional,omitempty"`
+	ResolvedAt int64  `kdl:"resolved_at,prop,optional,omitempty"`
 
 	// Children nodes
 	ContextHash string `kdl:"context_hash,child"`
 	TargetText  string `kdl:"target_text,child"`
 	Body        string `kdl:"body,child"`
+	// Where a non-text annotation points; zero for text, which is anchored
+	// by TargetText and ContextHash
+	Anchor  Anchor  `kdl:"anchor,child,optional,omitempty"`
+	Replies []Reply `kdl:"reply,multiple,optional,omitempty"`
+}
+
+// Anchor kinds other than text.
+const (
+	// A rectangle in an image
+	AnchorRect = "rect"
+	// A PDF page, with a quote or a rectangle on it
+	AnchorPage = "page"
+	// A range of lines in a source file
+	AnchorLines = "lines"
+)
+
+// Anchor locates an annotation in an image, PDF or source file.
+type Anchor struct {
+	Kind string `kdl:"kind,prop"`
+	// Rectangle as fractions of the image or page size, from the top left
+	X float64 `kdl:"x,prop,optional,omitempty"`
+	Y float64 `kdl:"y,prop,optional,omitempty"`
+	W float64 `kdl:"w,prop,optional,omitempty"`
+	H float64 `kdl:"h,prop,optional,omitempty"`
+	// 1-based page number
+	Page int `kdl:"page,prop,optional,omitempty"`
+	// 1-based, inclusive
+	StartLine int `kdl:"start_line,prop,optional,omitempty"`
+	EndLine   int `kdl:"end_line,prop,optional,omitempty"`
+	// Text quoted from the page
+	Quote string `kdl:"quote,child,optional,omitempty"`
+}
+
+// Reply is a message in an annotation's discussion.
+type Reply struct {
+	ID        string `kdl:"id,prop"`
+	User      string `kdl:"user,prop,optional"`
+	Timestamp int64  `kdl:"timestamp,prop,optional"`
+	// The reply this one answers; empty for the annotation itself
+	Parent string `kdl:"parent,prop,optional,omitempty"`
+	Body   string `kdl:"body,child"`
+}
+
+// Resolved reports whether the thread has been marked resolved.
+func (a *Annotation) Resolved() bool {
+	return a.Status == StatusResolved
+}
+
+// Resolve marks the thread resolved by user at the given Unix time.
+func (a *Annotation) Resolve(user string, at int64) {
+	a.Status = StatusResolved
+	a.ResolvedBy = user
+	a.ResolvedAt = at
+}
+
+// Reopen marks the thread open again.
+func (a *Annotation) Reopen() {
+	a.Status = StatusOpen
+	a.ResolvedBy = ""
+	a.ResolvedAt = 0
+}
+
+// FindReply returns the index of the reply with the given ID, or -1.
+func (a *Annotation) FindReply(id string) int {
+	for i, r := range a.Replies {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// RemoveReply removes a reply and the replies answering it, directly or
+// not. It reports whether the reply was found.
+func (a *Annotation) RemoveReply(id string) bool {
+	if a.FindReply(id) < 0 {
+		return false
+	}
+	removed := map[string]bool{id: true}
+	// Replies come after their parents, so one pass finds all descendants
+	kept := a.Replies[:0]
+	for _, r := range a.Replies {
+		if removed[r.ID] || removed[r.Parent] {
+			removed[r.ID] = true
+			continue
+		}
+		kept = append(kept, r)
+	}
+	a.Replies = kept
+	return true
+}
+
+// FindThread returns the index of the annotation with the given ID, or
+// holding a reply with it, or -1.
+func FindThread(anns []Annotation, id string) int {
+	for i := range anns {
+		if anns[i].ID == id || anns[i].FindReply(id) >= 0 {
+			return i
+		}
+	}
+	return -1
 }
 
 type KDLFile struct {
+	// The annotated file, for sidecars kept away from it
+	Source      string       `kdl:"source,optional,omitempty"`
 	Annotations []Annotation `kdl:"annotation,multiple"`
 }
 
 func Load(path string) ([]Annotation, error) {
+	doc, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Annotations, nil
+}
+
+// LoadFile reads a whole sidecar. A missing file is an empty one.
+func LoadFile(path string) (*KDLFile, error) {
 	f, err := os.Open(path)
 	if os.IsNotExist(err) {
-		return []Annotation{}, nil
+		return &KDLFile{Annotations: []Annotation{}}, nil
 	}
 	if err != nil {
 		return nil, err
@@ -36,18 +160,46 @@ func Load(path string) ([]Annotation, error) {
 		return nil, err
 	}
 
-	return doc.Annotations, nil
+	return &doc, nil
 }
 
 func Save(path string, annotations []Annotation) error {
-	f, err := os.Create(path)
+	return SaveFile(path, &KDLFile{Annotations: annotations})
+}
+
+// SaveFile writes a whole sidecar. It replaces the file in one step, so
+// readers never see it half written. The exception is a sidecar that can be
+// written in a directory that cannot: it is overwritten in place, which is
+// not atomic.
+func SaveFile(path string, doc *KDLFile) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
+		// No temporary file can be made next to it, but the sidecar itself
+		// may still be writable
+		if f, err = os.Create(path); err != nil {
+			return err
+		}
+		err = kdl.NewEncoder(f).Encode(doc)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
 		return err
 	}
-	defer f.Close()
-
-	doc := KDLFile{Annotations: annotations}
-	return kdl.NewEncoder(f).Encode(doc)
+	tmp := f.Name()
+	err = kdl.NewEncoder(f).Encode(doc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
 }
 
 // Append adds or updates an annotation in the file
@@ -71,4 +223,22 @@ func Append(path string, newAnn Annotation) error {
 	}
 
 	return Save(path, anns)
-}
\ No newline at end of file
+}
+
+// Modify loads the annotations in path, passes them to fn and saves what it
+// returns, unless fn fails. A non-empty source is recorded as the file the
+// annotations are about.
+func Modify(path, source string, fn func([]Annotation) ([]Annotation, error)) error {
+	doc, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	doc.Annotations, err = fn(doc.Annotations)
+	if err != nil {
+		return err
+	}
+	if source != "" {
+		doc.Source = source
+	}
+	return SaveFile(path, doc)
+}
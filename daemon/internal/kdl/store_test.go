@@ -3,6 +3,7 @@ package kdl
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -93,3 +94,66 @@ func TestKDLSerialization(t *testing.T) {
 		t.Errorf("Expected 3 annotations after append, got %d", len(anns))
 	}
 }
+
+// Saving and loading threads and anchors is tested for every backend in
+// package store.
+func TestThreadHelpers(t *testing.T) {
+	ann := Annotation{ID: "ann-1", User: "alice", Body: "Why?"}
+	ann.Replies = []Reply{
+		{ID: "r1", User: "bob", Body: "Because"},
+		{ID: "r2", User: "alice", Parent: "r1", Body: "Fair"},
+		{ID: "r3", User: "carol", Parent: "r2", Body: "Agreed"},
+		{ID: "r4", User: "carol", Body: "Unrelated"},
+	}
+	ann.Resolve("bob", 42)
+	if !ann.Resolved() || ann.ResolvedBy != "bob" || ann.ResolvedAt != 42 {
+		t.Errorf("Resolve left %+v", ann)
+	}
+	if i := FindThread([]Annotation{ann}, "r3"); i != 0 {
+		t.Errorf("FindThread(r3) = %d, want 0", i)
+	}
+
+	if !ann.RemoveReply("r1") {
+		t.Fatal("RemoveReply(r1) did not find the reply")
+	}
+	if len(ann.Replies) != 1 || ann.Replies[0].ID != "r4" {
+		t.Errorf("Expected only r4 after removing r1 and its answers, got %+v", ann.Replies)
+	}
+
+	ann.Reopen()
+	if ann.Resolved() || ann.ResolvedBy != "" || ann.ResolvedAt != 0 {
+		t.Errorf("Reopen left %+v", ann)
+	}
+}
+
+func TestLoadLegacySidecar(t *testing.T) {
+	kdlPath := filepath.Join(t.TempDir(), "legacy.kdl")
+	legacy := `annotation id="ann-1" user="alice" timestamp=123456789 {
+	context_hash "sha256:abc"
+	target_text "Hello"
+	body "World"
+}
+`
+	if err := os.WriteFile(kdlPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anns, err := Load(kdlPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(anns) != 1 || anns[0].Body != "World" || anns[0].Resolved() || len(anns[0].Replies) != 0 {
+		t.Fatalf("Unexpected legacy annotation: %+v", anns)
+	}
+
+	// Saving it again adds nothing for the unused thread fields
+	if err := Save(kdlPath, anns); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	content, _ := os.ReadFile(kdlPath)
+	for _, field := range []string{"status", "resolved_by", "resolved_at", "reply", "anchor"} {
+		if strings.Contains(string(content), field) {
+			t.Errorf("Saved legacy annotation contains %q:\n%s", field, content)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package logtail
+
+// Line is a single line of log output tagged with its position in the stream.
+type Line struct {
+	Seq  uint64
+	Text string
+}
+
+// Ring is a fixed-size buffer holding the most recent lines of a stream so
+// that clients joining late can catch up.
+type Ring struct {
+	lines []Line
+	start int
+	count int
+	next  uint64
+}
+
+func NewRing(capacity int) *Ring {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Ring{
+		lines: make([]Line, capacity),
+		next:  1,
+	}
+}
+
+// Append stores text as the next line, evicting the oldest line when full.
+func (r *Ring) Append(text string) Line {
+	line := Line{Seq: r.next, Text: text}
+	r.next++
+
+	idx := (r.start + r.count) % len(r.lines)
+	r.lines[idx] = line
+	if r.count < len(r.lines) {
+		r.count++
+	} else {
+		r.start = (r.start + 1) % len(r.lines)
+	}
+	return line
+}
+
+// Since returns the buffered lines with a sequence number greater than seq.
+func (r *Ring) Since(seq uint64) []Line {
+	var out []Line
+	for i := 0; i < r.count; i++ {
+		line := r.lines[(r.start+i)%len(r.lines)]
+		if line.Seq > seq {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// LastSeq returns the sequence number of the most recently appended line.
+func (r *Ring) LastSeq() uint64 {
+	return r.next - 1
+}
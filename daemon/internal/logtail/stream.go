@@ -0,0 +1,278 @@
+package logtail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultCapacity is the number of lines kept for late joiners.
+const DefaultCapacity = 2000
+
+// Event marks a discontinuity in a stream.
+type Event int
+
+const (
+	EventNone Event = iota
+	EventTruncated
+	EventRotated
+	EventEOF
+)
+
+// Update is delivered to the stream's listener whenever lines are appended
+// or an event occurs.
+type Update struct {
+	Lines []Line
+	Event Event
+}
+
+// Stream is a named, buffered sequence of log lines.
+type Stream struct {
+	ID    string
+	Title string
+
+	mu     sync.Mutex
+	ring   *Ring
+	closed bool
+	notify func(*Stream, Update)
+}
+
+// NewStream creates a stream. notify is called (outside the stream lock)
+// for every update and may be nil.
+func NewStream(id, title string, capacity int, notify func(*Stream, Update)) *Stream {
+	return &Stream{
+		ID:     id,
+		Title:  title,
+		ring:   NewRing(capacity),
+		notify: notify,
+	}
+}
+
+// Append adds lines to the stream.
+func (s *Stream) Append(texts ...string) {
+	if len(texts) == 0 {
+		return
+	}
+	s.mu.Lock()
+	lines := make([]Line, 0, len(texts))
+	for _, t := range texts {
+		lines = append(lines, s.ring.Append(t))
+	}
+	s.mu.Unlock()
+
+	s.emit(Update{Lines: lines})
+}
+
+// Mark records a discontinuity such as truncation or rotation.
+func (s *Stream) Mark(ev Event) {
+	s.emit(Update{Event: ev})
+}
+
+// Close marks the end of the stream. Buffered lines remain readable.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.emit(Update{Event: EventEOF})
+}
+
+// Closed reports whether the stream has ended.
+func (s *Stream) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Since returns the buffered lines after seq.
+func (s *Stream) Since(seq uint64) []Line {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ring.Since(seq)
+}
+
+func (s *Stream) emit(u Update) {
+	if s.notify != nil {
+		s.notify(s, u)
+	}
+}
+
+// MaxLineLength is the longest line kept, in bytes. Longer lines are cut
+// and end in "…".
+const MaxLineLength = 64 * 1024
+
+// ReadFrom appends every line read from r until EOF or ctx is cancelled.
+func (s *Stream) ReadFrom(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReader(r)
+	var line []byte
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(line) <= MaxLineLength {
+			line = append(line, chunk...)
+		}
+		if isPrefix {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.Append(truncateLine(string(line)))
+		line = line[:0]
+	}
+}
+
+// truncateLine cuts line to MaxLineLength, on a rune boundary.
+func truncateLine(line string) string {
+	if len(line) <= MaxLineLength {
+		return line
+	}
+	cut := MaxLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
+
+// pollInterval controls how often Follow checks the file for changes.
+var pollInterval = 500 * time.Millisecond
+
+// initialTail is how much of an existing file Follow reads on start.
+const initialTail = 64 * 1024
+
+// Follow tails the file at path until ctx is cancelled, appending new lines
+// as they are written. Truncation and rotation (the path being replaced by a
+// new file) are detected and reported as events.
+func (s *Stream) Follow(ctx context.Context, path string) error {
+	f, info, err := openFile(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	offset := int64(0)
+	if info.Size() > initialTail {
+		offset = info.Size() - initialTail
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var partial string
+	skipFirst := offset > 0 // the first line is likely cut in half
+	buf := make([]byte, 32*1024)
+
+	// drain appends whatever can be read from f now.
+	drain := func() {
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				offset += int64(n)
+				var lines []string
+				lines, partial = splitLines(partial + string(buf[:n]))
+				if skipFirst {
+					if len(lines) == 0 {
+						partial = ""
+						continue
+					}
+					lines = lines[1:]
+					skipFirst = false
+				}
+				for i, l := range lines {
+					lines[i] = truncateLine(l)
+				}
+				s.Append(lines...)
+				if len(partial) > MaxLineLength {
+					// Keep the start and drop the rest of the line
+					s.Append(truncateLine(partial))
+					partial, skipFirst = "", true
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		drain()
+
+		select {
+		case <-ctx.Done():
+			if partial != "" {
+				s.Append(truncateLine(partial))
+			}
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(path)
+		if err != nil {
+			// The file may be mid-rotation; try again on the next tick.
+			continue
+		}
+
+		switch {
+		case !os.SameFile(info, current):
+			nf, ninfo, err := openFile(path)
+			if err != nil {
+				continue
+			}
+			// Lines written just before the rotation are still in the old file
+			drain()
+			if partial != "" {
+				s.Append(truncateLine(partial))
+			}
+			f.Close()
+			f, info, offset, partial, skipFirst = nf, ninfo, 0, "", false
+			s.Mark(EventRotated)
+		case current.Size() < offset:
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset, partial, skipFirst = 0, "", false
+			s.Mark(EventTruncated)
+		}
+	}
+}
+
+func openFile(path string) (*os.File, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// splitLines splits data into complete lines and returns the trailing
+// incomplete line separately.
+func splitLines(data string) ([]string, string) {
+	parts := strings.Split(data, "\n")
+	lines := parts[:len(parts)-1]
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines, parts[len(parts)-1]
+}
@@ -0,0 +1,188 @@
+package logtail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRingEviction(t *testing.T) {
+	r := NewRing(3)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		r.Append(s)
+	}
+
+	lines := r.Since(0)
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 buffered lines, got %d", len(lines))
+	}
+	if lines[0].Seq != 3 || lines[0].Text != "c" {
+		t.Errorf("Expected oldest line 3:c, got %d:%s", lines[0].Seq, lines[0].Text)
+	}
+
+	lines = r.Since(4)
+	if len(lines) != 1 || lines[0].Text != "e" {
+		t.Errorf("Expected only line e after seq 4, got %+v", lines)
+	}
+}
+
+func TestFollowTruncateAndRotate(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+
+	tempDir, err := os.MkdirTemp("", "logtail-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logPath := filepath.Join(tempDir, "app.log")
+	if err := os.WriteFile(logPath, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var texts []string
+	var events []Event
+	st := NewStream("id", "app.log", 0, func(_ *Stream, u Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, l := range u.Lines {
+			texts = append(texts, l.Text)
+		}
+		if u.Event != EventNone {
+			events = append(events, u.Event)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- st.Follow(ctx, logPath) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			got := strings.Join(texts, ",")
+			mu.Unlock()
+			if got == want {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("Expected lines %q, got %q", want, strings.Join(texts, ","))
+	}
+
+	waitFor("one,two")
+
+	f, _ := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("three\n")
+	f.Close()
+	waitFor("one,two,three")
+
+	os.WriteFile(logPath, []byte("x\n"), 0644) // truncate in place
+	waitFor("one,two,three,x")
+
+	rotated := filepath.Join(tempDir, "app.log.new")
+	os.WriteFile(rotated, []byte("fresh\n"), 0644)
+	os.Rename(rotated, logPath)
+	waitFor("one,two,three,x,fresh")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Follow returned error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] != EventTruncated || events[1] != EventRotated {
+		t.Errorf("Expected truncate then rotate events, got %v", events)
+	}
+}
+
+func TestReadFromLongLines(t *testing.T) {
+	var texts []string
+	st := NewStream("id", "stdin", 0, func(_ *Stream, u Update) {
+		for _, l := range u.Lines {
+			texts = append(texts, l.Text)
+		}
+	})
+
+	long := strings.Repeat("x", 2*1024*1024)
+	input := "first\r\n" + long + "\nafter\nlast"
+	if err := st.ReadFrom(context.Background(), strings.NewReader(input)); err != nil {
+		t.Fatalf("ReadFrom returned error: %v", err)
+	}
+
+	if len(texts) != 4 {
+		t.Fatalf("Expected 4 lines, got %d", len(texts))
+	}
+	if texts[0] != "first" || texts[2] != "after" || texts[3] != "last" {
+		t.Errorf("Unexpected lines around the long one: %q, %q, %q", texts[0], texts[2], texts[3])
+	}
+	if want := strings.Repeat("x", MaxLineLength) + "…"; texts[1] != want {
+		t.Errorf("Expected the long line cut to %d bytes, got %d", MaxLineLength, len(texts[1]))
+	}
+}
+
+func TestFollowKeepsLinesBeforeRotation(t *testing.T) {
+	pollInterval = 200 * time.Millisecond
+
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "app.log")
+	if err := os.WriteFile(logPath, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var texts []string
+	st := NewStream("id", "app.log", 0, func(_ *Stream, u Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, l := range u.Lines {
+			texts = append(texts, l.Text)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- st.Follow(ctx, logPath) }()
+
+	lines := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(texts, ",")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for lines() != "one" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Write the last lines and rotate within one poll interval
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("two\nthree")
+	f.Close()
+	rotated := filepath.Join(tempDir, "app.log.new")
+	os.WriteFile(rotated, []byte("fresh\n"), 0644)
+	os.Rename(rotated, logPath)
+
+	deadline = time.Now().Add(2 * time.Second)
+	for lines() != "one,two,three,fresh" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if got := lines(); got != "one,two,three,fresh" {
+		t.Errorf("Expected lines one,two,three,fresh, got %q", got)
+	}
+}
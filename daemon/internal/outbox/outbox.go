@@ -0,0 +1,218 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// seqBlock is how many sequence numbers are reserved per write of the state
+// file, so that numbers stay monotonic across restarts without a disk write
+// for every message.
+const seqBlock = 1000
+
+type entry struct {
+	Seq       uint64    `json:"seq"`
+	Kind      string    `json:"kind"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Delivered bool      `json:"delivered"`
+	// Device IDs the envelope was addressed to; empty means everyone
+	Targets []string `json:"targets,omitempty"`
+	Data    []byte   `json:"data"` // Marshaled pb.Envelope
+}
+
+// visibleTo reports whether the entry may be replayed to device.
+func (e entry) visibleTo(device string) bool {
+	if len(e.Targets) == 0 {
+		return true
+	}
+	for _, t := range e.Targets {
+		if t == device {
+			return true
+		}
+	}
+	return false
+}
+
+type state struct {
+	Reserved uint64  `json:"reserved"`
+	Entries  []entry `json:"entries"`
+}
+
+// Outbox assigns sequence numbers to outbound envelopes and keeps a bounded,
+// persisted window of them for clients that reconnect later.
+type Outbox struct {
+	path string
+	max  int
+
+	mu       sync.Mutex
+	next     uint64
+	reserved uint64
+	entries  []entry
+}
+
+// Open loads the outbox stored at path, or starts an empty one if the file
+// does not exist. An empty path keeps the outbox in memory only.
+func Open(path string, max int) (*Outbox, error) {
+	o := &Outbox{path: path, max: max, next: 1}
+	if path == "" {
+		return o, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		var st state
+		if err := json.Unmarshal(data, &st); err != nil {
+			return nil, err
+		}
+		o.entries = st.Entries
+		o.next = st.Reserved + 1
+		o.reserved = st.Reserved
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pruneLocked(time.Now())
+	return o, o.saveLocked()
+}
+
+// Record stamps env with the next sequence number and retains it for replay
+// until ttl expires. Envelopes with no ttl are not replayable and get no
+// number, so clients only see gaps where something may really be missing.
+// delivered records whether any client received it when it was sent;
+// targets restricts replay to the given device IDs.
+func (o *Outbox) Record(env *pb.Envelope, kind string, ttl time.Duration, delivered bool, targets []string) error {
+	if ttl <= 0 {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	env.Seq = o.next
+	o.next++
+	if o.next > o.reserved {
+		o.reserved = o.next + seqBlock
+	}
+
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	o.entries = append(o.entries, entry{
+		Seq:       env.Seq,
+		Kind:      kind,
+		ExpiresAt: now.Add(ttl),
+		Delivered: delivered,
+		Targets:   targets,
+		Data:      data,
+	})
+	o.pruneLocked(now)
+	return o.saveLocked()
+}
+
+// Since returns the retained envelopes for device with a sequence number
+// greater than seq, oldest first.
+func (o *Outbox) Since(seq uint64, device string) []*pb.Envelope {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pruneLocked(time.Now())
+	var out []*pb.Envelope
+	for _, e := range o.entries {
+		if e.Seq <= seq || !e.visibleTo(device) {
+			continue
+		}
+		if env := decode(e); env != nil {
+			out = append(out, env)
+		}
+	}
+	return out
+}
+
+// Undelivered returns the retained envelopes for device that never reached
+// any client, oldest first. They stay undelivered until MarkDelivered.
+func (o *Outbox) Undelivered(device string) []*pb.Envelope {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pruneLocked(time.Now())
+	var out []*pb.Envelope
+	for _, e := range o.entries {
+		if e.Delivered || !e.visibleTo(device) {
+			continue
+		}
+		if env := decode(e); env != nil {
+			out = append(out, env)
+		}
+	}
+	return out
+}
+
+// MarkDelivered records that the envelopes with the given sequence numbers
+// reached a client.
+func (o *Outbox) MarkDelivered(seqs ...uint64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, e := range o.entries {
+		if slices.Contains(seqs, e.Seq) {
+			o.entries[i].Delivered = true
+		}
+	}
+	return o.saveLocked()
+}
+
+func (o *Outbox) pruneLocked(now time.Time) {
+	kept := o.entries[:0]
+	for _, e := range o.entries {
+		if now.Before(e.ExpiresAt) {
+			kept = append(kept, e)
+		}
+	}
+	if o.max > 0 && len(kept) > o.max {
+		kept = kept[len(kept)-o.max:]
+	}
+	o.entries = kept
+}
+
+func (o *Outbox) saveLocked() error {
+	if o.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(state{Reserved: o.reserved, Entries: o.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so a crash never leaves half a file
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, o.path)
+}
+
+func decode(e entry) *pb.Envelope {
+	var env pb.Envelope
+	if err := proto.Unmarshal(e.Data, &env); err != nil {
+		return nil
+	}
+	return &env
+}
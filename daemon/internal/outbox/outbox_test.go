@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+)
+
+func notification(title string) *pb.Envelope {
+	return &pb.Envelope{
+		Payload: &pb.Envelope_Notification{
+			Notification: &pb.Notification{Title: title},
+		},
+	}
+}
+
+func TestOutboxReplayAndPersistence(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "outbox-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "outbox.json")
+	o, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	first := notification("first")
+	o.Record(first, "notification", time.Hour, true, nil)
+	ping := &pb.Envelope{Payload: &pb.Envelope_Ping{Ping: &pb.KeepAlive{}}}
+	o.Record(ping, "ping", 0, true, nil)
+	second := notification("second")
+	o.Record(second, "notification", time.Hour, false, nil)
+
+	if first.Seq == 0 || second.Seq != first.Seq+1 {
+		t.Fatalf("Expected consecutive sequence numbers, got %d then %d", first.Seq, second.Seq)
+	}
+	if ping.Seq != 0 {
+		t.Errorf("Expected no sequence number for an envelope that is not kept, got %d", ping.Seq)
+	}
+
+	missed := o.Since(first.Seq, "")
+	if len(missed) != 1 || missed[0].GetNotification().Title != "second" {
+		t.Fatalf("Expected only 'second' after seq %d, got %v", first.Seq, missed)
+	}
+
+	undelivered := o.Undelivered("")
+	if len(undelivered) != 1 || undelivered[0].Seq != second.Seq {
+		t.Fatalf("Expected 'second' to be undelivered, got %v", undelivered)
+	}
+	if len(o.Undelivered("")) != 1 {
+		t.Errorf("Expected envelopes to stay undelivered until marked")
+	}
+	o.MarkDelivered(second.Seq)
+	if len(o.Undelivered("")) != 0 {
+		t.Errorf("Expected no undelivered envelopes after marking them delivered")
+	}
+
+	// Reopen: retained envelopes survive and numbering continues upward
+	o, err = Open(path, 10)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if got := len(o.Since(0, "")); got != 2 {
+		t.Errorf("Expected 2 retained envelopes after reopen, got %d", got)
+	}
+	third := notification("third")
+	o.Record(third, "notification", time.Hour, true, nil)
+	if third.Seq <= second.Seq {
+		t.Errorf("Expected seq after restart to exceed %d, got %d", second.Seq, third.Seq)
+	}
+}
+
+func TestOutboxBoundsAndExpiry(t *testing.T) {
+	o, err := Open("", 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	o.Record(notification("gone"), "notification", time.Nanosecond, true, nil)
+	time.Sleep(time.Millisecond)
+	o.Record(notification("a"), "notification", time.Hour, true, nil)
+	o.Record(notification("b"), "notification", time.Hour, true, nil)
+	o.Record(notification("c"), "notification", time.Hour, true, nil)
+
+	got := o.Since(0, "")
+	if len(got) != 2 || got[0].GetNotification().Title != "b" || got[1].GetNotification().Title != "c" {
+		t.Errorf("Expected the newest unexpired envelopes [b c], got %v", got)
+	}
+}
+
+func TestOutboxTargets(t *testing.T) {
+	o, err := Open("", 10)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	o.Record(notification("everyone"), "notification", time.Hour, true, nil)
+	o.Record(notification("pixel only"), "notification", time.Hour, true, []string{"pixel"})
+
+	if got := len(o.Since(0, "pixel")); got != 2 {
+		t.Errorf("Expected pixel to see 2 envelopes, got %d", got)
+	}
+	got := o.Since(0, "tablet")
+	if len(got) != 1 || got[0].GetNotification().Title != "everyone" {
+		t.Errorf("Expected tablet to see only the broadcast, got %v", got)
+	}
+
+	missed := notification("missed")
+	o.Record(missed, "notification", time.Hour, false, []string{"pixel"})
+	if got := o.Undelivered("tablet"); len(got) != 0 {
+		t.Errorf("Expected nothing undelivered for tablet, got %v", got)
+	}
+	if got := o.Undelivered("pixel"); len(got) != 1 || got[0].Seq != missed.Seq {
+		t.Errorf("Expected pixel's missed envelope to be undelivered, got %v", got)
+	}
+}
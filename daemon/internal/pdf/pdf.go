@@ -0,0 +1,80 @@
+// Package pdf reads what the daemon needs to know about PDF files without
+// rendering them.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// maxStreamBytes bounds how much of one object stream is inflated.
+const maxStreamBytes = 16 << 20
+
+var ErrNoPages = errors.New("no page tree found")
+
+var (
+	// A page tree node without nested dictionaries, which is how writers
+	// emit them
+	pagesRe = regexp.MustCompile(`<<[^<>]*?/Type\s*/Pages\b[^<>]*?>>`)
+	countRe = regexp.MustCompile(`/Count\s+(\d+)`)
+	// A page leaf; "/Pages" does not match because of the \b
+	pageRe = regexp.MustCompile(`/Type\s*/Page\b`)
+	// Compressed object streams, where PDF 1.5 writers put the page tree
+	objStmRe = regexp.MustCompile(`<<[^<>]*?/Type\s*/ObjStm\b[^<>]*?>>\s*stream\r?\n`)
+)
+
+// PageCount returns the number of pages in the PDF at path.
+func PageCount(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return Pages(data)
+}
+
+// Pages returns the number of pages in a PDF document: the largest /Count
+// of its page tree nodes, which the root holds, or failing that the number
+// of page objects.
+func Pages(data []byte) (int, error) {
+	parts := [][]byte{data}
+	for _, loc := range objStmRe.FindAllIndex(data, -1) {
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 || !bytes.Contains(data[loc[0]:loc[1]], []byte("/FlateDecode")) {
+			continue
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+end]))
+		if err != nil {
+			continue
+		}
+		// A truncated stream still yields what was inflated before the error
+		inflated, _ := io.ReadAll(io.LimitReader(zr, maxStreamBytes))
+		parts = append(parts, inflated)
+	}
+
+	count := 0
+	for _, p := range parts {
+		for _, dict := range pagesRe.FindAll(p, -1) {
+			if m := countRe.FindSubmatch(dict); m != nil {
+				if n, err := strconv.Atoi(string(m[1])); err == nil {
+					count = max(count, n)
+				}
+			}
+		}
+	}
+	if count > 0 {
+		return count, nil
+	}
+
+	for _, p := range parts {
+		count += len(pageRe.FindAllIndex(p, -1))
+	}
+	if count == 0 {
+		return 0, ErrNoPages
+	}
+	return count, nil
+}
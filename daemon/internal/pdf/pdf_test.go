@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func plainPDF(pages int) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	fmt.Fprintf(&b, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&b, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>\nendobj\n", i+3)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return []byte(b.String())
+}
+
+func TestPages(t *testing.T) {
+	n, err := Pages(plainPDF(3))
+	if err != nil || n != 3 {
+		t.Errorf("Pages = %d, %v; want 3", n, err)
+	}
+}
+
+func TestPagesWithoutCount(t *testing.T) {
+	data := bytes.ReplaceAll(plainPDF(2), []byte("/Count 2"), nil)
+	n, err := Pages(data)
+	if err != nil || n != 2 {
+		t.Errorf("Pages = %d, %v; want 2 page objects", n, err)
+	}
+}
+
+func TestPagesInObjectStream(t *testing.T) {
+	var objs bytes.Buffer
+	zw := zlib.NewWriter(&objs)
+	zw.Write([]byte("2 0 3 60\n<< /Type /Pages /Kids [3 0 R] /Count 7 >>\n<< /Type /Page /Parent 2 0 R >>"))
+	zw.Close()
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	fmt.Fprintf(&b, "4 0 obj\n<< /Type /ObjStm /N 2 /First 9 /Filter /FlateDecode /Length %d >>\nstream\n", objs.Len())
+	b.Write(objs.Bytes())
+	b.WriteString("\nendstream\nendobj\n%%EOF\n")
+
+	n, err := Pages(b.Bytes())
+	if err != nil || n != 7 {
+		t.Errorf("Pages = %d, %v; want 7", n, err)
+	}
+}
+
+func TestPagesNotPDF(t *testing.T) {
+	if _, err := Pages([]byte("hello")); err != ErrNoPages {
+		t.Errorf("Pages(hello) error = %v, want ErrNoPages", err)
+	}
+}
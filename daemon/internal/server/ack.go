@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+)
+
+// DeviceAck reports how a single device handled a request.
+type DeviceAck struct {
+	Device string `json:"device"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ackWaiter collects the acks for one request ID.
+type ackWaiter struct {
+	mu     sync.Mutex
+	acks   []DeviceAck
+	notify chan struct{}
+}
+
+// expectAcks starts collecting acks for requestID. Call forgetAcks when done.
+func (s *Server) expectAcks(requestID string) *ackWaiter {
+	w := &ackWaiter{notify: make(chan struct{}, 1)}
+
+	s.acksMu.Lock()
+	s.acks[requestID] = w
+	s.acksMu.Unlock()
+
+	return w
+}
+
+func (s *Server) forgetAcks(requestID string) {
+	s.acksMu.Lock()
+	delete(s.acks, requestID)
+	s.acksMu.Unlock()
+}
+
+func (s *Server) handleAck(c *client, ack *pb.Ack) {
+	s.acksMu.Lock()
+	w, ok := s.acks[ack.RequestId]
+	s.acksMu.Unlock()
+	if !ok {
+		// Nobody is waiting (e.g. a replayed envelope); nothing to do
+		return
+	}
+
+	w.mu.Lock()
+	w.acks = append(w.acks, DeviceAck{
+		Device: c.name(),
+		Status: ack.Status.String(),
+		Error:  ack.Error,
+	})
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until every one of the expected devices has displayed the
+// request (or failed), the timeout passes, or ctx is cancelled, and returns
+// the acks collected so far.
+func (w *ackWaiter) wait(ctx context.Context, timeout time.Duration, expected int) []DeviceAck {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for w.settled() < expected {
+		select {
+		case <-w.notify:
+		case <-timer.C:
+			return w.snapshot()
+		case <-ctx.Done():
+			return w.snapshot()
+		}
+	}
+	return w.snapshot()
+}
+
+func (w *ackWaiter) snapshot() []DeviceAck {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]DeviceAck(nil), w.acks...)
+}
+
+// settled counts the devices that have reached a final state.
+func (w *ackWaiter) settled() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	final := make(map[string]bool)
+	for _, a := range w.acks {
+		if a.Status != pb.Ack_RECEIVED.String() {
+			final[a.Device] = true
+		}
+	}
+	return len(final)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+
+	pb "github.com/zelland/daemon/proto"
+)
+
+// handleActionRequest runs an allowed Zellij action for a client and replies
+// with its output.
+func (s *Server) handleActionRequest(c *client, requestID string, req *pb.ActionRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), zellijTimeout)
+	defer cancel()
+
+	resp := &pb.ActionResponse{}
+	defer func() {
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_ActionResponse{ActionResponse: resp},
+		})
+	}()
+
+	out, err := s.runAction(ctx, c, req)
+	resp.Output = out
+	if err != nil {
+		log.Printf("Zellij action %q from %s: %v", req.Action, c.name(), err)
+		resp.Error = err.Error()
+		resp.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			resp.ExitCode = int32(exitErr.ExitCode())
+		}
+	}
+}
+
+func (s *Server) runAction(ctx context.Context, c *client, req *pb.ActionRequest) (string, error) {
+	if err := requireAuth(c); err != nil {
+		return "", err
+	}
+	if !s.allowedActions[req.Action] {
+		return "", fmt.Errorf("action %q is not allowed; add it to zellij.allowed_actions", req.Action)
+	}
+
+	session := req.Session
+	if session == "" {
+		session = c.info().ZellijSession
+	}
+	if session == "" {
+		return "", errors.New("no session given and the client is not showing one")
+	}
+
+	if req.Action == "run" {
+		return s.zellijCLI.Run(ctx, session, req.Args, req.Command)
+	}
+	if len(req.Command) > 0 {
+		return "", errors.New("command is only valid with the run action")
+	}
+	return s.zellijCLI.Action(ctx, session, req.Action, req.Args)
+}
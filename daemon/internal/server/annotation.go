@@ -0,0 +1,464 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zelland/daemon/internal/anchor"
+	"github.com/zelland/daemon/internal/assets"
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/pdf"
+	"github.com/zelland/daemon/internal/textdiff"
+	pb "github.com/zelland/daemon/proto"
+)
+
+var (
+	errNoAnnotation = errors.New("no such annotation")
+	errNotAuthor    = errors.New("only its author may change it")
+)
+
+// handleAnnotation applies an annotation change from a client to the file's
+// stored annotations and sends the resulting thread to every client viewing the file.
+func (s *Server) handleAnnotation(c *client, action *pb.AnnotationAction) {
+	data := action.GetData()
+	if data == nil || data.Id == "" {
+		log.Printf("Ignoring annotation %s from %s without an ID", action.Type, c.name())
+		return
+	}
+
+	// Notes are attributed to the connection, never to what the client claims
+	author := c.author()
+	if data.User != "" && data.User != author {
+		log.Printf("Rejected annotation %s from %s: user %q is not %q", data.Id, c.name(), data.User, author)
+		return
+	}
+	data.User = author
+
+	// Clients only name files by the asset IDs they were sent, so they
+	// cannot annotate anything that was not shown to them
+	s.assetPathsMu.RLock()
+	filePath, ok := s.assetPaths[action.FilePath]
+	s.assetPathsMu.RUnlock()
+	if !ok {
+		log.Printf("Rejected annotation %s from %s: unknown asset %q", data.Id, c.name(), action.FilePath)
+		return
+	}
+
+	if _, err := s.annotate(filePath, action.Type, data); err != nil {
+		log.Printf("Failed to %s annotation %s from %s: %v", action.Type, data.Id, c.name(), err)
+	}
+}
+
+// annotate performs an action by data.User on the annotations of file and
+// sends the resulting thread to every client, the sender included, once
+// for each asset ID the file is open as. It returns how many clients it
+// was sent to.
+func (s *Server) annotate(file string, action pb.AnnotationAction_ActionType, data *pb.AnnotationData) (int, error) {
+	if action == pb.AnnotationAction_CREATE || action == pb.AnnotationAction_UPDATE {
+		if err := validateAnchor(data.Anchor, file); err != nil {
+			return 0, err
+		}
+	}
+
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	thread, err := s.saveAnnotation(file, action, data)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Saved annotation %s (%s) by %q on %s", data.Id, action, data.User, file)
+
+	s.sentAnnotation(file, data.Id, thread)
+
+	// Everyone gets the whole thread as stored. DELETE is only sent once the
+	// thread is gone; removing a reply updates it.
+	typ := action
+	out := &pb.AnnotationData{Id: data.Id}
+	if thread != nil {
+		out = threadProto(thread)
+		if typ == pb.AnnotationAction_DELETE {
+			typ = pb.AnnotationAction_UPDATE
+		}
+	}
+	return s.sendAnnotation(file, typ, out), nil
+}
+
+// sendAnnotation sends a thread to every client, once for each asset ID
+// file is open as, and returns how many clients it reached.
+func (s *Server) sendAnnotation(file string, typ pb.AnnotationAction_ActionType, data *pb.AnnotationData) int {
+	reached := make(map[*client]bool)
+	for _, id := range s.assetIDsFor(file) {
+		sent := s.deliver(&pb.Envelope{
+			Payload: &pb.Envelope_Annotation{Annotation: &pb.AnnotationAction{
+				Type:     typ,
+				FilePath: id,
+				Data:     data,
+			}},
+		})
+		for _, c := range sent {
+			reached[c] = true
+		}
+	}
+	return len(reached)
+}
+
+// IPC Request Body
+type AnnotateRequest struct {
+	FilePath string `json:"file_path"`
+	// Generated by the daemon if empty; must not be taken yet
+	ID         string `json:"id"`
+	User       string `json:"user"`
+	TargetText string `json:"target_text"`
+	// Of the paragraph holding TargetText; computed by the daemon if empty
+	ContextHash string `json:"context_hash"`
+	Body        string `json:"body"`
+}
+
+// IPC Response Body
+type AnnotateResponse struct {
+	ID string `json:"id"`
+	// Clients viewing the file, which show the annotation right away
+	SentTo int `json:"sent_to"`
+}
+
+// handleTriggerAnnotate creates a text annotation from the CLI.
+func (s *Server) handleTriggerAnnotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnnotateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !filepath.IsAbs(req.FilePath) || req.TargetText == "" || req.Body == "" {
+		http.Error(w, "file_path must be absolute, and target_text and body are required", http.StatusBadRequest)
+		return
+	}
+	content, err := os.ReadFile(req.FilePath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to access file: %v", err), http.StatusBadRequest)
+		return
+	}
+	paragraph, err := anchor.Context(string(content), req.TargetText)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("target_text: %v", err), http.StatusBadRequest)
+		return
+	}
+	if hash := anchor.ContextHash(paragraph); req.ContextHash == "" {
+		req.ContextHash = hash
+	} else if req.ContextHash != hash {
+		http.Error(w, fmt.Sprintf("context_hash does not match the paragraph of target_text, which has %s", hash), http.StatusBadRequest)
+		return
+	}
+
+	if req.ID == "" {
+		req.ID = assets.NewID()
+	} else {
+		anns, err := s.annotations.List(req.FilePath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to read annotations: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if kdl.FindThread(anns, req.ID) >= 0 {
+			http.Error(w, fmt.Sprintf("annotation %s already exists", req.ID), http.StatusConflict)
+			return
+		}
+	}
+
+	sent, err := s.annotate(req.FilePath, pb.AnnotationAction_CREATE, &pb.AnnotationData{
+		Id:          req.ID,
+		User:        req.User,
+		ContextHash: req.ContextHash,
+		TargetText:  req.TargetText,
+		Body:        req.Body,
+	})
+	if errors.Is(err, errNotAuthor) {
+		// Created by someone else since the check above
+		http.Error(w, fmt.Sprintf("annotation %s already exists", req.ID), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save annotation: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AnnotateResponse{ID: req.ID, SentTo: sent})
+}
+
+// saveAnnotation performs an action on the stored annotations of file. It
+// returns the changed thread, or nil if the whole thread was deleted.
+func (s *Server) saveAnnotation(file string, action pb.AnnotationAction_ActionType, data *pb.AnnotationData) (*kdl.Annotation, error) {
+	anns, err := s.annotations.List(file)
+	if err != nil {
+		return nil, err
+	}
+	_, thread, err := applyAnnotation(anns, action, data)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, s.annotations.Delete(file, data.Id)
+	}
+	return thread, s.annotations.Put(file, *thread)
+}
+
+// applyAnnotation performs one action on the annotations of a file, by
+// data.User. It returns the changed thread, or nil if the whole thread was
+// deleted.
+func applyAnnotation(anns []kdl.Annotation, action pb.AnnotationAction_ActionType, data *pb.AnnotationData) ([]kdl.Annotation, *kdl.Annotation, error) {
+	now := time.Now().Unix()
+	timestamp := data.Timestamp
+	if timestamp == 0 {
+		timestamp = now
+	}
+
+	i := kdl.FindThread(anns, data.Id)
+	if i < 0 && action != pb.AnnotationAction_CREATE && action != pb.AnnotationAction_REPLY {
+		return nil, nil, fmt.Errorf("%s: %w", data.Id, errNoAnnotation)
+	}
+
+	switch action {
+	case pb.AnnotationAction_CREATE:
+		ann := kdl.Annotation{
+			ID:          data.Id,
+			User:        data.User,
+			Timestamp:   timestamp,
+			ContextHash: data.ContextHash,
+			TargetText:  data.TargetText,
+			Body:        data.Body,
+			Anchor:      anchorFromProto(data.Anchor),
+		}
+		if i < 0 {
+			anns = append(anns, ann)
+			return anns, &anns[len(anns)-1], nil
+		}
+		if anns[i].ID != data.Id {
+			return nil, nil, fmt.Errorf("%s is already a reply", data.Id)
+		}
+		if !mayChange(anns[i].User, data.User) {
+			return nil, nil, fmt.Errorf("%s: %w", data.Id, errNotAuthor)
+		}
+		// A repeated create replaces the note but keeps the discussion
+		ann.Status, ann.ResolvedBy, ann.ResolvedAt = anns[i].Status, anns[i].ResolvedBy, anns[i].ResolvedAt
+		ann.Replies = anns[i].Replies
+		anns[i] = ann
+
+	case pb.AnnotationAction_UPDATE:
+		if !mayChange(author(anns[i], data.Id), data.User) {
+			return nil, nil, fmt.Errorf("%s: %w", data.Id, errNotAuthor)
+		}
+		if anns[i].ID == data.Id {
+			anns[i].User = data.User
+			anns[i].Body = data.Body
+			if data.TargetText != "" {
+				anns[i].TargetText = data.TargetText
+				anns[i].ContextHash = data.ContextHash
+			}
+			if data.Anchor != nil {
+				anns[i].Anchor = anchorFromProto(data.Anchor)
+			}
+		} else {
+			r := &anns[i].Replies[anns[i].FindReply(data.Id)]
+			r.User = data.User
+			r.Body = data.Body
+		}
+
+	case pb.AnnotationAction_DELETE:
+		if !mayChange(author(anns[i], data.Id), data.User) {
+			return nil, nil, fmt.Errorf("%s: %w", data.Id, errNotAuthor)
+		}
+		if anns[i].ID == data.Id {
+			return append(anns[:i], anns[i+1:]...), nil, nil
+		}
+		anns[i].RemoveReply(data.Id)
+
+	case pb.AnnotationAction_REPLY:
+		if i >= 0 {
+			// Already stored; a resent reply changes nothing
+			return anns, &anns[i], nil
+		}
+		if i = kdl.FindThread(anns, data.ParentId); i < 0 {
+			return nil, nil, fmt.Errorf("parent %s: %w", data.ParentId, errNoAnnotation)
+		}
+		parent := data.ParentId
+		if parent == anns[i].ID {
+			parent = ""
+		}
+		anns[i].Replies = append(anns[i].Replies, kdl.Reply{
+			ID:        data.Id,
+			User:      data.User,
+			Timestamp: timestamp,
+			Parent:    parent,
+			Body:      data.Body,
+		})
+
+	// Whether a thread is settled is shared by everyone in it, not owned by
+	// whoever started it, so anyone may resolve or reopen; resolved_by
+	// records who did.
+	case pb.AnnotationAction_RESOLVE:
+		anns[i].Resolve(data.User, now)
+
+	case pb.AnnotationAction_REOPEN:
+		anns[i].Reopen()
+
+	default:
+		return nil, nil, fmt.Errorf("unknown action %v", action)
+	}
+	return anns, &anns[i], nil
+}
+
+// mayChange reports whether user may update, delete or recreate a note or
+// reply written by author. Notes and replies without an author, saved before
+// tokens were configured or from the CLI without a user, may be changed by
+// anyone, who then becomes their author.
+func mayChange(author, user string) bool {
+	return author == "" || author == user
+}
+
+// author returns who wrote the note or reply id in thread a.
+func author(a kdl.Annotation, id string) string {
+	if a.ID == id {
+		return a.User
+	}
+	return a.Replies[a.FindReply(id)].User
+}
+
+// threadProto converts an annotation and its replies for clients.
+func threadProto(a *kdl.Annotation) *pb.AnnotationData {
+	out := &pb.AnnotationData{
+		Id:          a.ID,
+		TargetText:  a.TargetText,
+		ContextHash: a.ContextHash,
+		Body:        a.Body,
+		Timestamp:   a.Timestamp,
+		User:        a.User,
+		ResolvedBy:  a.ResolvedBy,
+		ResolvedAt:  a.ResolvedAt,
+		Anchor:      anchorProto(a.Anchor),
+	}
+	if a.Resolved() {
+		out.Status = pb.AnnotationData_RESOLVED
+	}
+	for _, r := range a.Replies {
+		parent := r.Parent
+		if parent == "" {
+			parent = a.ID
+		}
+		out.Replies = append(out.Replies, &pb.AnnotationData{
+			Id:        r.ID,
+			Body:      r.Body,
+			Timestamp: r.Timestamp,
+			User:      r.User,
+			ParentId:  parent,
+		})
+	}
+	return out
+}
+
+// validateAnchor checks that an anchor suits the file it is on and lies
+// within it.
+func validateAnchor(a *pb.Anchor, filePath string) error {
+	ftype := viewType(filePath)
+	switch a.GetKind() {
+	case pb.Anchor_TEXT:
+		return nil
+	case pb.Anchor_RECT:
+		if ftype != pb.OpenViewRequest_IMAGE {
+			return fmt.Errorf("rect anchors are for images, not %s", ftype)
+		}
+		return validateRect(a)
+	case pb.Anchor_PAGE:
+		if ftype != pb.OpenViewRequest_PDF {
+			return fmt.Errorf("page anchors are for PDFs, not %s", ftype)
+		}
+		pages, err := pdf.PageCount(filePath)
+		if err != nil {
+			return fmt.Errorf("cannot count pages: %w", err)
+		}
+		if a.Page < 1 || int(a.Page) > pages {
+			return fmt.Errorf("page %d is not in 1-%d", a.Page, pages)
+		}
+		if a.Quote != "" {
+			return nil
+		}
+		return validateRect(a)
+	case pb.Anchor_LINES:
+		switch ftype {
+		case pb.OpenViewRequest_CODE, pb.OpenViewRequest_MARKDOWN, pb.OpenViewRequest_TEXT:
+		default:
+			return fmt.Errorf("line anchors are for text files, not %s", ftype)
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		lines := len(textdiff.Lines(string(content)))
+		if a.StartLine < 1 || a.EndLine < a.StartLine || int(a.EndLine) > lines {
+			return fmt.Errorf("lines %d-%d are not in 1-%d", a.StartLine, a.EndLine, lines)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown anchor kind %v", a.GetKind())
+}
+
+// validateRect checks that a rectangle is not empty and lies within the
+// bounds of its image or page.
+func validateRect(a *pb.Anchor) error {
+	// Written so that NaNs fail
+	const slack = 1e-9
+	if !(a.X >= 0 && a.Y >= 0 && a.W > 0 && a.H > 0 && a.X+a.W <= 1+slack && a.Y+a.H <= 1+slack) {
+		return fmt.Errorf("rectangle at %g,%g of %gx%g is not within the bounds 0-1", a.X, a.Y, a.W, a.H)
+	}
+	return nil
+}
+
+func anchorFromProto(a *pb.Anchor) kdl.Anchor {
+	switch a.GetKind() {
+	case pb.Anchor_RECT:
+		return kdl.Anchor{Kind: kdl.AnchorRect, X: a.X, Y: a.Y, W: a.W, H: a.H}
+	case pb.Anchor_PAGE:
+		if a.Quote != "" {
+			return kdl.Anchor{Kind: kdl.AnchorPage, Page: int(a.Page), Quote: a.Quote}
+		}
+		return kdl.Anchor{Kind: kdl.AnchorPage, Page: int(a.Page), X: a.X, Y: a.Y, W: a.W, H: a.H}
+	case pb.Anchor_LINES:
+		return kdl.Anchor{Kind: kdl.AnchorLines, StartLine: int(a.StartLine), EndLine: int(a.EndLine)}
+	}
+	return kdl.Anchor{}
+}
+
+// anchorProto converts a stored anchor for clients; text anchors have none.
+func anchorProto(a kdl.Anchor) *pb.Anchor {
+	out := &pb.Anchor{
+		X:         a.X,
+		Y:         a.Y,
+		W:         a.W,
+		H:         a.H,
+		Page:      uint32(a.Page),
+		Quote:     a.Quote,
+		StartLine: uint32(a.StartLine),
+		EndLine:   uint32(a.EndLine),
+	}
+	switch a.Kind {
+	case kdl.AnchorRect:
+		out.Kind = pb.Anchor_RECT
+	case kdl.AnchorPage:
+		out.Kind = pb.Anchor_PAGE
+	case kdl.AnchorLines:
+		out.Kind = pb.Anchor_LINES
+	default:
+		return nil
+	}
+	return out
+}
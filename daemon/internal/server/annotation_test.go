@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zelland/daemon/internal/kdl"
+	pb "github.com/zelland/daemon/proto"
+)
+
+func storedThread() []kdl.Annotation {
+	return []kdl.Annotation{{
+		ID:         "a1",
+		User:       "alice",
+		Timestamp:  1700000000,
+		TargetText: "Selected text",
+		Body:       "Why?",
+		Replies: []kdl.Reply{
+			{ID: "r1", User: "bob", Timestamp: 1700000300, Body: "Because"},
+		},
+	}}
+}
+
+func TestRepeatedCreateKeepsAuthor(t *testing.T) {
+	anns, got, err := applyAnnotation(storedThread(), pb.AnnotationAction_CREATE, &pb.AnnotationData{
+		Id: "a1", User: "alice", TargetText: "Selected text", Body: "Why not?",
+	})
+	if err != nil {
+		t.Fatalf("Repeated create by the author failed: %v", err)
+	}
+	if got.User != "alice" || got.Body != "Why not?" || len(got.Replies) != 1 || len(anns) != 1 {
+		t.Errorf("Expected alice's note replaced with its reply kept, got %+v", got)
+	}
+
+	_, _, err = applyAnnotation(storedThread(), pb.AnnotationAction_CREATE, &pb.AnnotationData{
+		Id: "a1", User: "mallory", TargetText: "Selected text", Body: "Mine now",
+	})
+	if !errors.Is(err, errNotAuthor) {
+		t.Errorf("Expected a create over someone else's note to be refused, got %v", err)
+	}
+}
+
+func TestOnlyAuthorsChangeNotes(t *testing.T) {
+	tests := []struct {
+		name   string
+		action pb.AnnotationAction_ActionType
+		id     string
+		user   string
+		ok     bool
+	}{
+		{"update own note", pb.AnnotationAction_UPDATE, "a1", "alice", true},
+		{"update other's note", pb.AnnotationAction_UPDATE, "a1", "bob", false},
+		{"update own reply", pb.AnnotationAction_UPDATE, "r1", "bob", true},
+		{"update other's reply", pb.AnnotationAction_UPDATE, "r1", "alice", false},
+		{"delete own note", pb.AnnotationAction_DELETE, "a1", "alice", true},
+		{"delete other's note", pb.AnnotationAction_DELETE, "a1", "bob", false},
+		{"delete own reply", pb.AnnotationAction_DELETE, "r1", "bob", true},
+		{"delete other's reply", pb.AnnotationAction_DELETE, "r1", "alice", false},
+		{"resolve other's note", pb.AnnotationAction_RESOLVE, "a1", "bob", true},
+	}
+	for _, tt := range tests {
+		_, _, err := applyAnnotation(storedThread(), tt.action, &pb.AnnotationData{Id: tt.id, User: tt.user, Body: "Edited"})
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%s: expected success, got %v", tt.name, err)
+		case !tt.ok && !errors.Is(err, errNotAuthor):
+			t.Errorf("%s: expected errNotAuthor, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestAnyoneClaimsAnonymousNotes(t *testing.T) {
+	anonymous := func() []kdl.Annotation {
+		anns := storedThread()
+		anns[0].User = ""
+		anns[0].Replies[0].User = ""
+		return anns
+	}
+
+	_, got, err := applyAnnotation(anonymous(), pb.AnnotationAction_UPDATE, &pb.AnnotationData{Id: "a1", User: "bob", Body: "Edited"})
+	if err != nil {
+		t.Fatalf("Update of an anonymous note failed: %v", err)
+	}
+	if got.User != "bob" || got.Body != "Edited" {
+		t.Errorf("Expected the note edited and claimed by bob, got %+v", got)
+	}
+	if _, _, err := applyAnnotation([]kdl.Annotation{*got}, pb.AnnotationAction_UPDATE, &pb.AnnotationData{Id: "a1", User: "carol", Body: "Mine"}); !errors.Is(err, errNotAuthor) {
+		t.Errorf("Expected a claimed note to be refused to others, got %v", err)
+	}
+
+	_, got, err = applyAnnotation(anonymous(), pb.AnnotationAction_UPDATE, &pb.AnnotationData{Id: "r1", User: "alice", Body: "Edited"})
+	if err != nil {
+		t.Fatalf("Update of an anonymous reply failed: %v", err)
+	}
+	if r := got.Replies[0]; r.User != "alice" || r.Body != "Edited" {
+		t.Errorf("Expected the reply edited and claimed by alice, got %+v", r)
+	}
+
+	_, got, err = applyAnnotation(anonymous(), pb.AnnotationAction_CREATE, &pb.AnnotationData{
+		Id: "a1", User: "bob", TargetText: "Selected text", Body: "Again",
+	})
+	if err != nil {
+		t.Fatalf("Create over an anonymous note failed: %v", err)
+	}
+	if got.User != "bob" || len(got.Replies) != 1 {
+		t.Errorf("Expected the note replaced and claimed by bob with its reply kept, got %+v", got)
+	}
+
+	if anns, _, err := applyAnnotation(anonymous(), pb.AnnotationAction_DELETE, &pb.AnnotationData{Id: "a1", User: "bob"}); err != nil || len(anns) != 0 {
+		t.Errorf("Expected an anonymous note deleted, got %v, %+v", err, anns)
+	}
+}
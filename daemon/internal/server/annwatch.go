@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/store"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// openCheckInterval is how often watches check that their file is still
+// open as an asset.
+const openCheckInterval = time.Minute
+
+// annotationWatch follows the stored annotations of an open file,
+// so changes made outside the daemon, like hand edits of a sidecar or a git
+// pull, reach its viewers.
+type annotationWatch struct {
+	cancel context.CancelFunc
+	// Threads as viewers last got them, by ID
+	sent map[string]kdl.Annotation
+}
+
+// watchAnnotations starts watching the annotations of file, unless they
+// are watched already. The watch ends once no asset of the file is left.
+func (s *Server) watchAnnotations(file string) {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	if _, ok := s.watches[file]; ok {
+		return
+	}
+	w := &annotationWatch{sent: make(map[string]kdl.Annotation)}
+	anns, err := s.annotations.List(file)
+	if err != nil {
+		log.Printf("Failed to read the annotations of %s: %v", file, err)
+	}
+	for _, a := range anns {
+		w.sent[a.ID] = a
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	s.watches[file] = w
+	events := s.annotations.Watch(ctx, file)
+
+	go func() {
+		ticker := time.NewTicker(openCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				s.annotationsChanged(file, e)
+			case <-ticker.C:
+				if s.unwatchClosed(file, w) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// unwatchClosed ends watch w of file if the file is no longer open, and
+// reports whether it did. Views register their asset before they call
+// watchAnnotations, which takes the same lock, so a file opened again
+// meanwhile is either seen as open here or gets a new watch.
+func (s *Server) unwatchClosed(file string, w *annotationWatch) bool {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	if s.isOpen(file) {
+		return false
+	}
+	if s.watches[file] == w {
+		delete(s.watches, file)
+	}
+	w.cancel()
+	return true
+}
+
+// isOpen reports whether file is still registered as an asset.
+func (s *Server) isOpen(file string) bool {
+	for _, id := range s.assetIDsFor(file) {
+		if _, ok := s.assetManager.Lookup(id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationsChanged sends viewers a change seen by a watch, unless they
+// already got it from the daemon itself.
+func (s *Server) annotationsChanged(file string, e store.Event) {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	w, ok := s.watches[file]
+	if !ok {
+		return
+	}
+	a := e.Annotation
+	prev, known := w.sent[a.ID]
+
+	switch e.Type {
+	case store.Failed:
+		// Viewers keep what they have; the next good read brings them up to date
+		log.Printf("Failed to read the annotations of %s: %v", file, e.Err)
+		for _, id := range s.assetIDsFor(file) {
+			s.deliver(&pb.Envelope{
+				Payload: &pb.Envelope_AnnotationWarning{AnnotationWarning: &pb.AnnotationWarning{
+					FilePath: id,
+					Message:  e.Err.Error(),
+				}},
+			})
+		}
+	case store.Deleted:
+		if !known {
+			return
+		}
+		delete(w.sent, a.ID)
+		log.Printf("Annotation %s was removed from %s outside the daemon", a.ID, file)
+		s.sendAnnotation(file, pb.AnnotationAction_DELETE, &pb.AnnotationData{Id: a.ID})
+	default:
+		if known && store.Equal(prev, a) {
+			return
+		}
+		w.sent[a.ID] = a
+		typ := pb.AnnotationAction_UPDATE
+		if !known {
+			typ = pb.AnnotationAction_CREATE
+		}
+		log.Printf("Annotation %s on %s was %s outside the daemon", a.ID, file, e.Type)
+		s.sendAnnotation(file, typ, threadProto(&a))
+	}
+}
+
+// sentAnnotation records a thread the daemon itself sent to viewers of
+// file, so its watch does not send it again. A nil thread was deleted.
+func (s *Server) sentAnnotation(file, id string, thread *kdl.Annotation) {
+	w, ok := s.watches[file]
+	if !ok {
+		return
+	}
+	if thread == nil {
+		delete(w.sent, id)
+		return
+	}
+	w.sent[thread.ID] = *thread
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// errUnauthenticated is returned for RPCs that control the host when the
+// connection did not present a token.
+var errUnauthenticated = errors.New("this request requires an authenticated connection; configure tokens in the daemon config")
+
+// authenticate checks the request's pre-shared key against the configured
+// tokens and returns the matching token name. When no tokens are configured
+// every request is accepted anonymously (name is empty).
+func (s *Server) authenticate(r *http.Request) (name string, ok bool) {
+	if len(s.tokens) == 0 {
+		return "", true
+	}
+
+	key := r.Header.Get("X-Zelland-PSK")
+	if key == "" {
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+	if key == "" {
+		return "", false
+	}
+
+	for n, secret := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(secret)) == 1 {
+			return n, true
+		}
+	}
+	return "", false
+}
+
+// requireAuth returns an error unless c authenticated with a token.
+func requireAuth(c *client) error {
+	if c.token == "" {
+		return errUnauthenticated
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zelland/daemon/internal/browse"
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	defaultBrowseLimit = 100
+	maxBrowseLimit     = 1000
+)
+
+func (s *Server) handleBrowseRequest(c *client, requestID string, req *pb.BrowseRequest) {
+	var resp *pb.BrowseResponse
+	defer func() {
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_BrowseResponse{BrowseResponse: resp},
+		})
+	}()
+
+	if err := requireAuth(c); err != nil {
+		resp = &pb.BrowseResponse{Error: err.Error()}
+		return
+	}
+	resp = s.browse(req)
+	if resp.Error != "" {
+		log.Printf("Browse %s %q from %s: %s", req.Action, req.Path, c.name(), resp.Error)
+	}
+}
+
+// handleBrowse serves GET /api/v1/browse with the fields of a BrowseRequest
+// as query parameters, and replies with a BrowseResponse as JSON. Like
+// uploads it requires a token.
+func (s *Server) handleBrowse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if name, ok := s.authenticate(r); !ok || name == "" {
+		http.Error(w, errUnauthenticated.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	action, ok := pb.BrowseRequest_Action_value[strings.ToUpper(q.Get("action"))]
+	if !ok && q.Get("action") != "" {
+		http.Error(w, fmt.Sprintf("unknown action %q", q.Get("action")), http.StatusBadRequest)
+		return
+	}
+	offset, _ := strconv.ParseUint(q.Get("offset"), 10, 32)
+	limit, _ := strconv.ParseUint(q.Get("limit"), 10, 32)
+
+	resp := s.browse(&pb.BrowseRequest{
+		Action: pb.BrowseRequest_Action(action),
+		Root:   q.Get("root"),
+		Path:   q.Get("path"),
+		Query:  q.Get("query"),
+		Offset: uint32(offset),
+		Limit:  uint32(limit),
+	})
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// browse carries out a BrowseRequest. Errors are reported in the response.
+func (s *Server) browse(req *pb.BrowseRequest) *pb.BrowseResponse {
+	resp := &pb.BrowseResponse{
+		Roots: s.browser.Roots(),
+		Root:  req.Root,
+		Path:  req.Path,
+	}
+	if req.Root == "" && req.Action == pb.BrowseRequest_LIST {
+		if len(resp.Roots) == 0 {
+			resp.Error = browse.ErrNoRoots.Error()
+		}
+		return resp
+	}
+
+	var entries []browse.Entry
+	var err error
+	switch req.Action {
+	case pb.BrowseRequest_LIST:
+		entries, err = s.browser.List(req.Root, req.Path)
+	case pb.BrowseRequest_SEARCH:
+		if req.Query == "" {
+			err = fmt.Errorf("query is required")
+			break
+		}
+		entries, err = s.browser.Search(req.Root, req.Path, req.Query)
+	case pb.BrowseRequest_OPEN:
+		resp.View, err = s.openBrowseEntry(req.Root, req.Path)
+	default:
+		err = fmt.Errorf("unknown action %v", req.Action)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp.Total = uint32(len(entries))
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultBrowseLimit
+	}
+	limit = min(limit, maxBrowseLimit)
+	start := min(int(req.Offset), len(entries))
+	end := min(start+limit, len(entries))
+	for _, e := range entries[start:end] {
+		pe := &pb.BrowseEntry{
+			Name:       e.Name,
+			Path:       e.Path,
+			IsDir:      e.IsDir,
+			Size:       e.Size,
+			ModifiedAt: e.ModTime.Unix(),
+		}
+		if !e.IsDir {
+			// Only the page is sniffed, so this stays cheap for big folders
+			if path, err := s.browser.Resolve(req.Root, e.Path); err == nil {
+				pe.FileType = viewType(path)
+			}
+		}
+		resp.Entries = append(resp.Entries, pe)
+	}
+	return resp
+}
+
+// openBrowseEntry registers a file under a browse root as an asset, as
+// `zelland show` would.
+func (s *Server) openBrowseEntry(root, rel string) (*pb.OpenViewRequest, error) {
+	path, err := s.browser.Resolve(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file", rel)
+	}
+
+	ftype := viewType(path)
+	if ftype == pb.OpenViewRequest_UNKNOWN {
+		return nil, fmt.Errorf("cannot display %s", fi.Name())
+	}
+	return s.registerView(path, ftype, fi.Name())
+}
+
+// viewType detects how a file would be shown: by extension like the CLI,
+// then by sniffing the content for files the extension says nothing about.
+func viewType(path string) pb.OpenViewRequest_FileType {
+	if ftype := fileTypeFor(path); ftype != pb.OpenViewRequest_UNKNOWN {
+		return ftype
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return pb.OpenViewRequest_UNKNOWN
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	switch ct := http.DetectContentType(head[:n]); {
+	case n == 0:
+		return pb.OpenViewRequest_UNKNOWN
+	case strings.HasPrefix(ct, "text/"):
+		return pb.OpenViewRequest_TEXT
+	case strings.HasPrefix(ct, "image/"):
+		return pb.OpenViewRequest_IMAGE
+	case ct == "application/pdf":
+		return pb.OpenViewRequest_PDF
+	}
+	return pb.OpenViewRequest_UNKNOWN
+}
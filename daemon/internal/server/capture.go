@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zelland/daemon/internal/ansi"
+	"github.com/zelland/daemon/internal/assets"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// captureRetention is how long captured scrollback pages are kept on disk.
+const captureRetention = 7 * 24 * time.Hour
+
+// CaptureRequest is the body of /api/v1/trigger/capture.
+type CaptureRequest struct {
+	// Zellij session to capture; defaults to the one the CLI runs in
+	Session string `json:"session,omitempty"`
+	// Only the visible screen instead of the full scrollback
+	ScreenOnly bool   `json:"screen_only,omitempty"`
+	Title      string `json:"title"`
+	WaitMs     int64  `json:"wait_ms,omitempty"`
+	To         string `json:"to,omitempty"`
+	Origin
+}
+
+func (s *Server) handleTriggerCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	session := req.Session
+	if session == "" {
+		session = req.Origin.ZellijSession
+	}
+	if session == "" {
+		http.Error(w, "Not running inside Zellij; name a session to capture", http.StatusBadRequest)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	view, err := s.captureView(r.Context(), session, !req.ScreenOnly, req.Title)
+	if err != nil {
+		log.Printf("Failed to capture %s: %v", session, err)
+		http.Error(w, fmt.Sprintf("Failed to capture: %v", err), http.StatusInternalServerError)
+		return
+	}
+	view.Origin = req.Origin.proto()
+
+	resp := s.sendView(r.Context(), view, targets, req.WaitMs)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleCaptureRequest captures a pane for the requesting client and replies
+// with the view to open.
+func (s *Server) handleCaptureRequest(c *client, requestID string, req *pb.CaptureRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), zellijTimeout)
+	defer cancel()
+
+	resp := &pb.CaptureResponse{}
+	defer func() {
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_CaptureResponse{CaptureResponse: resp},
+		})
+	}()
+
+	if err := requireAuth(c); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	session := req.Session
+	if session == "" {
+		session = c.info().ZellijSession
+	}
+	if session == "" {
+		resp.Error = "no session given and the client is not showing one"
+		return
+	}
+
+	view, err := s.captureView(ctx, session, !req.ScreenOnly, "")
+	if err != nil {
+		log.Printf("Failed to capture %s for %s: %v", session, c.name(), err)
+		resp.Error = err.Error()
+		return
+	}
+	resp.View = view
+}
+
+// captureView dumps a session's focused pane, converts the ANSI colours to
+// HTML and registers the page as a TEXT asset.
+func (s *Server) captureView(ctx context.Context, session string, full bool, title string) (*pb.OpenViewRequest, error) {
+	if err := os.MkdirAll(s.captureDir, 0700); err != nil {
+		return nil, err
+	}
+	pruneCaptures(s.captureDir)
+
+	id := assets.NewID()
+	raw := filepath.Join(s.captureDir, id+".txt")
+	defer os.Remove(raw)
+	if err := s.zellijCLI.DumpScreen(ctx, session, raw, full); err != nil {
+		return nil, err
+	}
+	readCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	data, err := readWhenWritten(readCtx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if title == "" {
+		title = session + " scrollback"
+	}
+	page := filepath.Join(s.captureDir, id+".html")
+	if err := os.WriteFile(page, []byte(ansi.Document(title, string(data))), 0600); err != nil {
+		return nil, err
+	}
+	return s.registerView(page, pb.OpenViewRequest_TEXT, title)
+}
+
+// readWhenWritten reads path once the Zellij server has finished writing it.
+// The dump is written by the server, so it may land after the CLI returns,
+// and the file may exist empty before anything is written to it. It counts
+// as written once it has content and its size stayed the same for a poll.
+func readWhenWritten(ctx context.Context, path string) ([]byte, error) {
+	var lastSize int64 = -1
+	for {
+		if info, err := os.Stat(path); err == nil {
+			if info.Size() > 0 && info.Size() == lastSize {
+				return os.ReadFile(path)
+			}
+			lastSize = info.Size()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("zellij did not write the capture: %w", ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// pruneCaptures removes captures older than captureRetention.
+func pruneCaptures(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err == nil && time.Since(info.ModTime()) > captureRetention {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
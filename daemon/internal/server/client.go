@@ -0,0 +1,180 @@
+package server
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// client is a connected WebSocket peer. gorilla/websocket allows only one
+// concurrent writer, so all sends go through writeMu.
+type client struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	// Name of the token the client authenticated with, if any
+	token string
+	// Serializes deliveries of undelivered envelopes
+	sessionMu sync.Mutex
+
+	mu sync.Mutex
+	// Log stream ID -> line filter (nil matches everything)
+	logSubs map[string]*regexp.Regexp
+	// Outbox sequence numbers already replayed on this connection
+	replayed map[uint64]bool
+	// Set once the session has started (Hello, Resume or legacy timeout)
+	started bool
+	caps    capabilities
+	// Activity, used by the default target policy and `zelland devices`
+	connectedAt time.Time
+	lastActive  time.Time
+	focusedAt   time.Time
+	status      *pb.ClientStatus
+}
+
+func newClient(conn *websocket.Conn, token string) *client {
+	now := time.Now()
+	return &client{
+		conn:        conn,
+		token:       token,
+		logSubs:     make(map[string]*regexp.Regexp),
+		replayed:    make(map[uint64]bool),
+		caps:        legacyCapabilities(),
+		connectedAt: now,
+		lastActive:  now,
+		status:      &pb.ClientStatus{},
+	}
+}
+
+// id is the stable device ID from Hello, or the remote address for clients
+// that did not provide one.
+func (c *client) id() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.caps.deviceID != "" {
+		return c.caps.deviceID
+	}
+	return c.conn.RemoteAddr().String()
+}
+
+// matches reports whether the client is the device called target (by name
+// or ID, ignoring case).
+func (c *client) matches(target string) bool {
+	return strings.EqualFold(c.id(), target) || strings.EqualFold(c.name(), target)
+}
+
+// touch records that a message was received from the client.
+func (c *client) touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastActive = time.Now()
+}
+
+func (c *client) setStatus(st *pb.ClientStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = st
+	if st.Foreground {
+		c.focusedAt = time.Now()
+	}
+}
+
+// name identifies the client in logs and CLI output.
+func (c *client) name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.caps.deviceName != "" {
+		return c.caps.deviceName
+	}
+	return c.conn.RemoteAddr().String()
+}
+
+// author is the identity the client's annotations are attributed to: the
+// token it authenticated with, else the device name from Hello, else "".
+func (c *client) author() string {
+	if c.token != "" {
+		return c.token
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caps.deviceName
+}
+
+// startSession reports whether this call started the session; only the first
+// call returns true.
+func (c *client) startSession() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return false
+	}
+	c.started = true
+	return true
+}
+
+func (c *client) setCapabilities(caps capabilities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caps = caps
+}
+
+func (c *client) capabilities() capabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caps
+}
+
+// send writes env to the client and reports whether that succeeded.
+func (c *client) send(env *pb.Envelope) bool {
+	data, err := proto.Marshal(env)
+	if err != nil {
+		log.Printf("Marshal error: %v", err)
+		return false
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		log.Printf("Write error: %v", err)
+		return false
+	}
+	return true
+}
+
+func (c *client) subscribeLog(streamID string, filter *regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logSubs[streamID] = filter
+}
+
+func (c *client) unsubscribeLog(streamID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.logSubs, streamID)
+}
+
+// logFilter returns the filter for a stream and whether the client is
+// subscribed to it at all.
+func (c *client) logFilter(streamID string) (*regexp.Regexp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, ok := c.logSubs[streamID]
+	return f, ok
+}
+
+func (c *client) markReplayed(seq uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replayed[seq] = true
+}
+
+func (c *client) wasReplayed(seq uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.replayed[seq]
+}
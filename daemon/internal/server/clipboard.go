@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// CopyRequest is the body of /api/v1/trigger/copy.
+type CopyRequest struct {
+	Data []byte `json:"data"`
+	// Detected from the data when empty
+	MimeType string `json:"mime_type,omitempty"`
+	To       string `json:"to,omitempty"`
+	Origin
+}
+
+// CopyResponse is the reply to a CopyRequest.
+type CopyResponse struct {
+	RequestID string `json:"request_id"`
+	MimeType  string `json:"mime_type"`
+	// Number of connected devices the content was sent to. Clipboard
+	// content is never queued for devices that connect later.
+	SentTo int `json:"sent_to"`
+}
+
+// PasteRequest is the body of /api/v1/trigger/paste.
+type PasteRequest struct {
+	// Zero or anything over the configured limit means the limit
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// How long to wait for the user; zero waits until the CLI gives up
+	TimeoutMs int64  `json:"timeout_ms,omitempty"`
+	To        string `json:"to,omitempty"`
+	Origin
+}
+
+// PasteResponse is the reply to a PasteRequest.
+type PasteResponse struct {
+	MimeType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	Device   string `json:"device,omitempty"`
+	Denied   bool   `json:"denied,omitempty"`
+	TimedOut bool   `json:"timed_out,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (s *Server) handleTriggerCopy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The data is base64 in JSON, so allow for the expansion
+	r.Body = http.MaxBytesReader(w, r.Body, s.clipboardMax/3*4+64*1024)
+	var req CopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("content is over the %d byte clipboard limit", s.clipboardMax), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Data) == 0 {
+		http.Error(w, "nothing to copy", http.StatusBadRequest)
+		return
+	}
+	if int64(len(req.Data)) > s.clipboardMax {
+		http.Error(w, fmt.Sprintf("%d bytes is over the %d byte clipboard limit", len(req.Data), s.clipboardMax), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if req.MimeType == "" {
+		req.MimeType = http.DetectContentType(req.Data)
+	}
+	if !clipboardType(req.MimeType) {
+		http.Error(w, fmt.Sprintf("cannot copy %s; only text and images are supported", req.MimeType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	id := assets.NewID()
+	sent := s.deliverTo(&pb.Envelope{
+		RequestId: id,
+		Payload: &pb.Envelope_ClipboardSet{ClipboardSet: &pb.ClipboardSet{
+			Id:       id,
+			MimeType: req.MimeType,
+			Data:     req.Data,
+			Origin:   req.Origin.proto(),
+		}},
+	}, targets)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CopyResponse{
+		RequestID: id,
+		MimeType:  req.MimeType,
+		SentTo:    len(sent),
+	})
+}
+
+func (s *Server) handleTriggerPaste(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PasteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	maxBytes := s.clipboardMax
+	if req.MaxBytes > 0 && req.MaxBytes < maxBytes {
+		maxBytes = req.MaxBytes
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	cr := &pb.ClipboardRequest{
+		Id:       assets.NewID(),
+		MaxBytes: maxBytes,
+		Origin:   req.Origin.proto(),
+	}
+	var timeout <-chan time.Time
+	if req.TimeoutMs > 0 {
+		d := time.Duration(req.TimeoutMs) * time.Millisecond
+		cr.ExpiresAt = time.Now().Add(d).Unix()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	pending, sent := s.deliverForReply(cr.Id, &pb.Envelope{
+		RequestId: cr.Id,
+		Payload:   &pb.Envelope_ClipboardRequest{ClipboardRequest: cr},
+	}, targets, nil)
+	defer s.forgetReply(cr.Id)
+
+	if len(sent) == 0 {
+		http.Error(w, "No device connected that can share its clipboard", http.StatusServiceUnavailable)
+		return
+	}
+
+	var resp PasteResponse
+	select {
+	case a := <-pending.answer:
+		cc := a.msg.(*pb.ClipboardContent)
+		resp = PasteResponse{Device: a.device.name(), Denied: cc.Denied, Error: cc.Error}
+		switch {
+		case cc.Denied || cc.Error != "":
+		case int64(len(cc.Data)) > maxBytes:
+			// Don't trust the client to have enforced the limit
+			resp.Error = fmt.Sprintf("clipboard is %d bytes, over the %d byte limit", len(cc.Data), maxBytes)
+		default:
+			resp.MimeType = cc.MimeType
+			resp.Data = cc.Data
+		}
+	case <-timeout:
+		resp.TimedOut = true
+	case <-r.Context().Done():
+		// The script was interrupted
+	}
+
+	// Take the confirmation off every other screen
+	closed := &pb.Envelope{
+		Payload: &pb.Envelope_PromptClosed{PromptClosed: &pb.PromptClosed{Id: cr.Id}},
+	}
+	for _, c := range sent {
+		s.send(c, closed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleClipboardContent passes a device's clipboard to the waiting
+// `zelland paste`.
+func (s *Server) handleClipboardContent(c *client, cc *pb.ClipboardContent) {
+	s.resolveReply(c, cc.Id, cc)
+}
+
+// clipboardType reports whether a MIME type can be put on a phone clipboard.
+func clipboardType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") || strings.HasPrefix(mimeType, "image/")
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+)
+
+// Origin identifies where a CLI request was made. The CLI fills it in from
+// the Zellij environment variables.
+type Origin struct {
+	ZellijSession string `json:"zellij_session,omitempty"`
+	ZellijPaneID  string `json:"zellij_pane_id,omitempty"`
+}
+
+func (o Origin) proto() *pb.Origin {
+	if o.ZellijSession == "" && o.ZellijPaneID == "" {
+		return nil
+	}
+	return &pb.Origin{ZellijSession: o.ZellijSession, ZellijPaneId: o.ZellijPaneID}
+}
+
+// route picks the recipients for a triggered request: the devices matching
+// to; else the devices attached to the caller's Zellij session; else the
+// configured default target. A nil result means everyone.
+func (s *Server) route(to string, origin Origin) ([]*client, error) {
+	clients := s.snapshotClients()
+
+	if to != "" {
+		var matched []*client
+		for _, c := range clients {
+			if c.matches(to) {
+				matched = append(matched, c)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("no connected device matches %q", to)
+		}
+		return matched, nil
+	}
+
+	if origin.ZellijSession != "" {
+		var attached []*client
+		for _, c := range clients {
+			if c.info().ZellijSession == origin.ZellijSession {
+				attached = append(attached, c)
+			}
+		}
+		if len(attached) > 0 {
+			return attached, nil
+		}
+	}
+
+	if len(clients) == 0 {
+		return nil, nil
+	}
+
+	switch s.defaultTarget {
+	case "focused":
+		var best *client
+		for _, c := range clients {
+			if at := c.info().FocusedAt; !at.IsZero() && (best == nil || at.After(best.info().FocusedAt)) {
+				best = c
+			}
+		}
+		if best != nil {
+			return []*client{best}, nil
+		}
+		// Nothing has reported focus yet; use the last active device
+		fallthrough
+	case "last_active":
+		best := clients[0]
+		for _, c := range clients[1:] {
+			if c.info().LastActive.After(best.info().LastActive) {
+				best = c
+			}
+		}
+		return []*client{best}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// DeviceInfo describes a connected client for `zelland devices`.
+type DeviceInfo struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	AppVersion    string    `json:"app_version,omitempty"`
+	State         string    `json:"state"`
+	ActiveAssetID string    `json:"active_asset_id,omitempty"`
+	Foreground    bool      `json:"foreground"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	LastActive    time.Time `json:"last_active"`
+	FocusedAt     time.Time `json:"focused_at,omitempty"`
+	ZellijSession string    `json:"zellij_session,omitempty"`
+}
+
+func (c *client) info() DeviceInfo {
+	id, name := c.id(), c.name()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return DeviceInfo{
+		ID:            id,
+		Name:          name,
+		Address:       c.conn.RemoteAddr().String(),
+		AppVersion:    c.caps.appVersion,
+		State:         c.status.State.String(),
+		ActiveAssetID: c.status.ActiveAssetId,
+		Foreground:    c.status.Foreground,
+		ConnectedAt:   c.connectedAt,
+		LastActive:    c.lastActive,
+		FocusedAt:     c.focusedAt,
+		ZellijSession: c.status.ZellijSession,
+	}
+}
+
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	devices := []DeviceInfo{}
+	for _, c := range s.snapshotClients() {
+		devices = append(devices, c.info())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
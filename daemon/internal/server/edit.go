@@ -0,0 +1,301 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/zelland/daemon/internal/textdiff"
+	pb "github.com/zelland/daemon/proto"
+)
+
+const (
+	// maxEditBytes limits the content of one save.
+	maxEditBytes = 16 << 20
+	// Versions of edited files kept as merge bases for conflicting saves
+	maxVersions     = 64
+	maxVersionBytes = 64 << 20
+)
+
+// SaveResult is the reply to a successful PUT /assets/{id}.
+type SaveResult struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// SaveConflict is the body of a 409 reply to a save whose If-Match no
+// longer matches the file on disk.
+type SaveConflict struct {
+	Error         string `json:"error"`
+	CurrentSHA256 string `json:"current_sha256"`
+	// The client's edit merged onto the current content, if the version it
+	// edited is still known
+	Merged         string `json:"merged,omitempty"`
+	MergeConflicts bool   `json:"merge_conflicts,omitempty"`
+	// Unified diff from the current content to the client's
+	Diff string `json:"diff"`
+}
+
+// versionCache remembers recently served or saved file contents by hash,
+// so a stale save can be merged three ways against the version it edited.
+type versionCache struct {
+	mu    sync.Mutex
+	data  map[string]string
+	order []string
+	size  int
+}
+
+func newVersionCache() *versionCache {
+	return &versionCache{data: make(map[string]string)}
+}
+
+// add records content and returns its hash.
+func (vc *versionCache) add(content []byte) string {
+	hash := contentHash(content)
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if _, ok := vc.data[hash]; ok {
+		return hash
+	}
+	vc.data[hash] = string(content)
+	vc.order = append(vc.order, hash)
+	vc.size += len(content)
+	for len(vc.order) > 1 && (len(vc.order) > maxVersions || vc.size > maxVersionBytes) {
+		vc.size -= len(vc.data[vc.order[0]])
+		delete(vc.data, vc.order[0])
+		vc.order = vc.order[1:]
+	}
+	return hash
+}
+
+func (vc *versionCache) get(hash string) (string, bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	content, ok := vc.data[hash]
+	return content, ok
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// editableAsset returns the path of asset id and its view type if it was
+// opened as a view that may be saved back from a client. What the file's
+// name suggests does not matter; a script shown as an image stays read-only.
+func (s *Server) editableAsset(id string) (string, pb.OpenViewRequest_FileType, bool) {
+	p, ok := s.assetManager.Lookup(id)
+	if !ok {
+		return "", pb.OpenViewRequest_UNKNOWN, false
+	}
+	s.assetPathsMu.RLock()
+	ftype := s.assetTypes[id]
+	s.assetPathsMu.RUnlock()
+	switch ftype {
+	case pb.OpenViewRequest_MARKDOWN, pb.OpenViewRequest_CODE:
+		return p, ftype, true
+	}
+	return p, ftype, false
+}
+
+// handleAsset serves /assets/{id}. Editable assets are served with their
+// hash as the ETag, which a PUT of new content must send back as If-Match.
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if p, ftype, ok := s.editableAsset(id); ok {
+			s.serveEditable(w, r, p, ftype)
+			return
+		}
+		http.StripPrefix("/assets/", s.assetManager).ServeHTTP(w, r)
+	case http.MethodPut:
+		s.saveAsset(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveEditable(w http.ResponseWriter, r *http.Request, p string, ftype pb.OpenViewRequest_FileType) {
+	f, err := os.Open(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := io.ReadAll(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", `"`+s.versions.add(content)+`"`)
+	if ftype == pb.OpenViewRequest_CODE {
+		// Never let a WebView run an edited script or stylesheet
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), bytes.NewReader(content))
+}
+
+// saveAsset writes the body of a PUT over an editable asset, provided the
+// file still has the hash given in If-Match.
+func (s *Server) saveAsset(w http.ResponseWriter, r *http.Request, id string) {
+	owner, ok := s.authenticate(r)
+	if !ok || owner == "" {
+		http.Error(w, errUnauthenticated.Error(), http.StatusUnauthorized)
+		return
+	}
+	p, _, ok := s.editableAsset(id)
+	if p == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !ok {
+		http.Error(w, "only assets opened as markdown or code views can be edited", http.StatusForbidden)
+		return
+	}
+	base := strings.Trim(strings.TrimPrefix(r.Header.Get("If-Match"), "W/"), `"`)
+	if base == "" {
+		http.Error(w, "If-Match with the hash of the edited version is required", http.StatusPreconditionRequired)
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEditBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("content is over the %d byte limit", maxEditBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Write through symlinks rather than replacing them
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+
+	fi, err := os.Stat(real)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	current, err := os.ReadFile(real)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	currentHash := s.versions.add(current)
+
+	if base != currentHash {
+		conflict := SaveConflict{
+			Error:         "the file changed since this version was opened",
+			CurrentSHA256: currentHash,
+			Diff:          textdiff.Unified("current", "yours", string(current), string(content)),
+		}
+		if baseContent, ok := s.versions.get(base); ok {
+			conflict.Merged, conflict.MergeConflicts = textdiff.Merge3(baseContent, string(content), string(current), "yours", "current")
+		}
+		log.Printf("Save of %s by %s conflicts with the file on disk", real, owner)
+		w.Header().Set("ETag", `"`+currentHash+`"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(conflict)
+		return
+	}
+
+	hash := contentHash(content)
+	if hash != currentHash {
+		if s.editBackup {
+			if err := writeFileAtomic(real+".bak", current, fi); err != nil {
+				http.Error(w, fmt.Sprintf("failed to write backup: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := writeFileAtomic(real, content, fi); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.versions.add(content)
+		log.Printf("Saved %s (%d bytes) edited by %s", real, len(content), owner)
+		s.assetUpdated(p, hash, owner, int64(len(content)))
+	}
+
+	w.Header().Set("ETag", `"`+hash+`"`)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SaveResult{SHA256: hash, Size: int64(len(content))})
+}
+
+// assetUpdated tells clients about the new version of a file, under every
+// asset ID it is open as.
+func (s *Server) assetUpdated(filePath, hash, by string, size int64) {
+	for _, id := range s.assetIDsFor(filePath) {
+		s.deliver(&pb.Envelope{
+			Payload: &pb.Envelope_AssetUpdated{AssetUpdated: &pb.AssetUpdated{
+				AssetId:   id,
+				Sha256:    hash,
+				UpdatedBy: by,
+				Size:      size,
+			}},
+		})
+	}
+}
+
+// writeFileAtomic replaces name with data by renaming a synced temporary
+// file over it, so readers see either the old or the new content. The new
+// file gets the permissions and, where the daemon may set them, the owner
+// and group of like. Being a new file, it is no longer hard linked to other
+// names and has none of the old file's extended attributes.
+func writeFileAtomic(name string, data []byte, like os.FileInfo) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, like.Mode().Perm())
+	}
+	if st, ok := like.Sys().(*syscall.Stat_t); ok && err == nil {
+		// Without privileges this fails for other owners; the file is then
+		// the daemon user's, as any new file would be
+		os.Lchown(tmp, int(st.Uid), int(st.Gid))
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
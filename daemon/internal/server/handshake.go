@@ -0,0 +1,152 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtocolVersion is the version of the WebSocket protocol spoken by this
+// daemon. Bump it when a change requires clients to behave differently.
+const ProtocolVersion = 1
+
+// Version is the daemon version reported in Welcome. Release builds override
+// it with -ldflags "-X github.com/zelland/daemon/internal/server.Version=...".
+var Version = "0.1.0"
+
+// Features advertised to clients in Welcome.
+var serverFeatures = []string{"ack", "clipboard", "edit", "log_stream", "notifications", "outbox", "prompts", "sessions", "upload", "zellij_web"}
+
+// Clients that have not sent Hello or Resume within this time are treated
+// as legacy clients and their session starts anyway. A later Hello still
+// sets their capabilities.
+const helloTimeout = 2 * time.Second
+
+// capabilities describes what a client can handle.
+type capabilities struct {
+	protocolVersion uint32
+	appVersion      string
+	deviceName      string
+	deviceID        string
+	fileTypes       map[pb.OpenViewRequest_FileType]bool
+	features        map[string]bool
+}
+
+// legacyCapabilities are assumed for clients that never send Hello: the view
+// types of the original protocol and no optional features.
+func legacyCapabilities() capabilities {
+	return capabilities{
+		fileTypes: map[pb.OpenViewRequest_FileType]bool{
+			pb.OpenViewRequest_IMAGE:    true,
+			pb.OpenViewRequest_MARKDOWN: true,
+			pb.OpenViewRequest_PDF:      true,
+		},
+		features: map[string]bool{},
+	}
+}
+
+func (c capabilities) has(feature string) bool {
+	return c.features[feature]
+}
+
+func (s *Server) handleHello(c *client, h *pb.Hello) {
+	caps := capabilities{
+		protocolVersion: h.ProtocolVersion,
+		appVersion:      h.AppVersion,
+		deviceName:      h.DeviceName,
+		deviceID:        h.DeviceId,
+		fileTypes:       make(map[pb.OpenViewRequest_FileType]bool),
+		features:        make(map[string]bool),
+	}
+	if h.ProtocolVersion == ProtocolVersion {
+		for _, ft := range h.SupportedFileTypes {
+			caps.fileTypes[ft] = true
+		}
+		for _, f := range h.Features {
+			caps.features[f] = true
+		}
+	} else {
+		// Its Hello may mean something else; speak the original protocol
+		log.Printf("%s speaks protocol v%d, not v%d; treating it as a legacy client", h.DeviceName, h.ProtocolVersion, ProtocolVersion)
+		legacy := legacyCapabilities()
+		caps.fileTypes, caps.features = legacy.fileTypes, legacy.features
+	}
+	c.setCapabilities(caps)
+
+	log.Printf("Hello from %s (app %s, protocol v%d)", c.name(), h.AppVersion, h.ProtocolVersion)
+
+	s.sendWelcome(c)
+	s.handleResume(c, &pb.Resume{LastSeq: h.LastSeq})
+}
+
+func (s *Server) sendWelcome(c *client) {
+	welcome := &pb.Welcome{
+		ProtocolVersion: ProtocolVersion,
+		ServerVersion:   Version,
+		Features:        serverFeatures,
+	}
+	// Only clients that may use them learn about host actions
+	if c.token != "" {
+		welcome.ZellijActions = s.zellijActions
+		welcome.QuickActions = s.quickActionList
+	}
+	s.send(c, &pb.Envelope{
+		Payload: &pb.Envelope_Welcome{Welcome: welcome},
+	})
+}
+
+// beginSession delivers what was broadcast while no client that could
+// handle it was connected. It runs on Hello and Resume and, for legacy
+// clients, after helloTimeout. A Hello that comes after the timeout runs it
+// again, so what the client could not handle as a legacy client still
+// reaches it.
+func (s *Server) beginSession(c *client) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	caps := c.capabilities()
+	var delivered []uint64
+	for _, env := range s.outbox.Undelivered(c.id()) {
+		out, ok := adapt(caps, env)
+		if !ok {
+			// Left for a client that can handle it
+			continue
+		}
+		c.markReplayed(env.Seq)
+		if c.send(out) {
+			delivered = append(delivered, env.Seq)
+		}
+	}
+	if err := s.outbox.MarkDelivered(delivered...); err != nil {
+		log.Printf("Outbox error: %v", err)
+	}
+}
+
+// adapt returns env in a form the client can handle, downgrading or
+// dropping it when the client lacks the needed capability.
+func adapt(caps capabilities, env *pb.Envelope) (*pb.Envelope, bool) {
+	switch payload := env.Payload.(type) {
+	case *pb.Envelope_OpenView:
+		ft := payload.OpenView.FileType
+		if ft == pb.OpenViewRequest_UNKNOWN || caps.fileTypes[ft] {
+			return env, true
+		}
+		// Fall back to a generic "open this URL" view
+		out := proto.Clone(env).(*pb.Envelope)
+		out.GetOpenView().FileType = pb.OpenViewRequest_UNKNOWN
+		return out, true
+	case *pb.Envelope_Notification:
+		return env, caps.has("notifications")
+	case *pb.Envelope_Prompt:
+		// Don't leave a script waiting on a device that can't answer
+		return env, caps.has("prompts")
+	case *pb.Envelope_ClipboardSet, *pb.Envelope_ClipboardRequest:
+		return env, caps.has("clipboard")
+	case *pb.Envelope_ReceiveRequest:
+		return env, caps.has("upload")
+	default:
+		return env, true
+	}
+}
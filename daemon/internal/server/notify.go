@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// IPC Request Body
+type NotifyRequest struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Urgency string `json:"urgency"`   // "low", "normal" (default) or "high"
+	Open    string `json:"file_path"` // Optional file to open when tapped
+	To      string `json:"to"`        // Optional device name or ID
+	Origin
+}
+
+func (s *Server) handleTriggerNotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req NotifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	urgency, ok := parseUrgency(req.Urgency)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown urgency %q", req.Urgency), http.StatusBadRequest)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	n := &pb.Notification{
+		Id:        assets.NewID(),
+		Title:     req.Title,
+		Body:      req.Body,
+		Urgency:   urgency,
+		Timestamp: time.Now().Unix(),
+		Origin:    req.Origin.proto(),
+	}
+
+	if req.Open != "" {
+		view, err := s.registerView(req.Open, fileTypeFor(req.Open), filepath.Base(req.Open))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to access file: %v", err), http.StatusBadRequest)
+			return
+		}
+		n.Action = view
+	}
+
+	env := &pb.Envelope{
+		RequestId: n.Id,
+		Payload:   &pb.Envelope_Notification{Notification: n},
+	}
+	if len(s.deliverTo(env, targets)) > 0 {
+		fmt.Fprintf(w, "Sent notification %s", n.Id)
+	} else {
+		fmt.Fprintf(w, "Queued notification %s until a device connects", n.Id)
+	}
+}
+
+func parseUrgency(s string) (pb.Notification_Urgency, bool) {
+	switch strings.ToLower(s) {
+	case "", "normal":
+		return pb.Notification_NORMAL, true
+	case "low":
+		return pb.Notification_LOW, true
+	case "high":
+		return pb.Notification_HIGH, true
+	default:
+		return pb.Notification_NORMAL, false
+	}
+}
+
+// fileTypeFor guesses the view type for a file from its extension.
+func fileTypeFor(path string) pb.OpenViewRequest_FileType {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return pb.OpenViewRequest_MARKDOWN
+	case ".pdf":
+		return pb.OpenViewRequest_PDF
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp":
+		return pb.OpenViewRequest_IMAGE
+	case ".go", ".py", ".rs", ".c", ".h", ".cc", ".cpp", ".hpp", ".java", ".kt", ".kts",
+		".js", ".ts", ".tsx", ".jsx", ".rb", ".lua", ".sh", ".bash", ".zsh", ".fish",
+		".json", ".yaml", ".yml", ".toml", ".kdl", ".proto", ".sql", ".gradle", ".txt":
+		return pb.OpenViewRequest_CODE
+	default:
+		return pb.OpenViewRequest_UNKNOWN
+	}
+}
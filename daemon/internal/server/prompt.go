@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// AskRequest is the body of /api/v1/trigger/ask.
+type AskRequest struct {
+	Question string   `json:"question"`
+	Choices  []string `json:"choices,omitempty"`
+	// How long to wait for an answer; zero waits until the CLI gives up
+	TimeoutMs int64  `json:"timeout_ms,omitempty"`
+	To        string `json:"to,omitempty"`
+	Origin
+}
+
+// AskResponse is the reply to an AskRequest.
+type AskResponse struct {
+	Answer    string `json:"answer"`
+	Device    string `json:"device,omitempty"`
+	Dismissed bool   `json:"dismissed,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+}
+
+func (s *Server) handleTriggerAsk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Question == "" {
+		http.Error(w, "question is required", http.StatusBadRequest)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	p := &pb.Prompt{
+		Id:       assets.NewID(),
+		Question: req.Question,
+		Choices:  req.Choices,
+		Origin:   req.Origin.proto(),
+	}
+	var timeout <-chan time.Time
+	if req.TimeoutMs > 0 {
+		d := time.Duration(req.TimeoutMs) * time.Millisecond
+		p.ExpiresAt = time.Now().Add(d).Unix()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	pending, sent := s.deliverForReply(p.Id, &pb.Envelope{
+		RequestId: p.Id,
+		Payload:   &pb.Envelope_Prompt{Prompt: p},
+	}, targets, func(m proto.Message) error {
+		pr := m.(*pb.PromptResponse)
+		if !pr.Dismissed && len(p.Choices) > 0 && !slices.Contains(p.Choices, pr.Answer) {
+			return fmt.Errorf("answer %q is not one of %v", pr.Answer, p.Choices)
+		}
+		return nil
+	})
+	defer s.forgetReply(p.Id)
+
+	if len(sent) == 0 {
+		http.Error(w, "No device connected to answer", http.StatusServiceUnavailable)
+		return
+	}
+
+	var resp AskResponse
+	select {
+	case a := <-pending.answer:
+		pr := a.msg.(*pb.PromptResponse)
+		resp = AskResponse{Answer: pr.Answer, Device: a.device.name(), Dismissed: pr.Dismissed}
+	case <-timeout:
+		resp.TimedOut = true
+	case <-r.Context().Done():
+		// The script was interrupted
+	}
+
+	// Take the question off every other screen
+	closed := &pb.Envelope{
+		Payload: &pb.Envelope_PromptClosed{PromptClosed: &pb.PromptClosed{Id: p.Id}},
+	}
+	for _, c := range sent {
+		s.send(c, closed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handlePromptResponse passes an answer to the waiting `zelland ask`.
+func (s *Server) handlePromptResponse(c *client, pr *pb.PromptResponse) {
+	s.resolveReply(c, pr.Id, pr)
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	"github.com/zelland/daemon/internal/config"
+	"github.com/zelland/daemon/internal/logtail"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// quickActionStopTimeout is how long a cancelled quick action has to exit
+// after SIGTERM before it is killed.
+const quickActionStopTimeout = 5 * time.Second
+
+// loadQuickActions validates the configured quick actions and returns them
+// by name, along with the list advertised in Welcome.
+func loadQuickActions(actions []config.QuickAction) (map[string]config.QuickAction, []*pb.QuickAction, error) {
+	byName := make(map[string]config.QuickAction)
+	var list []*pb.QuickAction
+	for _, qa := range actions {
+		if qa.Name == "" || qa.Command == "" {
+			return nil, nil, errors.New("quick actions need a name and a command")
+		}
+		if _, dup := byName[qa.Name]; dup {
+			return nil, nil, fmt.Errorf("duplicate quick action %q", qa.Name)
+		}
+		output := pb.QuickAction_LOG
+		switch qa.Output {
+		case "", "log":
+		case "notify":
+			output = pb.QuickAction_NOTIFY
+		default:
+			return nil, nil, fmt.Errorf("quick action %q: unknown output %q", qa.Name, qa.Output)
+		}
+		byName[qa.Name] = qa
+		list = append(list, &pb.QuickAction{
+			Name:        qa.Name,
+			Description: qa.Description,
+			Command:     qa.Command,
+			Confirm:     qa.Confirm,
+			Output:      output,
+		})
+	}
+	return byName, list, nil
+}
+
+// handleRunQuickAction runs a quick action for a client, streaming its
+// output, and replies once it exits.
+func (s *Server) handleRunQuickAction(c *client, requestID string, req *pb.RunQuickAction) {
+	result := &pb.QuickActionResult{Name: req.Name, ExitCode: -1}
+	defer func() {
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_QuickActionResult{QuickActionResult: result},
+		})
+	}()
+
+	if err := s.runQuickAction(c, req, result); err != nil {
+		log.Printf("Quick action %q from %s: %v", req.Name, c.name(), err)
+		result.Error = err.Error()
+	}
+}
+
+func (s *Server) runQuickAction(c *client, req *pb.RunQuickAction, result *pb.QuickActionResult) error {
+	if err := requireAuth(c); err != nil {
+		return err
+	}
+	qa, ok := s.quickActions[req.Name]
+	if !ok {
+		return fmt.Errorf("unknown quick action %q", req.Name)
+	}
+
+	if req.Cancel {
+		s.runningMu.Lock()
+		cancel, running := s.running[qa.Name]
+		s.runningMu.Unlock()
+		if !running {
+			return fmt.Errorf("%s is not running", qa.Name)
+		}
+		cancel()
+		result.ExitCode = 0
+		return nil
+	}
+
+	if qa.Confirm && !req.Confirmed {
+		return fmt.Errorf("%s must be confirmed before it runs", qa.Name)
+	}
+
+	// One instance per action, so a double tap doesn't deploy twice
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.runningMu.Lock()
+	if _, running := s.running[qa.Name]; running {
+		s.runningMu.Unlock()
+		return fmt.Errorf("%s is already running", qa.Name)
+	}
+	s.running[qa.Name] = cancel
+	s.runningMu.Unlock()
+	defer func() {
+		s.runningMu.Lock()
+		delete(s.running, qa.Name)
+		s.runningMu.Unlock()
+	}()
+
+	st := s.newStream(qa.Name)
+	defer st.Close()
+	result.StreamId = st.ID
+	if qa.Output != "notify" {
+		s.openLogView(st, []*client{c}, Origin{})
+	}
+
+	log.Printf("Running quick action %q for %s", qa.Name, c.name())
+	start := time.Now()
+	code, err := runCommand(ctx, qa, st)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	result.ExitCode = int32(code)
+	result.DurationMs = elapsed.Milliseconds()
+
+	summary := fmt.Sprintf("exited with status %d after %s", code, elapsed)
+	if err != nil {
+		summary = fmt.Sprintf("failed after %s: %v", elapsed, err)
+	}
+	if qa.Output == "notify" {
+		s.notifyQuickAction(c, qa, st, code, err)
+	}
+	st.Append("zelland: " + qa.Name + " " + summary)
+	return err
+}
+
+// runCommand runs a quick action with its output appended to st and returns
+// its exit code. Cancelling ctx terminates the command's process group.
+func runCommand(ctx context.Context, qa config.QuickAction, st *logtail.Stream) (int, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", qa.Command)
+	cmd.Dir = qa.Dir
+	cmd.Env = os.Environ()
+	for k, v := range qa.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = quickActionStopTimeout
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return -1, err
+	}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		return -1, err
+	}
+	pw.Close()
+
+	output := make(chan struct{})
+	go func() {
+		defer close(output)
+		defer pr.Close()
+		st.ReadFrom(context.Background(), pr)
+	}()
+
+	err = cmd.Wait()
+	// Background children may keep the pipe open; don't wait for them
+	select {
+	case <-output:
+	case <-time.After(time.Second):
+	}
+
+	if ctx.Err() != nil {
+		return -1, errors.New("cancelled")
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// notifyQuickAction tells the client how a quick action with notify output
+// finished, with the last lines of its output.
+func (s *Server) notifyQuickAction(c *client, qa config.QuickAction, st *logtail.Stream, code int, runErr error) {
+	title := qa.Name + " succeeded"
+	urgency := pb.Notification_NORMAL
+	if code != 0 || runErr != nil {
+		title = fmt.Sprintf("%s failed (exit %d)", qa.Name, code)
+		urgency = pb.Notification_HIGH
+	}
+
+	lines := st.Since(0)
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+	var body strings.Builder
+	for _, l := range lines {
+		body.WriteString(l.Text)
+		body.WriteByte('\n')
+	}
+
+	n := &pb.Notification{
+		Id:        assets.NewID(),
+		Title:     title,
+		Body:      strings.TrimRight(body.String(), "\n"),
+		Urgency:   urgency,
+		Timestamp: time.Now().Unix(),
+	}
+	s.deliverTo(&pb.Envelope{
+		RequestId: n.Id,
+		Payload:   &pb.Envelope_Notification{Notification: n},
+	}, []*client{c})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"log"
+	"slices"
+
+	pb "github.com/zelland/daemon/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// pendingReply is a CLI request waiting for the first answer from one of
+// the devices an envelope was sent to, e.g. a prompt or a paste request.
+type pendingReply struct {
+	devices []*client
+	// Checks an answer before it is accepted; may be nil
+	accept func(proto.Message) error
+	answer chan deviceReply
+}
+
+type deviceReply struct {
+	device *client
+	msg    proto.Message
+}
+
+// deliverForReply sends env to targets and registers id so that the first
+// recipient to answer resolves it. Callers must forgetReply when done.
+func (s *Server) deliverForReply(id string, env *pb.Envelope, targets []*client, accept func(proto.Message) error) (*pendingReply, []*client) {
+	pending := &pendingReply{accept: accept, answer: make(chan deviceReply, 1)}
+
+	// Hold the lock while sending so an answer can't arrive before we know
+	// who may give it
+	s.repliesMu.Lock()
+	defer s.repliesMu.Unlock()
+	s.replies[id] = pending
+	pending.devices = s.deliverTo(env, targets)
+	return pending, pending.devices
+}
+
+func (s *Server) forgetReply(id string) {
+	s.repliesMu.Lock()
+	delete(s.replies, id)
+	s.repliesMu.Unlock()
+}
+
+// resolveReply hands msg from c to the request waiting on id. Late answers,
+// answers from devices the envelope was not sent to and answers rejected by
+// the request are dropped.
+func (s *Server) resolveReply(c *client, id string, msg proto.Message) {
+	s.repliesMu.Lock()
+	pending, ok := s.replies[id]
+	allowed := ok && slices.Contains(pending.devices, c)
+	s.repliesMu.Unlock()
+	if !ok {
+		// Already answered or given up on
+		return
+	}
+	if !allowed {
+		log.Printf("Ignoring reply to %s from %s: not sent to it", id, c.name())
+		return
+	}
+	if pending.accept != nil {
+		if err := pending.accept(msg); err != nil {
+			log.Printf("Ignoring reply to %s from %s: %v", id, c.name(), err)
+			return
+		}
+	}
+
+	select {
+	case pending.answer <- deviceReply{device: c, msg: msg}:
+	default:
+		// Another device answered first
+	}
+}
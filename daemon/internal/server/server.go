@@ -1,19 +1,26 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/zelland/daemon/internal/assets"
-	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/browse"
+	"github.com/zelland/daemon/internal/config"
+	"github.com/zelland/daemon/internal/logtail"
+	"github.com/zelland/daemon/internal/outbox"
+	"github.com/zelland/daemon/internal/store"
+	"github.com/zelland/daemon/internal/upload"
+	"github.com/zelland/daemon/internal/zellij"
 	pb "github.com/zelland/daemon/proto"
 	"google.golang.org/protobuf/proto"
 )
@@ -23,28 +30,158 @@ type Server struct {
 	certFile     string
 	keyFile      string
 	upgrader     websocket.Upgrader
-	clients      map[*websocket.Conn]bool
+	clients      map[*websocket.Conn]*client
 	clientsMu    sync.Mutex
 	assetManager *assets.Manager
 	// Map AssetID -> Original FilePath (for annotation syncing)
-	assetPaths   map[string]string 
+	assetPaths map[string]string
+	// Map AssetID -> view type it was opened as (for saving edits)
+	assetTypes   map[string]pb.OpenViewRequest_FileType
 	assetPathsMu sync.RWMutex
+	// Map StreamID -> live log stream (for LOG views)
+	streams   map[string]*logtail.Stream
+	streamsMu sync.RWMutex
+	// Sequence numbers and retained envelopes for reconnecting clients
+	outbox    *outbox.Outbox
+	retention map[string]time.Duration
+	// Map RequestID -> CLI request waiting for client acks
+	acks   map[string]*ackWaiter
+	acksMu sync.Mutex
+	// Recipient policy when a trigger names no device
+	defaultTarget string
+	// Token name -> pre-shared key; empty disables authentication
+	tokens map[string]string
+	// Supervised `zellij web` server
+	zellijWeb    *zellij.WebServer
+	autostartWeb bool
+	// Zellij sessions as of the last listing, for change events
+	zellijCLI  zellij.CLI
+	sessions   []zellij.Session
+	sessionsMu sync.Mutex
+	// Where captured scrollback pages are written
+	captureDir string
+	// `zellij action` names clients may run
+	allowedActions map[string]bool
+	zellijActions  []string
+	// Configured quick actions, and the cancel funcs of running ones
+	quickActions    map[string]config.QuickAction
+	quickActionList []*pb.QuickAction
+	running         map[string]context.CancelFunc
+	runningMu       sync.Mutex
+	// Map envelope ID -> CLI request waiting for a device to answer
+	replies   map[string]*pendingReply
+	repliesMu sync.Mutex
+	// Size limit for clipboard content in either direction
+	clipboardMax int64
+	// Unfinished uploads, and `zelland receive` calls waiting for one
+	uploads     *upload.Store
+	receivers   map[string]*receiver
+	receiversMu sync.Mutex
+	// Directories clients may browse
+	browser *browse.Browser
+	// Recent contents of edited files, and saves in progress
+	versions   *versionCache
+	editMu     sync.Mutex
+	editBackup bool
+	// Where annotations are kept, and a lock serializing changes to them
+	annotations   store.AnnotationStore
+	annotationsMu sync.Mutex
+	// Open markdown files whose annotations are watched for outside changes
+	watches map[string]*annotationWatch
 }
 
-func New(port int, certFile, keyFile string) *Server {
-	return &Server{
-		port:         port,
-		certFile:     certFile,
-		keyFile:      keyFile,
+func New(cfg *config.Config) (*Server, error) {
+	outboxPath := ""
+	if cfg.StateDir != "" {
+		outboxPath = filepath.Join(cfg.StateDir, "outbox.json")
+	}
+	ob, err := outbox.Open(outboxPath, cfg.Outbox.MaxMessages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+
+	captureDir := filepath.Join(os.TempDir(), "zelland-captures")
+	if cfg.StateDir != "" {
+		captureDir = filepath.Join(cfg.StateDir, "captures")
+	}
+
+	uploadMeta := ""
+	if cfg.StateDir != "" {
+		uploadMeta = filepath.Join(cfg.StateDir, "uploads")
+	}
+	uploads, err := upload.Open(uploadMeta, cfg.Uploads.InboxDir, cfg.Uploads.MaxFileBytes, cfg.Uploads.QuotaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploads: %w", err)
+	}
+
+	browser, err := browse.New(cfg.BrowseRoots)
+	if err != nil {
+		return nil, err
+	}
+
+	a := cfg.Annotations
+	annotations, err := store.Open(a.Backend, a.Storage, a.DataDir, a.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	quickActions, quickActionList, err := loadQuickActions(cfg.QuickActions)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedActions := make(map[string]bool)
+	for _, a := range cfg.Zellij.AllowedActions {
+		allowedActions[a] = true
+	}
+
+	retention := make(map[string]time.Duration)
+	for kind, d := range cfg.Outbox.Retention {
+		retention[kind] = time.Duration(d)
+	}
+	// Clipboard content is often a secret; never write it to disk
+	delete(retention, "clipboard_set")
+	delete(retention, "clipboard_request")
+
+	s := &Server{
+		port:     cfg.Port,
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins for now
 			},
 		},
-		clients:      make(map[*websocket.Conn]bool),
-		assetManager: assets.New(),
-		assetPaths:   make(map[string]string),
+		clients:         make(map[*websocket.Conn]*client),
+		assetManager:    assets.New(),
+		assetPaths:      make(map[string]string),
+		assetTypes:      make(map[string]pb.OpenViewRequest_FileType),
+		streams:         make(map[string]*logtail.Stream),
+		outbox:          ob,
+		retention:       retention,
+		acks:            make(map[string]*ackWaiter),
+		defaultTarget:   cfg.DefaultTarget,
+		tokens:          cfg.Tokens,
+		autostartWeb:    cfg.Zellij.Web.Autostart,
+		zellijCLI:       zellij.CLI{Binary: cfg.Zellij.Binary},
+		captureDir:      captureDir,
+		allowedActions:  allowedActions,
+		zellijActions:   cfg.Zellij.AllowedActions,
+		quickActions:    quickActions,
+		quickActionList: quickActionList,
+		running:         make(map[string]context.CancelFunc),
+		replies:         make(map[string]*pendingReply),
+		clipboardMax:    cfg.Clipboard.MaxBytes,
+		uploads:         uploads,
+		receivers:       make(map[string]*receiver),
+		browser:         browser,
+		versions:        newVersionCache(),
+		editBackup:      cfg.Edit.Backup,
+		annotations:     annotations,
+		watches:         make(map[string]*annotationWatch),
 	}
+	s.zellijWeb = zellij.NewWebServer(cfg.Zellij.Binary, cfg.Zellij.Web.Port, cfg.Zellij.Web.Args, s.newStream("zellij web"))
+	return s, nil
 }
 
 func (s *Server) Start() error {
@@ -52,15 +189,39 @@ func (s *Server) Start() error {
 	http.HandleFunc("/ws", s.handleWebSocket)
 
 	// Asset serving endpoint
-	http.Handle("/assets/", http.StripPrefix("/assets/", s.assetManager))
+	http.HandleFunc("/assets/", s.handleAsset)
+	http.HandleFunc("/logs/", s.handleLogSnapshot)
+
+	// Client endpoints that touch host files, authenticated with a token
+	http.HandleFunc("/api/v1/uploads", s.handleUploads)
+	http.HandleFunc("/api/v1/uploads/", s.handleUploads)
+	http.HandleFunc("/api/v1/browse", s.handleBrowse)
 
 	// IPC / Trigger endpoints (restricted to loopback)
 	http.Handle("/api/v1/trigger/show", s.loopbackOnly(http.HandlerFunc(s.handleTriggerShow)))
 	http.Handle("/api/v1/trigger/md", s.loopbackOnly(http.HandlerFunc(s.handleTriggerMarkdown)))
+	http.Handle("/api/v1/trigger/tail", s.loopbackOnly(http.HandlerFunc(s.handleTriggerTail)))
+	http.Handle("/api/v1/trigger/tail/stdin", s.loopbackOnly(http.HandlerFunc(s.handleTriggerTailStdin)))
+	http.Handle("/api/v1/trigger/notify", s.loopbackOnly(http.HandlerFunc(s.handleTriggerNotify)))
+	http.Handle("/api/v1/trigger/ask", s.loopbackOnly(http.HandlerFunc(s.handleTriggerAsk)))
+	http.Handle("/api/v1/trigger/copy", s.loopbackOnly(http.HandlerFunc(s.handleTriggerCopy)))
+	http.Handle("/api/v1/trigger/paste", s.loopbackOnly(http.HandlerFunc(s.handleTriggerPaste)))
+	http.Handle("/api/v1/trigger/receive", s.loopbackOnly(http.HandlerFunc(s.handleTriggerReceive)))
+	http.Handle("/api/v1/trigger/capture", s.loopbackOnly(http.HandlerFunc(s.handleTriggerCapture)))
+	http.Handle("/api/v1/trigger/annotate", s.loopbackOnly(http.HandlerFunc(s.handleTriggerAnnotate)))
+	http.Handle("/api/v1/devices", s.loopbackOnly(http.HandlerFunc(s.handleDevices)))
+
+	if s.autostartWeb {
+		if err := s.zellijWeb.Start(context.Background()); err != nil {
+			log.Printf("Failed to start zellij web: %v", err)
+		}
+	}
+
+	go s.watchSessions()
 
 	addr := fmt.Sprintf(":%d", s.port)
 	log.Printf("Starting Zelland Daemon on %s (TLS: %v)", addr, s.certFile != "")
-	
+
 	if s.certFile != "" && s.keyFile != "" {
 		return http.ListenAndServeTLS(addr, s.certFile, s.keyFile, nil)
 	}
@@ -89,6 +250,23 @@ func (s *Server) loopbackOnly(next http.Handler) http.Handler {
 type ShowRequest struct {
 	FilePath string `json:"file_path"`
 	Title    string `json:"title"`
+	// If set, wait up to this long for devices to acknowledge the view
+	WaitMs int64 `json:"wait_ms,omitempty"`
+	// Device name or ID to send to instead of the default target
+	To string `json:"to,omitempty"`
+	Origin
+}
+
+// IPC Response Body
+type TriggerResponse struct {
+	AssetID   string `json:"asset_id"`
+	RequestID string `json:"request_id"`
+	// Number of connected devices the view was sent to. Zero means it was
+	// queued in the outbox for the next device to connect.
+	SentTo int `json:"sent_to"`
+	// How many of those devices declared support for acks in Hello
+	AckCapable int         `json:"ack_capable"`
+	Acks       []DeviceAck `json:"acks,omitempty"`
 }
 
 func (s *Server) handleTriggerShow(w http.ResponseWriter, r *http.Request) {
@@ -111,40 +289,108 @@ func (s *Server) genericTrigger(w http.ResponseWriter, r *http.Request, ftype pb
 		return
 	}
 
-	// Register file
-	assetID, err := s.assetManager.Register(req.FilePath)
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	view, err := s.registerView(req.FilePath, ftype, req.Title)
 	if err != nil {
 		log.Printf("Failed to register asset: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to access file: %v", err), http.StatusBadRequest)
 		return
 	}
-	
-	s.assetPathsMu.Lock()
-	s.assetPaths[assetID] = req.FilePath
-	s.assetPathsMu.Unlock()
+	view.Origin = req.Origin.proto()
 
-	// Construct URL
-	assetURL := fmt.Sprintf("/assets/%s", assetID)
+	resp := s.sendView(r.Context(), view, targets, req.WaitMs)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	// Broadcast to clients
+// sendView delivers view to targets and, if waitMs is set, waits for their
+// acks. It returns the reply for the CLI.
+func (s *Server) sendView(ctx context.Context, view *pb.OpenViewRequest, targets []*client, waitMs int64) TriggerResponse {
 	viewReq := &pb.Envelope{
+		RequestId: assets.NewID(),
 		Payload: &pb.Envelope_OpenView{
-			OpenView: &pb.OpenViewRequest{
-				AssetId:  assetID,
-				Url:      assetURL,
-				FileType: ftype,
-				Title:    req.Title,
-			},
+			OpenView: view,
 		},
 	}
 
-	s.Broadcast(viewReq)
+	var waiter *ackWaiter
+	if waitMs > 0 {
+		waiter = s.expectAcks(viewReq.RequestId)
+		defer s.forgetAcks(viewReq.RequestId)
+	}
 
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Showing %s (ID: %s)", req.FilePath, assetID)
+	sent := s.deliverTo(viewReq, targets)
+	resp := TriggerResponse{
+		AssetID:   view.AssetId,
+		RequestID: viewReq.RequestId,
+		SentTo:    len(sent),
+	}
+	for _, c := range sent {
+		if c.capabilities().has("ack") {
+			resp.AckCapable++
+		}
+	}
+	if waiter != nil && resp.AckCapable > 0 {
+		resp.Acks = waiter.wait(ctx, time.Duration(waitMs)*time.Millisecond, resp.AckCapable)
+	}
+	return resp
+}
+
+// registerView registers a file as an asset and builds the OpenViewRequest
+// that points at it.
+func (s *Server) registerView(filePath string, ftype pb.OpenViewRequest_FileType, title string) (*pb.OpenViewRequest, error) {
+	assetID, err := s.assetManager.Register(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s.assetPathsMu.Lock()
+	s.assetPaths[assetID] = filePath
+	s.assetTypes[assetID] = ftype
+	s.assetPathsMu.Unlock()
+
+	switch ftype {
+	case pb.OpenViewRequest_MARKDOWN, pb.OpenViewRequest_IMAGE, pb.OpenViewRequest_PDF,
+		pb.OpenViewRequest_CODE, pb.OpenViewRequest_TEXT:
+		s.watchAnnotations(filePath)
+	}
+
+	return &pb.OpenViewRequest{
+		AssetId:  assetID,
+		Url:      fmt.Sprintf("/assets/%s", assetID),
+		FileType: ftype,
+		Title:    title,
+	}, nil
+}
+
+// assetIDsFor returns the IDs a file is registered under; each `zelland md`
+// or browse OPEN of the same file adds one.
+func (s *Server) assetIDsFor(filePath string) []string {
+	s.assetPathsMu.RLock()
+	defer s.assetPathsMu.RUnlock()
+
+	var ids []string
+	for id, p := range s.assetPaths {
+		if p == filePath {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.authenticate(r)
+	if !ok {
+		log.Printf("Rejected unauthenticated client from %s", r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Upgrade error: %v", err)
@@ -152,13 +398,21 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	s.registerClient(conn)
+	c := s.registerClient(conn, token)
 	defer s.unregisterClient(conn)
 
 	log.Printf("Client connected: %s", conn.RemoteAddr())
 
 	// Send a welcome ping
-	s.sendPing(conn)
+	s.sendPing(c)
+
+	// Clients that never say Hello still get what they missed
+	legacy := time.AfterFunc(helloTimeout, func() {
+		if c.startSession() {
+			s.beginSession(c)
+		}
+	})
+	defer legacy.Stop()
 
 	for {
 		_, message, err := conn.ReadMessage()
@@ -166,21 +420,24 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			log.Printf("Read error: %v", err)
 			break
 		}
-		
+
 		var env pb.Envelope
 		if err := proto.Unmarshal(message, &env); err != nil {
 			log.Printf("Unmarshal error: %v", err)
 			continue
 		}
 
-		s.handleMessage(conn, &env)
+		c.touch()
+		s.handleMessage(c, &env)
 	}
 }
 
-func (s *Server) registerClient(conn *websocket.Conn) {
+func (s *Server) registerClient(conn *websocket.Conn, token string) *client {
 	s.clientsMu.Lock()
 	defer s.clientsMu.Unlock()
-	s.clients[conn] = true
+	c := newClient(conn, token)
+	s.clients[conn] = c
+	return c
 }
 
 func (s *Server) unregisterClient(conn *websocket.Conn) {
@@ -190,7 +447,7 @@ func (s *Server) unregisterClient(conn *websocket.Conn) {
 	log.Printf("Client disconnected: %s", conn.RemoteAddr())
 }
 
-func (s *Server) sendPing(conn *websocket.Conn) {
+func (s *Server) sendPing(c *client) {
 	ping := &pb.Envelope{
 		Payload: &pb.Envelope_Ping{
 			Ping: &pb.KeepAlive{
@@ -198,64 +455,141 @@ func (s *Server) sendPing(conn *websocket.Conn) {
 			},
 		},
 	}
-	s.SendMessage(conn, ping)
+	s.send(c, ping)
 }
 
-func (s *Server) SendMessage(conn *websocket.Conn, env *pb.Envelope) {
-	data, err := proto.Marshal(env)
-	if err != nil {
-		log.Printf("Marshal error: %v", err)
-		return
-	}
+// send sends env to a single client. Such envelopes, like pings and RPC
+// replies, are not replayed and carry no sequence number.
+func (s *Server) send(c *client, env *pb.Envelope) {
+	c.send(env)
+}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
-		log.Printf("Write error: %v", err)
-	}
+// reply sends the response to a client RPC, echoing its request ID.
+func (s *Server) reply(c *client, requestID string, env *pb.Envelope) {
+	env.RequestId = requestID
+	s.send(c, env)
 }
 
-func (s *Server) handleMessage(conn *websocket.Conn, env *pb.Envelope) {
+func (s *Server) handleMessage(c *client, env *pb.Envelope) {
 	switch payload := env.Payload.(type) {
 	case *pb.Envelope_Annotation:
-		s.handleAnnotation(payload.Annotation)
+		s.handleAnnotation(c, payload.Annotation)
+	case *pb.Envelope_Status:
+		c.setStatus(payload.Status)
+	case *pb.Envelope_LogSubscribe:
+		s.handleLogSubscribe(c, payload.LogSubscribe)
+	case *pb.Envelope_Resume:
+		s.handleResume(c, payload.Resume)
+	case *pb.Envelope_Ack:
+		s.handleAck(c, payload.Ack)
+	case *pb.Envelope_Hello:
+		s.handleHello(c, payload.Hello)
+	case *pb.Envelope_ZellijWebRequest:
+		go s.handleZellijWeb(c, env.RequestId, payload.ZellijWebRequest)
+	case *pb.Envelope_SessionRequest:
+		go s.handleSessionRequest(c, env.RequestId, payload.SessionRequest)
+	case *pb.Envelope_CaptureRequest:
+		go s.handleCaptureRequest(c, env.RequestId, payload.CaptureRequest)
+	case *pb.Envelope_ActionRequest:
+		go s.handleActionRequest(c, env.RequestId, payload.ActionRequest)
+	case *pb.Envelope_RunQuickAction:
+		go s.handleRunQuickAction(c, env.RequestId, payload.RunQuickAction)
+	case *pb.Envelope_PromptResponse:
+		s.handlePromptResponse(c, payload.PromptResponse)
+	case *pb.Envelope_BrowseRequest:
+		go s.handleBrowseRequest(c, env.RequestId, payload.BrowseRequest)
+	case *pb.Envelope_ClipboardContent:
+		s.handleClipboardContent(c, payload.ClipboardContent)
 	default:
 		log.Printf("Received message: %T", payload)
 	}
 }
 
-func (s *Server) handleAnnotation(action *pb.AnnotationAction) {
-	s.assetPathsMu.RLock()
-	filePath, ok := s.assetPaths[action.FilePath] // client sends assetID as FilePath in proto
-	if !ok {
-		// Fallback: maybe the client sent the actual path?
-		filePath = action.FilePath
-	}
-	s.assetPathsMu.RUnlock()
-
-	// Determine KDL path
-	// If filePath is /foo/bar.md, kdl is /foo/bar.kdl
-	ext := filepath.Ext(filePath)
-	kdlPath := strings.TrimSuffix(filePath, ext) + ".kdl"
-	
-	ann := kdl.Annotation{
-		ID:          action.Data.Id,
-		ContextHash: action.Data.ContextHash,
-		TargetText:  action.Data.TargetText,
-		Body:        action.Data.Body,
-		Timestamp:   action.Data.Timestamp,
-	}
-
-	if err := kdl.Append(kdlPath, ann); err != nil {
-		log.Printf("Failed to save annotation to %s: %v", kdlPath, err)
+// Broadcast sends env to every connected client and records it in the
+// outbox according to the retention configured for its payload type. It
+// returns the number of clients it was sent to.
+func (s *Server) Broadcast(env *pb.Envelope) int {
+	return len(s.deliver(env))
+}
+
+// deliver is Broadcast, returning the clients that env (or a downgraded
+// form of it) was sent to.
+func (s *Server) deliver(env *pb.Envelope) []*client {
+	return s.deliverTo(env, nil)
+}
+
+// deliverTo sends env to targets, or to every connected client when targets
+// is nil, and returns the clients it was sent to. Targeted envelopes are only
+// replayed to the same devices.
+func (s *Server) deliverTo(env *pb.Envelope, targets []*client) []*client {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	var targetIDs []string
+	if targets == nil {
+		targets = make([]*client, 0, len(s.clients))
+		for _, c := range s.clients {
+			targets = append(targets, c)
+		}
 	} else {
-		log.Printf("Saved annotation to %s", kdlPath)
+		for _, c := range targets {
+			targetIDs = append(targetIDs, c.id())
+		}
+	}
+
+	var sent []*client
+	for _, c := range targets {
+		if _, ok := adapt(c.capabilities(), env); ok {
+			sent = append(sent, c)
+		}
+	}
+
+	kind := payloadKind(env)
+	if err := s.outbox.Record(env, kind, s.retention[kind], len(sent) > 0, targetIDs); err != nil {
+		log.Printf("Outbox error: %v", err)
+	}
+
+	for _, c := range sent {
+		out, _ := adapt(c.capabilities(), env)
+		go c.send(out)
+	}
+	return sent
+}
+
+// handleResume replays retained envelopes the client has not seen yet.
+func (s *Server) handleResume(c *client, r *pb.Resume) {
+	c.startSession()
+	s.beginSession(c)
+
+	caps := c.capabilities()
+	for _, env := range s.outbox.Since(r.LastSeq, c.id()) {
+		if c.wasReplayed(env.Seq) {
+			continue
+		}
+		if out, ok := adapt(caps, env); ok {
+			c.send(out)
+		}
+	}
+}
+
+// payloadKind returns the oneof field name of env's payload, e.g.
+// "open_view". It is used as the key for outbox retention.
+func payloadKind(env *pb.Envelope) string {
+	m := env.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("payload"))
+	if fd == nil {
+		return ""
 	}
+	return string(fd.Name())
 }
 
-func (s *Server) Broadcast(env *pb.Envelope) {
+func (s *Server) snapshotClients() []*client {
 	s.clientsMu.Lock()
 	defer s.clientsMu.Unlock()
 
-	for conn := range s.clients {
-		go s.SendMessage(conn, env)
+	out := make([]*client, 0, len(s.clients))
+	for _, c := range s.clients {
+		out = append(out, c)
 	}
+	return out
 }
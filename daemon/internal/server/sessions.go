@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zelland/daemon/internal/zellij"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// sessionPollInterval is how often the Zellij socket directory is checked
+// for sessions appearing or disappearing.
+const sessionPollInterval = 2 * time.Second
+
+// watchSessions pushes SessionEvents whenever the set of sessions changes.
+func (s *Server) watchSessions() {
+	ctx := context.Background()
+	zellij.Watch(ctx, zellij.SocketDir(), sessionPollInterval, func() {
+		if _, err := s.refreshSessions(ctx); err != nil {
+			log.Printf("Failed to list zellij sessions: %v", err)
+		}
+	})
+}
+
+// refreshSessions lists sessions, pushes events for anything that changed
+// since the last listing and returns the new list.
+func (s *Server) refreshSessions(ctx context.Context) ([]zellij.Session, error) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	sessions, err := s.zellijCLI.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := zellij.Diff(s.sessions, sessions)
+	s.sessions = sessions
+
+	for _, ch := range changes {
+		env := &pb.Envelope{
+			Payload: &pb.Envelope_SessionEvent{
+				SessionEvent: &pb.SessionEvent{
+					Type:    sessionEventType(ch.Type),
+					Session: sessionProto(ch.Session),
+				},
+			},
+		}
+		for _, c := range s.snapshotClients() {
+			if c.token != "" && c.capabilities().has("sessions") {
+				s.send(c, env)
+			}
+		}
+	}
+	return sessions, nil
+}
+
+// handleSessionRequest runs a SessionRequest and replies with the session
+// list. It runs on its own goroutine since zellij commands can be slow.
+func (s *Server) handleSessionRequest(c *client, requestID string, req *pb.SessionRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), zellijTimeout)
+	defer cancel()
+
+	resp := &pb.SessionResponse{}
+	defer func() {
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_SessionResponse{SessionResponse: resp},
+		})
+	}()
+
+	if err := requireAuth(c); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	if err := s.sessionAction(ctx, req); err != nil {
+		log.Printf("Session %s %q from %s: %v", req.Action, req.Name, c.name(), err)
+		resp.Error = err.Error()
+	}
+
+	sessions, err := s.refreshSessions(ctx)
+	if err != nil {
+		if resp.Error == "" {
+			resp.Error = err.Error()
+		}
+		return
+	}
+	for _, sess := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionProto(sess))
+	}
+}
+
+func (s *Server) sessionAction(ctx context.Context, req *pb.SessionRequest) error {
+	switch req.Action {
+	case pb.SessionRequest_LIST:
+		return nil
+	case pb.SessionRequest_CREATE:
+		return s.zellijCLI.CreateSession(ctx, req.Name, req.Layout)
+	case pb.SessionRequest_RENAME:
+		return s.zellijCLI.RenameSession(ctx, req.Name, req.NewName)
+	case pb.SessionRequest_KILL:
+		return s.zellijCLI.KillSession(ctx, req.Name)
+	case pb.SessionRequest_DELETE:
+		return s.zellijCLI.DeleteSession(ctx, req.Name, req.Force)
+	}
+	return fmt.Errorf("unknown action %v", req.Action)
+}
+
+func sessionProto(sess zellij.Session) *pb.ZellijSession {
+	p := &pb.ZellijSession{
+		Name:            sess.Name,
+		AttachedClients: uint32(sess.AttachedClients),
+		Exited:          sess.Exited,
+	}
+	if !sess.CreatedAt.IsZero() {
+		p.CreatedAt = sess.CreatedAt.Unix()
+	}
+	return p
+}
+
+func sessionEventType(t zellij.ChangeType) pb.SessionEvent_Type {
+	switch t {
+	case zellij.SessionRemoved:
+		return pb.SessionEvent_REMOVED
+	case zellij.SessionExited:
+		return pb.SessionEvent_EXITED
+	}
+	return pb.SessionEvent_ADDED
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	"github.com/zelland/daemon/internal/logtail"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// Finished streams stay available for late joiners this long.
+const streamRetention = 30 * time.Minute
+
+// handleTriggerTail follows a file on the host for as long as the CLI keeps
+// the request open.
+func (s *Server) handleTriggerTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ShowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := os.Stat(req.FilePath); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to access file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	st := s.newStream(req.Title)
+	defer st.Close()
+
+	s.openLogView(st, targets, req.Origin)
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Streaming %s (ID: %s)\n", req.FilePath, st.ID)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	if err := st.Follow(r.Context(), req.FilePath); err != nil {
+		log.Printf("Failed to follow %s: %v", req.FilePath, err)
+	}
+}
+
+// handleTriggerTailStdin streams the request body (the CLI's stdin) into a
+// log view until EOF.
+func (s *Server) handleTriggerTailStdin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	title := r.URL.Query().Get("title")
+	if title == "" {
+		title = "stdin"
+	}
+
+	q := r.URL.Query()
+	origin := Origin{ZellijSession: q.Get("zellij_session"), ZellijPaneID: q.Get("zellij_pane_id")}
+	targets, err := s.route(q.Get("to"), origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	st := s.newStream(title)
+	s.openLogView(st, targets, origin)
+
+	err = st.ReadFrom(r.Context(), r.Body)
+	st.Close()
+	if err != nil {
+		log.Printf("Log stream %s ended with error: %v", st.ID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Streamed %s (ID: %s)", title, st.ID)
+}
+
+// handleLogSnapshot serves the buffered lines of a stream as plain text, for
+// clients that fetch the view URL instead of subscribing.
+func (s *Server) handleLogSnapshot(w http.ResponseWriter, r *http.Request) {
+	st, ok := s.lookupStream(strings.TrimPrefix(r.URL.Path, "/logs/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range st.Since(0) {
+		fmt.Fprintln(w, line.Text)
+	}
+}
+
+func (s *Server) newStream(title string) *logtail.Stream {
+	st := logtail.NewStream(assets.NewID(), title, logtail.DefaultCapacity, s.publishLog)
+
+	s.streamsMu.Lock()
+	s.streams[st.ID] = st
+	s.streamsMu.Unlock()
+
+	return st
+}
+
+func (s *Server) lookupStream(id string) (*logtail.Stream, bool) {
+	s.streamsMu.RLock()
+	defer s.streamsMu.RUnlock()
+	st, ok := s.streams[id]
+	return st, ok
+}
+
+func (s *Server) openLogView(st *logtail.Stream, targets []*client, origin Origin) {
+	s.deliverTo(&pb.Envelope{
+		RequestId: assets.NewID(),
+		Payload: &pb.Envelope_OpenView{
+			OpenView: &pb.OpenViewRequest{
+				AssetId:  st.ID,
+				Url:      "/logs/" + st.ID,
+				FileType: pb.OpenViewRequest_LOG,
+				Title:    st.Title,
+				Origin:   origin.proto(),
+			},
+		},
+	}, targets)
+}
+
+// publishLog fans a stream update out to every subscribed client, applying
+// each client's filter.
+func (s *Server) publishLog(st *logtail.Stream, u logtail.Update) {
+	if u.Event == logtail.EventEOF {
+		time.AfterFunc(streamRetention, func() {
+			s.streamsMu.Lock()
+			delete(s.streams, st.ID)
+			s.streamsMu.Unlock()
+		})
+	}
+
+	for _, c := range s.snapshotClients() {
+		filter, ok := c.logFilter(st.ID)
+		if !ok {
+			continue
+		}
+		chunk := newLogChunk(st.ID, u.Lines, filter, u.Event)
+		if len(chunk.Lines) == 0 && chunk.Event == pb.LogChunk_NONE {
+			continue
+		}
+		s.send(c, &pb.Envelope{Payload: &pb.Envelope_Log{Log: chunk}})
+	}
+}
+
+func (s *Server) handleLogSubscribe(c *client, sub *pb.LogSubscribe) {
+	st, ok := s.lookupStream(sub.StreamId)
+	if !ok {
+		log.Printf("Subscribe to unknown log stream %s", sub.StreamId)
+		return
+	}
+
+	if sub.Unsubscribe {
+		c.unsubscribeLog(st.ID)
+		return
+	}
+
+	var filter *regexp.Regexp
+	if sub.Filter != "" {
+		var err error
+		if filter, err = regexp.Compile(sub.Filter); err != nil {
+			log.Printf("Invalid log filter %q: %v", sub.Filter, err)
+			return
+		}
+	}
+
+	// Subscribe before replaying so no line falls between the two; clients
+	// drop lines whose seq they have already seen.
+	c.subscribeLog(st.ID, filter)
+
+	ev := logtail.EventNone
+	if st.Closed() {
+		ev = logtail.EventEOF
+	}
+	chunk := newLogChunk(st.ID, st.Since(sub.AfterSeq), filter, ev)
+	s.send(c, &pb.Envelope{Payload: &pb.Envelope_Log{Log: chunk}})
+}
+
+func newLogChunk(streamID string, lines []logtail.Line, filter *regexp.Regexp, ev logtail.Event) *pb.LogChunk {
+	chunk := &pb.LogChunk{
+		StreamId: streamID,
+		Event:    logEvent(ev),
+	}
+	for _, l := range lines {
+		if filter != nil && !filter.MatchString(l.Text) {
+			continue
+		}
+		chunk.Lines = append(chunk.Lines, &pb.LogLine{Seq: l.Seq, Text: l.Text})
+	}
+	return chunk
+}
+
+func logEvent(ev logtail.Event) pb.LogChunk_Event {
+	switch ev {
+	case logtail.EventTruncated:
+		return pb.LogChunk_TRUNCATED
+	case logtail.EventRotated:
+		return pb.LogChunk_ROTATED
+	case logtail.EventEOF:
+		return pb.LogChunk_EOF
+	default:
+		return pb.LogChunk_NONE
+	}
+}
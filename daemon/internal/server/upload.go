@@ -0,0 +1,329 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/assets"
+	"github.com/zelland/daemon/internal/upload"
+	pb "github.com/zelland/daemon/proto"
+)
+
+// CreateUpload is the body of POST /api/v1/uploads.
+type CreateUpload struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Send the file to a waiting `zelland receive` instead of the inbox
+	ReceiveID string `json:"receive_id,omitempty"`
+}
+
+// UploadStatus describes an upload in replies to the upload endpoints.
+type UploadStatus struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+	// Largest chunk the daemon accepts
+	ChunkBytes int64 `json:"chunk_bytes"`
+	Done       bool  `json:"done,omitempty"`
+	// Where the file was saved, once done
+	Path string `json:"path,omitempty"`
+}
+
+// ReceiveRequest is the body of /api/v1/trigger/receive.
+type ReceiveRequest struct {
+	// Absolute directory to save into; empty means the inbox
+	Dir string `json:"dir,omitempty"`
+	// How long to wait for an upload; zero waits until the CLI gives up
+	TimeoutMs int64  `json:"timeout_ms,omitempty"`
+	To        string `json:"to,omitempty"`
+	Origin
+}
+
+// ReceiveResponse is the reply to a ReceiveRequest.
+type ReceiveResponse struct {
+	Path     string `json:"path,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	From     string `json:"from,omitempty"`
+	TimedOut bool   `json:"timed_out,omitempty"`
+}
+
+// receiver is a `zelland receive` waiting for an upload.
+type receiver struct {
+	dir  string
+	done chan ReceiveResponse
+}
+
+// handleUploads serves /api/v1/uploads (create) and /api/v1/uploads/{id}
+// (GET status, POST a chunk, DELETE). Uploads write to the host, so they
+// require a token even though the rest of the HTTP API does not.
+func (s *Server) handleUploads(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.authenticate(r)
+	if !ok || owner == "" {
+		http.Error(w, errUnauthenticated.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/uploads"), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.createUpload(w, r, owner)
+		return
+	}
+
+	u, offset, err := s.uploads.Get(id)
+	if err == nil && u.Owner != owner {
+		// Don't reveal other clients' uploads
+		err = upload.ErrNotFound
+	}
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeUploadStatus(w, http.StatusOK, u, offset, "")
+	case http.MethodPost:
+		s.writeChunk(w, r, u)
+	case http.MethodDelete:
+		if err := s.uploads.Cancel(u.ID); err != nil {
+			uploadError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request, owner string) {
+	var req CreateUpload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dir := ""
+	if req.ReceiveID != "" {
+		s.receiversMu.Lock()
+		rc, ok := s.receivers[req.ReceiveID]
+		s.receiversMu.Unlock()
+		if !ok {
+			http.Error(w, "zelland receive is no longer waiting", http.StatusNotFound)
+			return
+		}
+		dir = rc.dir
+	}
+
+	u, err := s.uploads.Create(req.Name, req.Size, req.SHA256, dir, owner, req.ReceiveID)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	log.Printf("Upload %s of %s (%d bytes) started by %s", u.ID, u.Name, u.Size, owner)
+	writeUploadStatus(w, http.StatusCreated, u, 0, "")
+}
+
+// writeChunk reads a multipart body with "offset" and optional "sha256"
+// fields followed by the "chunk" file part.
+func (s *Server) writeChunk(w http.ResponseWriter, r *http.Request, u *upload.Upload) {
+	// Leave room for the multipart framing and fields
+	r.Body = http.MaxBytesReader(w, r.Body, upload.MaxChunk+64*1024)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offset := int64(-1)
+	sum := ""
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			http.Error(w, "missing chunk part", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+
+		switch part.FormName() {
+		case "offset":
+			v, _ := io.ReadAll(io.LimitReader(part, 32))
+			if offset, err = strconv.ParseInt(string(v), 10, 64); err != nil {
+				http.Error(w, "invalid offset", http.StatusBadRequest)
+				return
+			}
+		case "sha256":
+			v, _ := io.ReadAll(io.LimitReader(part, 128))
+			sum = string(v)
+		case "chunk":
+			if offset < 0 {
+				http.Error(w, "offset must come before the chunk", http.StatusBadRequest)
+				return
+			}
+			next, path, err := s.uploads.Write(u.ID, offset, part, sum)
+			if err != nil {
+				uploadError(w, err)
+				return
+			}
+			if path != "" {
+				s.uploadDone(u, path)
+			}
+			writeUploadStatus(w, http.StatusOK, u, next, path)
+			return
+		}
+	}
+}
+
+// uploadDone reports a finished upload to the `zelland receive` waiting
+// for it, if any.
+func (s *Server) uploadDone(u *upload.Upload, path string) {
+	log.Printf("Received %s (%d bytes) from %s", path, u.Size, u.Owner)
+	if u.Tag == "" {
+		return
+	}
+
+	s.receiversMu.Lock()
+	rc, ok := s.receivers[u.Tag]
+	s.receiversMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case rc.done <- ReceiveResponse{Path: path, Size: u.Size, From: u.Owner}:
+	default:
+		// Another upload already answered it
+	}
+}
+
+func writeUploadStatus(w http.ResponseWriter, code int, u *upload.Upload, offset int64, path string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(UploadStatus{
+		ID:         u.ID,
+		Name:       u.Name,
+		Size:       u.Size,
+		Offset:     offset,
+		ChunkBytes: upload.MaxChunk,
+		Done:       path != "",
+		Path:       path,
+	})
+}
+
+// uploadError maps upload errors to HTTP statuses.
+func uploadError(w http.ResponseWriter, err error) {
+	var offErr *upload.OffsetError
+	var tooLarge *http.MaxBytesError
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, upload.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.As(err, &offErr), errors.Is(err, upload.ErrBusy):
+		code = http.StatusConflict
+	case errors.Is(err, upload.ErrTooLarge), errors.As(err, &tooLarge):
+		code = http.StatusRequestEntityTooLarge
+	case errors.Is(err, upload.ErrQuota):
+		code = http.StatusInsufficientStorage
+	case errors.Is(err, upload.ErrChecksum):
+		code = http.StatusUnprocessableEntity
+	}
+	if code == http.StatusInternalServerError {
+		log.Printf("Upload failed: %v", err)
+	}
+	http.Error(w, err.Error(), code)
+}
+
+func (s *Server) handleTriggerReceive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dir := req.Dir
+	if dir == "" {
+		dir = s.uploads.Inbox()
+	} else if fi, err := os.Stat(dir); err != nil || !fi.IsDir() || !filepath.IsAbs(dir) {
+		http.Error(w, fmt.Sprintf("%s is not a directory", dir), http.StatusBadRequest)
+		return
+	}
+
+	targets, err := s.route(req.To, req.Origin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rr := &pb.ReceiveRequest{
+		Id:     assets.NewID(),
+		Dir:    dir,
+		Origin: req.Origin.proto(),
+	}
+	var timeout <-chan time.Time
+	if req.TimeoutMs > 0 {
+		d := time.Duration(req.TimeoutMs) * time.Millisecond
+		rr.ExpiresAt = time.Now().Add(d).Unix()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	rc := &receiver{dir: dir, done: make(chan ReceiveResponse, 1)}
+	s.receiversMu.Lock()
+	s.receivers[rr.Id] = rc
+	s.receiversMu.Unlock()
+	defer func() {
+		s.receiversMu.Lock()
+		delete(s.receivers, rr.Id)
+		s.receiversMu.Unlock()
+	}()
+
+	sent := s.deliverTo(&pb.Envelope{
+		RequestId: rr.Id,
+		Payload:   &pb.Envelope_ReceiveRequest{ReceiveRequest: rr},
+	}, targets)
+	if len(sent) == 0 {
+		http.Error(w, "No device connected that can upload files", http.StatusServiceUnavailable)
+		return
+	}
+
+	var resp ReceiveResponse
+	select {
+	case resp = <-rc.done:
+	case <-timeout:
+		resp.TimedOut = true
+	case <-r.Context().Done():
+		// The script was interrupted
+	}
+
+	// Take the file picker off every other screen
+	closed := &pb.Envelope{
+		Payload: &pb.Envelope_PromptClosed{PromptClosed: &pb.PromptClosed{Id: rr.Id}},
+	}
+	for _, c := range sent {
+		s.send(c, closed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
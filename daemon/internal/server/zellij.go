@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/zelland/daemon/proto"
+)
+
+// zellijTimeout bounds a single Zellij RPC, including stopping a server
+// that ignores SIGTERM.
+const zellijTimeout = 30 * time.Second
+
+// handleZellijWeb runs a ZellijWebRequest and replies with the server's
+// state. It runs on its own goroutine since starting and stopping can take
+// a few seconds.
+func (s *Server) handleZellijWeb(c *client, requestID string, req *pb.ZellijWebRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), zellijTimeout)
+	defer cancel()
+
+	resp := &pb.ZellijWebResponse{}
+	if err := requireAuth(c); err != nil {
+		resp.Error = err.Error()
+		s.reply(c, requestID, &pb.Envelope{
+			Payload: &pb.Envelope_ZellijWebResponse{ZellijWebResponse: resp},
+		})
+		return
+	}
+	if err := s.zellijWebAction(ctx, c, req, resp); err != nil {
+		log.Printf("zellij web %s from %s: %v", req.Action, c.name(), err)
+		resp.Error = err.Error()
+	}
+
+	web := s.zellijWeb
+	st := web.Status(ctx)
+	resp.Running = st.Running
+	resp.Supervised = st.Supervised
+	resp.Pid = int32(st.PID)
+	resp.Port = uint32(web.Port())
+	resp.Restarts = uint32(st.Restarts)
+	resp.LastExit = st.LastExit
+	if !st.StartedAt.IsZero() {
+		resp.StartedAt = st.StartedAt.Unix()
+	}
+	resp.LogStreamId = web.Logs().ID
+	if req.LogLines > 0 {
+		lines := web.Logs().Since(0)
+		if n := int(req.LogLines); len(lines) > n {
+			lines = lines[len(lines)-n:]
+		}
+		for _, l := range lines {
+			resp.LogLines = append(resp.LogLines, l.Text)
+		}
+	}
+
+	s.reply(c, requestID, &pb.Envelope{
+		Payload: &pb.Envelope_ZellijWebResponse{ZellijWebResponse: resp},
+	})
+}
+
+func (s *Server) zellijWebAction(ctx context.Context, c *client, req *pb.ZellijWebRequest, resp *pb.ZellijWebResponse) error {
+	web := s.zellijWeb
+	switch req.Action {
+	case pb.ZellijWebRequest_STATUS:
+		return nil
+	case pb.ZellijWebRequest_START:
+		return web.Start(ctx)
+	case pb.ZellijWebRequest_STOP:
+		return web.Stop(ctx)
+	case pb.ZellijWebRequest_RESTART:
+		return web.Restart(ctx)
+	case pb.ZellijWebRequest_CREATE_TOKEN:
+		name, token, err := web.CreateToken(ctx)
+		if err != nil {
+			return err
+		}
+		resp.TokenName = name
+		resp.Token = token
+		log.Printf("Created zellij web token %s for %s", name, c.name())
+		return nil
+	}
+	return fmt.Errorf("unknown action %v", req.Action)
+}
@@ -0,0 +1,373 @@
+// Package sidecar decides where the annotations of a file are kept, and
+// moves sidecars left elsewhere by other strategies or older versions.
+package sidecar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+// Storage strategies.
+const (
+	// notes.md.zelland.kdl next to notes.md
+	Sibling = "sibling"
+	// .zelland/notes.md.kdl in the directory of notes.md
+	Hidden = "hidden"
+	// <central dir>/<sha256 of the canonical path>.kdl
+	Central = "central"
+)
+
+// Sidecar is where the annotations of one file are kept.
+type Sidecar struct {
+	Path string
+	// The annotated file, for sidecars whose path does not tell; recorded
+	// in the sidecar itself
+	Source string
+}
+
+// Locator maps files to their sidecars.
+type Locator struct {
+	strategy string
+	central  string
+	// Old-style sidecars already reported as shared by several files
+	shared sync.Map
+}
+
+// New returns a Locator for strategy. centralDir holds central sidecars,
+// and those of files in directories that cannot be written.
+func New(strategy, centralDir string) (*Locator, error) {
+	switch strategy {
+	case "":
+		strategy = Sibling
+	case Sibling, Hidden, Central:
+	default:
+		return nil, fmt.Errorf("unknown annotation storage %q (want %s, %s or %s)", strategy, Sibling, Hidden, Central)
+	}
+	if centralDir == "" || !filepath.IsAbs(centralDir) {
+		return nil, fmt.Errorf("annotation data dir %q is not an absolute path", centralDir)
+	}
+	return &Locator{strategy: strategy, central: centralDir}, nil
+}
+
+// Strategy returns the configured strategy.
+func (l *Locator) Strategy() string {
+	return l.strategy
+}
+
+// Canonical returns the absolute path of file with symlinks resolved, so a
+// file has one sidecar however it is named.
+func Canonical(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real, nil
+	}
+	return abs, nil
+}
+
+// path returns the sidecar of the canonical file under a strategy.
+func (l *Locator) path(file, strategy string) Sidecar {
+	dir, base := filepath.Split(file)
+	switch strategy {
+	case Hidden:
+		return Sidecar{Path: filepath.Join(dir, ".zelland", base+".kdl")}
+	case Central:
+		sum := sha256.Sum256([]byte(file))
+		return Sidecar{Path: filepath.Join(l.central, hex.EncodeToString(sum[:])+".kdl"), Source: file}
+	default:
+		return Sidecar{Path: file + ".zelland.kdl"}
+	}
+}
+
+// Locate returns the sidecar to write the annotations of file to. A
+// sidecar found where another strategy or an older version put it is moved
+// to the configured place first. When that place turns out not to be
+// writable, the central store is used instead; writers should do the same
+// when writing fails with an error Unwritable reports, see Central.
+func (l *Locator) Locate(file string) (Sidecar, error) {
+	canon, err := Canonical(file)
+	if err != nil {
+		return Sidecar{}, err
+	}
+
+	want := l.path(canon, l.strategy)
+	err = l.gather(canon, want)
+	if Unwritable(err) && l.strategy != Central {
+		want = l.path(canon, Central)
+		err = l.gather(canon, want)
+	}
+	if err != nil {
+		return Sidecar{}, err
+	}
+	return want, nil
+}
+
+// Central returns the central sidecar of file, for files in directories
+// that cannot be written, with any sidecars left elsewhere moved into it.
+func (l *Locator) Central(file string) (Sidecar, error) {
+	canon, err := Canonical(file)
+	if err != nil {
+		return Sidecar{}, err
+	}
+	want := l.path(canon, Central)
+	if err := l.gather(canon, want); err != nil {
+		return Sidecar{}, err
+	}
+	return want, nil
+}
+
+// gather moves the sidecars of file left elsewhere into want, unless want
+// exists already.
+func (l *Locator) gather(file string, want Sidecar) error {
+	if exists(want.Path) {
+		return nil
+	}
+	for _, old := range l.previous(file) {
+		if old.Path == want.Path {
+			continue
+		}
+		if err := migrate(old.Path, want); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unwritable reports whether err means a sidecar could not be written where
+// it was tried, so it belongs in the central store.
+func Unwritable(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// Find returns the sidecar holding the annotations of file without moving
+// anything: the one where Locate would put it if that exists, else one
+// left by another strategy or an older version. Use it to read; Locate
+// before writing.
+func (l *Locator) Find(file string) (Sidecar, error) {
+	canon, err := Canonical(file)
+	if err != nil {
+		return Sidecar{}, err
+	}
+
+	want := l.path(canon, l.strategy)
+	if exists(want.Path) {
+		return want, nil
+	}
+	if central := l.path(canon, Central); exists(central.Path) {
+		return central, nil
+	}
+	for _, old := range l.previous(canon) {
+		if anns, err := kdl.Load(old.Path); err == nil && len(anns) > 0 {
+			return old, nil
+		}
+	}
+	return want, nil
+}
+
+// previous returns the places a sidecar of file may have been left: where
+// the other strategies put it, then where older versions did.
+func (l *Locator) previous(file string) []Sidecar {
+	var out []Sidecar
+	for _, s := range []string{Sibling, Hidden, Central} {
+		out = append(out, l.path(file, s))
+	}
+	// Before storage was configurable: notes.md -> notes.kdl, main.go ->
+	// main.kdl, and briefly photo.png -> photo.png.kdl. migrate leaves
+	// files of those names alone unless they hold annotations.
+	ext := filepath.Ext(file)
+	if ext == "" {
+		return out
+	}
+	if stem := strings.TrimSuffix(file, ext) + ".kdl"; stem != file && exists(stem) {
+		// notes.kdl next to both notes.md and notes.txt may belong to
+		// either, so which one is left to the user
+		if others := namesakes(file); len(others) > 0 {
+			l.reportShared(stem, file, others)
+		} else {
+			out = append(out, Sidecar{Path: stem})
+		}
+	}
+	switch strings.ToLower(ext) {
+	case ".md", ".markdown":
+	default:
+		out = append(out, Sidecar{Path: file + ".kdl"})
+	}
+	return out
+}
+
+// namesakes returns the other files next to file with the same name up to
+// the extension, whose old-style sidecar would have had the same name.
+func namesakes(file string) []string {
+	dir, base := filepath.Split(file)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		ext := filepath.Ext(name)
+		if e.IsDir() || name == base || ext == "" || ext == ".kdl" || strings.TrimSuffix(name, ext) != stem {
+			continue
+		}
+		out = append(out, filepath.Join(dir, name))
+	}
+	return out
+}
+
+// reportShared logs once that the old-style sidecar stem is not moved
+// because it may belong to file or any of others.
+func (l *Locator) reportShared(stem, file string, others []string) {
+	if _, done := l.shared.LoadOrStore(stem, true); done {
+		return
+	}
+	if anns, err := kdl.Load(stem); err != nil || len(anns) == 0 {
+		return
+	}
+	log.Printf("Not moving the annotations in %s: they may belong to %s or %s. Rename it to <file>.zelland.kdl next to the right one.",
+		stem, file, strings.Join(others, " or "))
+}
+
+// migrate moves the annotations in from, if it is a sidecar, into to,
+// merging them with any already there. Files that do not parse as
+// annotations, like a real config.kdl, are left alone.
+func migrate(from string, to Sidecar) error {
+	anns, err := kdl.Load(from)
+	if err != nil || len(anns) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to.Path), 0755); err != nil {
+		return err
+	}
+	err = kdl.Modify(to.Path, to.Source, func(existing []kdl.Annotation) ([]kdl.Annotation, error) {
+		for _, a := range anns {
+			if kdl.FindThread(existing, a.ID) < 0 {
+				existing = append(existing, a)
+			}
+		}
+		return existing, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", from, err)
+	}
+	log.Printf("Moved %d annotation(s) from %s to %s", len(anns), from, to.Path)
+	if err := os.Remove(from); err != nil {
+		// Merging again later is harmless; IDs already present are skipped
+		log.Printf("Failed to remove %s: %v", from, err)
+	}
+	return nil
+}
+
+// Sources returns the files at or under root that may have sidecars: those
+// named by sidecars of any strategy or older version found under root, and
+// those central sidecars record. Files that no longer exist are included,
+// and so are files whose old-style sidecar turns out not to be one or to be
+// shared with a namesake; Locate ignores those.
+func (l *Locator) Sources(root string) ([]string, error) {
+	root, err := Canonical(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	found := make(map[string]bool)
+	// Files by directory, and old-style sidecars to match against them
+	names := make(map[string][]string)
+	var legacy []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories hold no sidecars we could read either
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		name := d.Name()
+		dir := filepath.Dir(path)
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(name, ".") && name != ".zelland" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		names[dir] = append(names[dir], name)
+		if !strings.HasSuffix(name, ".kdl") {
+			return nil
+		}
+		switch {
+		case filepath.Base(dir) == ".zelland":
+			found[filepath.Join(filepath.Dir(dir), strings.TrimSuffix(name, ".kdl"))] = true
+		case strings.HasSuffix(name, ".zelland.kdl"):
+			found[strings.TrimSuffix(path, ".zelland.kdl")] = true
+		default:
+			legacy = append(legacy, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range legacy {
+		// photo.png.kdl of photo.png, notes.kdl of notes.md or notes.txt
+		dir, name := filepath.Split(path)
+		stem := strings.TrimSuffix(name, ".kdl")
+		for _, candidate := range names[filepath.Clean(dir)] {
+			if candidate == name || filepath.Ext(candidate) == "" {
+				continue
+			}
+			if candidate == stem || strings.TrimSuffix(candidate, filepath.Ext(candidate)) == stem {
+				found[filepath.Join(dir, candidate)] = true
+			}
+		}
+	}
+
+	central, err := os.ReadDir(l.central)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range central {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".kdl") {
+			continue
+		}
+		doc, err := kdl.LoadFile(filepath.Join(l.central, e.Name()))
+		if err != nil || doc.Source == "" {
+			continue
+		}
+		if doc.Source == root || strings.HasPrefix(doc.Source, root+string(filepath.Separator)) {
+			found[doc.Source] = true
+		}
+	}
+
+	files := make([]string, 0, len(found))
+	for f := range found {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,292 @@
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zelland/daemon/internal/kdl"
+)
+
+func setup(t *testing.T) (dir, central string) {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	central = filepath.Join(dir, "central")
+	return dir, central
+}
+
+func TestStrategies(t *testing.T) {
+	dir, central := setup(t)
+	file := filepath.Join(dir, "notes.md")
+	os.WriteFile(file, []byte("# Notes\n"), 0644)
+
+	for strategy, want := range map[string]string{
+		Sibling: filepath.Join(dir, "notes.md.zelland.kdl"),
+		Hidden:  filepath.Join(dir, ".zelland", "notes.md.kdl"),
+	} {
+		l, err := New(strategy, central)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc, err := l.Locate(file)
+		if err != nil || sc.Path != want || sc.Source != "" {
+			t.Errorf("%s: Locate = %+v, %v; want %s", strategy, sc, err, want)
+		}
+	}
+
+	l, _ := New(Central, central)
+	sc, err := l.Locate(file)
+	if err != nil || filepath.Dir(sc.Path) != central || sc.Source != file {
+		t.Errorf("central: Locate = %+v, %v", sc, err)
+	}
+	// Symlinks share the sidecar of their target
+	link := filepath.Join(dir, "link.md")
+	os.Symlink(file, link)
+	if linked, _ := l.Locate(link); linked != sc {
+		t.Errorf("central: Locate(link) = %+v, want %+v", linked, sc)
+	}
+
+	if _, err := New("elsewhere", central); err == nil {
+		t.Error("New accepted an unknown strategy")
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	dir, central := setup(t)
+	file := filepath.Join(dir, "notes.md")
+	os.WriteFile(file, []byte("# Notes\n"), 0644)
+	legacy := filepath.Join(dir, "notes.kdl")
+	if err := kdl.Save(legacy, []kdl.Annotation{{ID: "a1", Body: "Old"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	l, _ := New(Hidden, central)
+	sc, err := l.Locate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anns, err := kdl.Load(sc.Path)
+	if err != nil || len(anns) != 1 || anns[0].Body != "Old" {
+		t.Fatalf("Migrated sidecar has %+v, %v", anns, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("Legacy sidecar was not removed: %v", err)
+	}
+
+	// Switching strategy moves the sidecar again, source and all
+	l, _ = New(Central, central)
+	moved, err := l.Locate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := kdl.LoadFile(moved.Path)
+	if err != nil || len(doc.Annotations) != 1 || doc.Source != file {
+		t.Fatalf("Central sidecar has %+v, %v", doc, err)
+	}
+	if _, err := os.Stat(sc.Path); !os.IsNotExist(err) {
+		t.Errorf("Hidden sidecar was not removed: %v", err)
+	}
+}
+
+func TestRealKDLFileLeftAlone(t *testing.T) {
+	dir, central := setup(t)
+	file := filepath.Join(dir, "config.md")
+	os.WriteFile(file, []byte("# Config\n"), 0644)
+	// Shares the name a legacy sidecar of config.md would have had
+	real := filepath.Join(dir, "config.kdl")
+	content := "server {\n\tport 8080\n}\n"
+	os.WriteFile(real, []byte(content), 0644)
+
+	l, _ := New(Sibling, central)
+	sc, err := l.Locate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Path == real {
+		t.Fatal("Locate chose the real config.kdl")
+	}
+	if data, _ := os.ReadFile(real); string(data) != content {
+		t.Errorf("config.kdl was changed to %q", data)
+	}
+
+	// Annotating config.kdl itself doesn't touch it either
+	sc, _ = l.Locate(real)
+	if !strings.HasSuffix(sc.Path, "config.kdl.zelland.kdl") {
+		t.Errorf("Locate(config.kdl) = %s", sc.Path)
+	}
+}
+
+func TestReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir, central := setup(t)
+	ro := filepath.Join(dir, "ro")
+	os.Mkdir(ro, 0755)
+	file := filepath.Join(ro, "notes.md")
+	os.WriteFile(file, []byte("# Notes\n"), 0644)
+	os.Chmod(ro, 0555)
+	defer os.Chmod(ro, 0755)
+
+	// Nothing is written to find out
+	l, _ := New(Sibling, central)
+	sc, err := l.Locate(file)
+	if err != nil || sc.Path != file+".zelland.kdl" {
+		t.Errorf("Locate in a read-only directory = %+v, %v", sc, err)
+	}
+	if entries, _ := os.ReadDir(ro); len(entries) != 1 {
+		t.Errorf("Expected only notes.md in the directory, found %d files", len(entries))
+	}
+
+	sc, err = l.Central(file)
+	if err != nil || filepath.Dir(sc.Path) != central || sc.Source != file {
+		t.Fatalf("Central = %+v, %v", sc, err)
+	}
+	os.MkdirAll(central, 0755)
+	kdl.SaveFile(sc.Path, &kdl.KDLFile{Source: file, Annotations: []kdl.Annotation{{ID: "a1"}}})
+	// Once there, the central sidecar stays in use
+	if again, err := l.Locate(file); err != nil || again != sc {
+		t.Errorf("Locate after falling back = %+v, %v; want %+v", again, err, sc)
+	}
+}
+
+func TestMigrateStrippedExtension(t *testing.T) {
+	dir, central := setup(t)
+	l, _ := New(Sibling, central)
+	for _, name := range []string{"notes.txt", "main.go"} {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, nil, 0644)
+		legacy := filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))+".kdl")
+		kdl.Save(legacy, []kdl.Annotation{{ID: name}})
+
+		sc, err := l.Locate(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anns, err := kdl.Load(sc.Path)
+		if err != nil || len(anns) != 1 || anns[0].ID != name {
+			t.Errorf("%s: migrated sidecar has %+v, %v", name, anns, err)
+		}
+		if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+			t.Errorf("%s: legacy sidecar was not removed: %v", name, err)
+		}
+	}
+}
+
+func TestSharedLegacyLeftAlone(t *testing.T) {
+	dir, central := setup(t)
+	md := filepath.Join(dir, "notes.md")
+	txt := filepath.Join(dir, "notes.txt")
+	os.WriteFile(md, []byte("# Notes\n"), 0644)
+	os.WriteFile(txt, nil, 0644)
+	// Could be the old sidecar of either file
+	legacy := filepath.Join(dir, "notes.kdl")
+	kdl.Save(legacy, []kdl.Annotation{{ID: "a1", Body: "Old"}})
+
+	l, _ := New(Sibling, central)
+	for _, file := range []string{md, txt} {
+		if sc, err := l.Find(file); err != nil || sc.Path != file+".zelland.kdl" {
+			t.Errorf("Find(%s) = %+v, %v", filepath.Base(file), sc, err)
+		}
+		sc, err := l.Locate(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if anns, _ := kdl.Load(sc.Path); len(anns) != 0 {
+			t.Errorf("%s was given the shared annotations: %+v", filepath.Base(file), anns)
+		}
+	}
+	if anns, err := kdl.Load(legacy); err != nil || len(anns) != 1 {
+		t.Errorf("Shared legacy sidecar was changed: %+v, %v", anns, err)
+	}
+
+	// Once the user picks a file, it is moved as usual
+	os.Remove(txt)
+	sc, err := l.Locate(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anns, _ := kdl.Load(sc.Path); len(anns) != 1 || anns[0].Body != "Old" {
+		t.Errorf("Migrated sidecar has %+v", anns)
+	}
+}
+
+func TestSources(t *testing.T) {
+	dir, central := setup(t)
+	docs := filepath.Join(dir, "docs")
+	os.MkdirAll(filepath.Join(docs, "deep", ".zelland"), 0755)
+	os.MkdirAll(filepath.Join(docs, ".git"), 0755)
+	for name, content := range map[string]string{
+		"a.md":                     "",
+		"a.md.zelland.kdl":         "",
+		"deep/b.md":                "",
+		"deep/.zelland/b.md.kdl":   "",
+		"legacy.md":                "",
+		"legacy.kdl":               "",
+		"config.kdl":               "server {\n}\n",
+		".git/c.md.zelland.kdl":    "",
+		"gone.md.zelland.kdl":      "",
+		"deep/photo.png":           "",
+		"deep/photo.png.kdl":       "",
+		"deep/main.go":             "",
+		"deep/main.kdl":            "",
+		"notes.txt":                "",
+		"notes.kdl":                "",
+		"deep/unrelated-notes.kdl": "",
+	} {
+		os.WriteFile(filepath.Join(docs, name), []byte(content), 0644)
+	}
+	elsewhere := filepath.Join(dir, "elsewhere.md")
+	os.WriteFile(elsewhere, nil, 0644)
+
+	l, _ := New(Central, central)
+	centralFile := filepath.Join(docs, "c.md")
+	os.WriteFile(centralFile, nil, 0644)
+	for _, f := range []string{centralFile, elsewhere} {
+		sc, _ := l.Locate(f)
+		os.MkdirAll(central, 0755)
+		kdl.SaveFile(sc.Path, &kdl.KDLFile{Source: sc.Source, Annotations: []kdl.Annotation{{ID: "x"}}})
+	}
+
+	files, err := l.Sources(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, f := range files {
+		r, _ := filepath.Rel(docs, f)
+		rel = append(rel, r)
+	}
+	want := "a.md c.md deep/b.md deep/main.go deep/photo.png gone.md legacy.md notes.txt"
+	if got := strings.Join(rel, " "); got != want {
+		t.Errorf("Sources = %s, want %s", got, want)
+	}
+}
+
+func TestFindDoesNotMove(t *testing.T) {
+	dir, central := setup(t)
+	file := filepath.Join(dir, "notes.md")
+	os.WriteFile(file, []byte("# Notes\n"), 0644)
+	hidden := filepath.Join(dir, ".zelland", "notes.md.kdl")
+	os.Mkdir(filepath.Dir(hidden), 0755)
+	kdl.Save(hidden, []kdl.Annotation{{ID: "a1"}})
+	// Not a sidecar, so never found
+	os.WriteFile(filepath.Join(dir, "notes.kdl"), []byte("server {\n}\n"), 0644)
+
+	l, _ := New(Sibling, central)
+	if sc, err := l.Find(file); err != nil || sc.Path != hidden {
+		t.Errorf("Find = %+v, %v; want %s", sc, err, hidden)
+	}
+	if _, err := os.Stat(hidden); err != nil {
+		t.Errorf("Find moved the sidecar: %v", err)
+	}
+
+	other := filepath.Join(dir, "other.md")
+	if sc, _ := l.Find(other); sc.Path != other+".zelland.kdl" {
+		t.Errorf("Find of an unannotated file = %s", sc.Path)
+	}
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/sidecar"
+)
+
+// KDLStore keeps each file's threads in a KDL sidecar placed by a
+// sidecar.Locator.
+type KDLStore struct {
+	locator *sidecar.Locator
+	mu      sync.Mutex
+}
+
+// NewKDL returns a store writing sidecars where locator puts them.
+func NewKDL(locator *sidecar.Locator) *KDLStore {
+	return &KDLStore{locator: locator}
+}
+
+func (s *KDLStore) Get(file, id string) (kdl.Annotation, error) {
+	anns, err := s.List(file)
+	if err != nil {
+		return kdl.Annotation{}, err
+	}
+	for _, a := range anns {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return kdl.Annotation{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+}
+
+// List reads the sidecar wherever it is; sidecars left elsewhere are only
+// moved by the next change.
+func (s *KDLStore) List(file string) ([]kdl.Annotation, error) {
+	sc, err := s.locator.Find(file)
+	if err != nil {
+		return nil, err
+	}
+	anns, err := kdl.Load(sc.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sc.Path, err)
+	}
+	return anns, nil
+}
+
+func (s *KDLStore) Put(file string, a kdl.Annotation) error {
+	return s.modify(file, func(anns []kdl.Annotation) ([]kdl.Annotation, error) {
+		for i := range anns {
+			if anns[i].ID == a.ID {
+				anns[i] = a
+				return anns, nil
+			}
+		}
+		return append(anns, a), nil
+	})
+}
+
+func (s *KDLStore) Delete(file, id string) error {
+	return s.modify(file, func(anns []kdl.Annotation) ([]kdl.Annotation, error) {
+		for i := range anns {
+			if anns[i].ID == id {
+				return append(anns[:i], anns[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+	})
+}
+
+func (s *KDLStore) modify(file string, fn func([]kdl.Annotation) ([]kdl.Annotation, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, err := s.locator.Locate(file)
+	if err != nil {
+		return err
+	}
+	err = write(sc, fn)
+	if sidecar.Unwritable(err) && sc.Source == "" {
+		// Only central sidecars record their source; this one is in the
+		// file's directory, which cannot be written
+		if sc, err = s.locator.Central(file); err != nil {
+			return err
+		}
+		err = write(sc, fn)
+	}
+	return err
+}
+
+func write(sc sidecar.Sidecar, fn func([]kdl.Annotation) ([]kdl.Annotation, error)) error {
+	if err := os.MkdirAll(filepath.Dir(sc.Path), 0755); err != nil {
+		return err
+	}
+	return kdl.Modify(sc.Path, sc.Source, fn)
+}
+
+func (s *KDLStore) Files(root string) ([]string, error) {
+	return s.locator.Sources(root)
+}
+
+// Watch polls the sidecar, so edits made to it by hand are seen too.
+func (s *KDLStore) Watch(ctx context.Context, file string) <-chan Event {
+	return poll(ctx, file, s.List)
+}
+
+func (s *KDLStore) Close() error {
+	return nil
+}
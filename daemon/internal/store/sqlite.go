@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/sidecar"
+)
+
+// Each thread is one row holding it as JSON, with its text indexed for
+// search under the same rowid.
+const schema = `
+CREATE TABLE IF NOT EXISTS annotations (
+	n    INTEGER PRIMARY KEY,
+	file TEXT NOT NULL,
+	id   TEXT NOT NULL,
+	data TEXT NOT NULL,
+	UNIQUE (file, id)
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS annotations_text USING fts4 (text);
+`
+
+// SQLiteStore keeps all threads in one SQLite database, and can search
+// them.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if !haveSQLite {
+		return nil, errors.New("the sqlite annotation backend needs a build with cgo (CGO_ENABLED=1); use the kdl backend or rebuild")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// Other processes, like the zelland CLI, may write while the daemon runs
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up %s: %w", path, err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Get(file, id string) (kdl.Annotation, error) {
+	canon, err := sidecar.Canonical(file)
+	if err != nil {
+		return kdl.Annotation{}, err
+	}
+	var data string
+	err = s.db.QueryRow(`SELECT data FROM annotations WHERE file = ? AND id = ?`, canon, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return kdl.Annotation{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return kdl.Annotation{}, err
+	}
+	var a kdl.Annotation
+	err = json.Unmarshal([]byte(data), &a)
+	return a, err
+}
+
+func (s *SQLiteStore) List(file string) ([]kdl.Annotation, error) {
+	canon, err := sidecar.Canonical(file)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT data FROM annotations WHERE file = ? ORDER BY n`, canon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	anns := []kdl.Annotation{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var a kdl.Annotation
+		if err := json.Unmarshal([]byte(data), &a); err != nil {
+			return nil, err
+		}
+		anns = append(anns, a)
+	}
+	return anns, rows.Err()
+}
+
+func (s *SQLiteStore) Put(file string, a kdl.Annotation) error {
+	canon, err := sidecar.Canonical(file)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Updating in place keeps the rowid, and with it the thread's position
+	var n int64
+	err = tx.QueryRow(`INSERT INTO annotations (file, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (file, id) DO UPDATE SET data = excluded.data
+		RETURNING n`, canon, a.ID, string(data)).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO annotations_text (docid, text) VALUES (?, ?)`, n, Text(a)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Delete(file, id string) error {
+	canon, err := sidecar.Canonical(file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int64
+	err = tx.QueryRow(`DELETE FROM annotations WHERE file = ? AND id = ? RETURNING n`, canon, id).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM annotations_text WHERE docid = ?`, n); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Files(root string) ([]string, error) {
+	canon, err := sidecar.Canonical(root)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSuffix(canon, string(filepath.Separator)) + string(filepath.Separator)
+	rows, err := s.db.Query(`SELECT DISTINCT file FROM annotations
+		WHERE file = ?1 OR substr(file, 1, length(?2)) = ?2
+		ORDER BY file`, canon, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []string
+	for rows.Next() {
+		var f string
+		if err := rows.Scan(&f); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// Search takes an FTS4 full-text query, like `typo OR spelling` or
+// `"quick fox"`, and matches it against the quoted text, body and replies
+// of every thread.
+func (s *SQLiteStore) Search(query string) ([]Match, error) {
+	rows, err := s.db.Query(`SELECT a.file, a.data, snippet(annotations_text, '[', ']', '…', -1, 12)
+		FROM annotations_text JOIN annotations a ON a.n = annotations_text.docid
+		WHERE annotations_text MATCH ?
+		ORDER BY a.file, a.n`, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var m Match
+		var data string
+		if err := rows.Scan(&m.File, &data, &m.Snippet); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &m.Annotation); err != nil {
+			return nil, err
+		}
+		m.Snippet = strings.Join(strings.Fields(m.Snippet), " ")
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// Watch polls the database, so threads written by other processes are
+// seen too.
+func (s *SQLiteStore) Watch(ctx context.Context, file string) <-chan Event {
+	return poll(ctx, file, s.List)
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,6 @@
+//go:build cgo
+
+package store
+
+// haveSQLite reports whether the SQLite driver was built; it needs cgo.
+const haveSQLite = true
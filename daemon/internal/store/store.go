@@ -0,0 +1,222 @@
+// Package store keeps annotation threads behind one interface, with KDL
+// sidecars and an SQLite database as backends.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/sidecar"
+)
+
+var ErrNotFound = errors.New("annotation not found")
+
+// AnnotationStore keeps the annotation threads of files. Files are named
+// by path; symlinks and relative paths name the same file as its canonical
+// path does.
+type AnnotationStore interface {
+	// Get returns the thread with the given ID on file.
+	Get(file, id string) (kdl.Annotation, error)
+	// List returns the threads on file, oldest first.
+	List(file string) ([]kdl.Annotation, error)
+	// Put adds a thread, or replaces the one with the same ID.
+	Put(file string, a kdl.Annotation) error
+	// Delete removes a thread and its replies.
+	Delete(file, id string) error
+	// Files returns the annotated files at or under root, a file or a
+	// directory. It may include files without annotations.
+	Files(root string) ([]string, error)
+	// Watch reports changes to the threads on file, including those made by
+	// other processes, until ctx is done.
+	Watch(ctx context.Context, file string) <-chan Event
+	Close() error
+}
+
+// Searcher is implemented by stores that can search all annotations.
+type Searcher interface {
+	// Search returns the threads whose text matches query, in the
+	// backend's query syntax.
+	Search(query string) ([]Match, error)
+}
+
+// Match is a thread found by Search.
+type Match struct {
+	File       string
+	Annotation kdl.Annotation
+	// Matching text with the matched terms in [brackets]
+	Snippet string
+}
+
+// EventType says what happened to a thread.
+type EventType int
+
+const (
+	Created EventType = iota
+	Updated
+	Deleted
+	// The annotations could not be read; Err says why
+	Failed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	case Deleted:
+		return "deleted"
+	}
+	return "failed"
+}
+
+// Event is a change seen by Watch.
+type Event struct {
+	Type EventType
+	File string
+	// The thread as it is now, or as it was before being deleted
+	Annotation kdl.Annotation
+	Err        error
+}
+
+// PollInterval is how often Watch looks for changes.
+var PollInterval = 2 * time.Second
+
+// poll implements Watch by listing the threads on file every PollInterval
+// and comparing them with the previous listing.
+func poll(ctx context.Context, file string, list func(string) ([]kdl.Annotation, error)) <-chan Event {
+	events := make(chan Event)
+	// Changes made once Watch returns are reported
+	known, err := list(file)
+	go func() {
+		defer close(events)
+		send := func(e Event) bool {
+			e.File = file
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		failed := err != nil
+		if failed && !send(Event{Type: Failed, Err: err}) {
+			return
+		}
+		ticker := time.NewTicker(PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := list(file)
+			if err != nil {
+				// Report a broken file once, and keep the last good state
+				if !failed && !send(Event{Type: Failed, Err: err}) {
+					return
+				}
+				failed = true
+				continue
+			}
+			failed = false
+			for _, e := range Diff(known, current) {
+				if !send(e) {
+					return
+				}
+			}
+			known = current
+		}
+	}()
+	return events
+}
+
+// Diff returns the events that turn the threads in before into those in
+// after.
+func Diff(before, after []kdl.Annotation) []Event {
+	old := make(map[string]kdl.Annotation, len(before))
+	for _, a := range before {
+		old[a.ID] = a
+	}
+
+	var events []Event
+	for _, a := range after {
+		prev, ok := old[a.ID]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: Created, Annotation: a})
+		case !Equal(prev, a):
+			events = append(events, Event{Type: Updated, Annotation: a})
+		}
+		delete(old, a.ID)
+	}
+	for _, a := range before {
+		if _, ok := old[a.ID]; ok {
+			events = append(events, Event{Type: Deleted, Annotation: a})
+		}
+	}
+	return events
+}
+
+// Text returns the text of a thread that searches look at: the quote, the
+// note and the replies.
+func Text(a kdl.Annotation) string {
+	var parts []string
+	for _, text := range []string{a.TargetText, a.Anchor.Quote, a.Body} {
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	for _, r := range a.Replies {
+		parts = append(parts, r.Body)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Equal reports whether two threads are the same, replies included.
+func Equal(a, b kdl.Annotation) bool {
+	if !slices.Equal(a.Replies, b.Replies) {
+		return false
+	}
+	a.Replies, b.Replies = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// Backends.
+const (
+	KDL    = "kdl"
+	SQLite = "sqlite"
+)
+
+// Open returns the store for a backend. storage and dataDir place KDL
+// sidecars as sidecar.New does; database is the SQLite file, by default
+// annotations.db in dataDir.
+func Open(backend, storage, dataDir, database string) (AnnotationStore, error) {
+	switch backend {
+	case "", KDL:
+		l, err := sidecar.New(storage, dataDir)
+		if err != nil {
+			return nil, err
+		}
+		return NewKDL(l), nil
+	case SQLite:
+		if database == "" {
+			if dataDir == "" || !filepath.IsAbs(dataDir) {
+				return nil, fmt.Errorf("annotation data dir %q is not an absolute path", dataDir)
+			}
+			database = filepath.Join(dataDir, "annotations.db")
+		}
+		return OpenSQLite(database)
+	}
+	return nil, fmt.Errorf("unknown annotation backend %q (want %s or %s)", backend, KDL, SQLite)
+}
@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zelland/daemon/internal/kdl"
+	"github.com/zelland/daemon/internal/sidecar"
+)
+
+// forEachBackend runs test against a fresh store of each kind, with a file
+// to annotate.
+func forEachBackend(t *testing.T, test func(t *testing.T, s AnnotationStore, file string)) {
+	backends := map[string]func(dir string) (AnnotationStore, error){
+		"kdl": func(dir string) (AnnotationStore, error) {
+			l, err := sidecar.New(sidecar.Sibling, filepath.Join(dir, "central"))
+			if err != nil {
+				return nil, err
+			}
+			return NewKDL(l), nil
+		},
+		"sqlite": func(dir string) (AnnotationStore, error) {
+			return OpenSQLite(filepath.Join(dir, "db", "annotations.db"))
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			if name == SQLite && !haveSQLite {
+				t.Skip("built without cgo")
+			}
+			dir, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			file := filepath.Join(dir, "notes.md")
+			os.WriteFile(file, []byte("# Notes\n\nHello world\n"), 0644)
+			s, err := open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			test(t, s, file)
+		})
+	}
+}
+
+func TestPutGetDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s AnnotationStore, file string) {
+		if anns, err := s.List(file); err != nil || len(anns) != 0 {
+			t.Fatalf("List of an unannotated file = %+v, %v", anns, err)
+		}
+
+		expected := []kdl.Annotation{
+			{ID: "ann-1", User: "alice", Timestamp: 123456789, ContextHash: "sha256:abc", TargetText: "Hello", Body: "World"},
+			{ID: "ann-2", User: "bob", Timestamp: 987654321, ContextHash: "sha256:def", TargetText: "Foo", Body: "Bar"},
+			{ID: "ann-3", TargetText: "New", Body: "Note"},
+		}
+		for _, a := range expected {
+			if err := s.Put(file, a); err != nil {
+				t.Fatalf("Put(%s) failed: %v", a.ID, err)
+			}
+		}
+
+		// Replacing keeps the thread's place
+		updated := kdl.Annotation{ID: "ann-1", TargetText: "Hello Updated", Body: "World Updated"}
+		if err := s.Put(file, updated); err != nil {
+			t.Fatalf("Put (update) failed: %v", err)
+		}
+		expected[0] = updated
+		anns, err := s.List(file)
+		if err != nil || len(anns) != len(expected) {
+			t.Fatalf("List = %+v, %v; want %d annotations", anns, err, len(expected))
+		}
+		for i := range expected {
+			if !Equal(anns[i], expected[i]) {
+				t.Errorf("Annotation %d: expected %+v, got %+v", i, expected[i], anns[i])
+			}
+		}
+
+		if got, err := s.Get(file, "ann-2"); err != nil || got.Body != "Bar" {
+			t.Errorf("Get(ann-2) = %+v, %v", got, err)
+		}
+		if err := s.Delete(file, "ann-2"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get(file, "ann-2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+		}
+		if err := s.Delete(file, "ann-2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Second Delete error = %v, want ErrNotFound", err)
+		}
+		if anns, _ := s.List(file); len(anns) != 2 {
+			t.Errorf("Expected 2 annotations after Delete, got %d", len(anns))
+		}
+
+		// A symlink names the same file
+		link := filepath.Join(filepath.Dir(file), "link.md")
+		os.Symlink(file, link)
+		if anns, _ := s.List(link); len(anns) != 2 {
+			t.Errorf("List through a symlink got %d annotations, want 2", len(anns))
+		}
+	})
+}
+
+func TestThreads(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s AnnotationStore, file string) {
+		ann := kdl.Annotation{ID: "ann-1", User: "alice", ContextHash: "sha256:abc", TargetText: "Hello", Body: "Why?"}
+		ann.Replies = []kdl.Reply{
+			{ID: "r1", User: "bob", Body: "Because"},
+			{ID: "r2", User: "alice", Parent: "r1", Body: "Fair"},
+			{ID: "r3", User: "carol", Parent: "r2", Body: "Agreed"},
+		}
+		ann.Resolve("bob", 42)
+		if err := s.Put(file, ann); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+
+		got, err := s.Get(file, "ann-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if !got.Resolved() || got.ResolvedBy != "bob" || got.ResolvedAt != 42 {
+			t.Errorf("Resolution not kept: %+v", got)
+		}
+		if !Equal(got, ann) {
+			t.Errorf("Thread not kept: expected %+v, got %+v", ann, got)
+		}
+	})
+}
+
+func TestAnchors(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s AnnotationStore, file string) {
+		expected := []kdl.Annotation{
+			{ID: "img", Body: "Logo", Anchor: kdl.Anchor{Kind: kdl.AnchorRect, X: 0.25, Y: 0, W: 0.5, H: 0.125}},
+			{ID: "pdf", Body: "Typo", Anchor: kdl.Anchor{Kind: kdl.AnchorPage, Page: 3, Quote: "teh \"quick\" fox"}},
+			{ID: "code", Body: "Leak", Anchor: kdl.Anchor{Kind: kdl.AnchorLines, StartLine: 10, EndLine: 12}},
+			{ID: "text", Body: "Plain", TargetText: "Hello"},
+		}
+		for _, a := range expected {
+			if err := s.Put(file, a); err != nil {
+				t.Fatalf("Put(%s) failed: %v", a.ID, err)
+			}
+		}
+		actual, err := s.List(file)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		for i := range expected {
+			if actual[i].Anchor != expected[i].Anchor {
+				t.Errorf("Anchor of %s: expected %+v, got %+v", expected[i].ID, expected[i].Anchor, actual[i].Anchor)
+			}
+		}
+	})
+}
+
+func TestWatch(t *testing.T) {
+	defer func(d time.Duration) { PollInterval = d }(PollInterval)
+	PollInterval = 10 * time.Millisecond
+
+	forEachBackend(t, func(t *testing.T, s AnnotationStore, file string) {
+		s.Put(file, kdl.Annotation{ID: "old", Body: "Going"})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := s.Watch(ctx, file)
+
+		next := func() Event {
+			t.Helper()
+			select {
+			case e := <-events:
+				return e
+			case <-time.After(5 * time.Second):
+				t.Fatal("No event from Watch")
+			}
+			return Event{}
+		}
+
+		s.Put(file, kdl.Annotation{ID: "new", Body: "Hi"})
+		if e := next(); e.Type != Created || e.Annotation.ID != "new" || e.File != file {
+			t.Errorf("Expected new to be created, got %+v", e)
+		}
+		s.Put(file, kdl.Annotation{ID: "new", Body: "Hello"})
+		if e := next(); e.Type != Updated || e.Annotation.Body != "Hello" {
+			t.Errorf("Expected new to be updated, got %+v", e)
+		}
+		s.Delete(file, "old")
+		if e := next(); e.Type != Deleted || e.Annotation.ID != "old" {
+			t.Errorf("Expected old to be deleted, got %+v", e)
+		}
+
+		cancel()
+		for range events {
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	if !haveSQLite {
+		t.Skip("built without cgo")
+	}
+	dir := t.TempDir()
+	s, err := OpenSQLite(filepath.Join(dir, "annotations.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	a := filepath.Join(dir, "a.md")
+	b := filepath.Join(dir, "b.md")
+	os.WriteFile(a, nil, 0644)
+	os.WriteFile(b, nil, 0644)
+	s.Put(a, kdl.Annotation{ID: "1", TargetText: "teh fox", Body: "Spelling"})
+	s.Put(a, kdl.Annotation{ID: "2", Body: "Unrelated", Replies: []kdl.Reply{{ID: "r", Body: "another typo here"}}})
+	s.Put(b, kdl.Annotation{ID: "3", Anchor: kdl.Anchor{Kind: kdl.AnchorPage, Page: 1, Quote: "a typo"}})
+	s.Put(b, kdl.Annotation{ID: "4", Body: "Nothing to see"})
+
+	matches, err := s.Search("typo OR spelling")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.Annotation.ID)
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[1] != "2" || ids[2] != "3" {
+		t.Errorf("Search found %v, want [1 2 3]", ids)
+	}
+	if len(matches) == 3 && matches[1].Snippet != "Unrelated another [typo] here" {
+		t.Errorf("Snippet = %q", matches[1].Snippet)
+	}
+
+	// Deleted and replaced threads leave the index
+	s.Delete(a, "1")
+	s.Put(a, kdl.Annotation{ID: "2", Body: "Unrelated"})
+	if matches, _ := s.Search("typo OR spelling"); len(matches) != 1 || matches[0].File != b {
+		t.Errorf("Search after changes found %+v", matches)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s AnnotationStore, file string) {
+		dir := filepath.Dir(file)
+		sub := filepath.Join(dir, "sub")
+		os.Mkdir(sub, 0755)
+		other := filepath.Join(sub, "other.md")
+		os.WriteFile(other, nil, 0644)
+		s.Put(file, kdl.Annotation{ID: "1", Body: "Top"})
+		s.Put(other, kdl.Annotation{ID: "2", Body: "Nested"})
+
+		if files, err := s.Files(dir); err != nil || len(files) != 2 || files[0] != file || files[1] != other {
+			t.Errorf("Files(dir) = %v, %v; want [%s %s]", files, err, file, other)
+		}
+		if files, err := s.Files(sub); err != nil || len(files) != 1 || files[0] != other {
+			t.Errorf("Files(sub) = %v, %v; want [%s]", files, err, other)
+		}
+		// A sibling directory sharing the prefix is not under sub
+		os.Mkdir(sub+"2", 0755)
+		s.Put(filepath.Join(sub+"2", "x.md"), kdl.Annotation{ID: "3"})
+		if files, _ := s.Files(sub); len(files) != 1 {
+			t.Errorf("Files(sub) = %v, want only %s", files, other)
+		}
+	})
+}
+
+func TestWatchBrokenSidecar(t *testing.T) {
+	defer func(d time.Duration) { PollInterval = d }(PollInterval)
+	PollInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	os.WriteFile(file, nil, 0644)
+	l, _ := sidecar.New(sidecar.Sibling, filepath.Join(dir, "central"))
+	s := NewKDL(l)
+	s.Put(file, kdl.Annotation{ID: "a1", Body: "Kept"})
+	good, _ := os.ReadFile(file + ".zelland.kdl")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Watch(ctx, file)
+
+	// Replaced whole, so polls never see a half-written file
+	replace := func(data []byte) {
+		os.WriteFile(file+".tmp", data, 0644)
+		os.Rename(file+".tmp", file+".zelland.kdl")
+	}
+	replace(append(good, "annotation id= {{\n"...))
+	if e := <-events; e.Type != Failed || e.Err == nil {
+		t.Fatalf("Expected a failure for the broken sidecar, got %+v", e)
+	}
+	// Fixing it reports what changed since the last good read, and nothing
+	// for the break itself
+	replace(good)
+	s.Put(file, kdl.Annotation{ID: "a2", Body: "New"})
+	if e := <-events; e.Type != Created || e.Annotation.ID != "a2" {
+		t.Errorf("Expected a2 to be created after the fix, got %+v", e)
+	}
+}
+
+func TestReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	ro := filepath.Join(dir, "ro")
+	os.Mkdir(ro, 0755)
+	file := filepath.Join(ro, "notes.md")
+	os.WriteFile(file, nil, 0644)
+	os.Chmod(ro, 0555)
+	defer os.Chmod(ro, 0755)
+
+	l, _ := sidecar.New(sidecar.Sibling, filepath.Join(dir, "central"))
+	s := NewKDL(l)
+	if err := s.Put(file, kdl.Annotation{ID: "a1", Body: "Kept"}); err != nil {
+		t.Fatalf("Put in a read-only directory failed: %v", err)
+	}
+	if anns, err := s.List(file); err != nil || len(anns) != 1 {
+		t.Errorf("List = %+v, %v", anns, err)
+	}
+	if err := s.Put(file, kdl.Annotation{ID: "a2", Body: "Also kept"}); err != nil {
+		t.Errorf("Second Put failed: %v", err)
+	}
+	if anns, _ := s.List(file); len(anns) != 2 {
+		t.Errorf("Expected 2 annotations, got %+v", anns)
+	}
+}
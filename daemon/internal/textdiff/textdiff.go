@@ -0,0 +1,294 @@
+// Package textdiff compares and merges text line by line: unified diffs
+// for review and diff3-style three-way merges for concurrent edits.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the work spent on very different inputs. Beyond it the
+// differing middle is treated as replaced wholesale.
+const maxEdits = 4000
+
+// Lines splits s into lines, each keeping its "\n".
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// match maps each line of a to the line of b it is paired with in a longest
+// common subsequence, or -1.
+func match(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	// Common prefix and suffix are cheap and usually most of the file
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		m[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		m[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+
+	for _, p := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		m[pre+p[0]] = pre + p[1]
+	}
+	return m
+}
+
+// myers returns the index pairs of a longest common subsequence of a and
+// b, using Myers' O((N+M)D) algorithm. It gives up (returning nothing) after
+// maxEdits edits.
+func myers(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+	max := min(n+m, maxEdits)
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	end := -1
+	for d := 0; d <= max && end < 0; d++ {
+		// Keep the diagonals reachable at this step for backtracking
+		snap := make([]int, 2*d+1)
+		copy(snap, v[offset-d:offset+d+1])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				end = d
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+
+	var pairs [][2]int
+	x, y := n, m
+	for d := end; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+		k := x - y
+		var pk int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+		px := at(pk)
+		py := px - pk
+		for x > px && y > py {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = px, py
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		pairs = append(pairs, [2]int{x, y})
+	}
+
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	return pairs
+}
+
+// hunk is a run of lines a[a0:a1] replaced by b[b0:b1].
+type hunk struct {
+	a0, a1, b0, b1 int
+}
+
+// hunks returns the changed regions between a and b.
+func hunks(a, b []string) []hunk {
+	m := match(a, b)
+	var hs []hunk
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && m[i] == j {
+			i++
+			j++
+			continue
+		}
+		h := hunk{a0: i, b0: j}
+		for i < len(a) && m[i] < 0 {
+			i++
+		}
+		if i < len(a) {
+			j = m[i]
+		} else {
+			j = len(b)
+		}
+		h.a1, h.b1 = i, j
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// Unified returns a unified diff from a to b with three lines of context,
+// or "" if they are equal.
+func Unified(aName, bName, a, b string) string {
+	al, bl := Lines(a), Lines(b)
+	hs := hunks(al, bl)
+	if len(hs) == 0 {
+		return ""
+	}
+
+	const context = 3
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for i := 0; i < len(hs); {
+		// Merge hunks whose context would overlap
+		j := i
+		for j+1 < len(hs) && hs[j+1].a0-hs[j].a1 <= 2*context {
+			j++
+		}
+		a0 := max(hs[i].a0-context, 0)
+		b0 := max(hs[i].b0-context, 0)
+		a1 := min(hs[j].a1+context, len(al))
+		b1 := min(hs[j].b1+context, len(bl))
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", span(a0, a1), span(b0, b1))
+
+		pos := a0
+		for _, h := range hs[i : j+1] {
+			for ; pos < h.a0; pos++ {
+				writeLine(&out, " ", al[pos])
+			}
+			for _, l := range al[h.a0:h.a1] {
+				writeLine(&out, "-", l)
+			}
+			for _, l := range bl[h.b0:h.b1] {
+				writeLine(&out, "+", l)
+			}
+			pos = h.a1
+		}
+		for ; pos < a1; pos++ {
+			writeLine(&out, " ", al[pos])
+		}
+		i = j + 1
+	}
+	return out.String()
+}
+
+func span(start, end int) string {
+	if end-start == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	if end == start {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
+
+func writeLine(out *strings.Builder, prefix, line string) {
+	out.WriteString(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs.
+// Regions both sides changed differently are kept as conflicts between
+// "<<<<<<< oursName", "=======" and ">>>>>>> theirsName" markers.
+func Merge3(base, ours, theirs, oursName, theirsName string) (string, bool) {
+	bl, ol, tl := Lines(base), Lines(ours), Lines(theirs)
+	mo, mt := match(bl, ol), match(bl, tl)
+
+	var out strings.Builder
+	conflicts := false
+	b, o, t := 0, 0, 0
+	for {
+		// Lines unchanged on both sides
+		for b < len(bl) && mo[b] == o && mt[b] == t {
+			out.WriteString(bl[b])
+			b, o, t = b+1, o+1, t+1
+		}
+		if b == len(bl) && o == len(ol) && t == len(tl) {
+			break
+		}
+
+		// The next base line both sides kept ends the changed region
+		nb, no, nt := b, len(ol), len(tl)
+		for ; nb < len(bl); nb++ {
+			if mo[nb] >= 0 && mt[nb] >= 0 {
+				no, nt = mo[nb], mt[nb]
+				break
+			}
+		}
+
+		bc, oc, tc := bl[b:nb], ol[o:no], tl[t:nt]
+		switch {
+		case equal(oc, bc):
+			writeAll(&out, tc)
+		case equal(tc, bc), equal(oc, tc):
+			writeAll(&out, oc)
+		default:
+			conflicts = true
+			out.WriteString("<<<<<<< " + oursName + "\n")
+			writeAll(&out, oc)
+			ensureNewline(&out)
+			out.WriteString("=======\n")
+			writeAll(&out, tc)
+			ensureNewline(&out)
+			out.WriteString(">>>>>>> " + theirsName + "\n")
+		}
+		b, o, t = nb, no, nt
+	}
+	return out.String(), conflicts
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeAll(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+func ensureNewline(out *strings.Builder) {
+	if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,96 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerge3Clean(t *testing.T) {
+	base := "# Title\n\nintro\n\n## A\nalpha\n\n## B\nbeta\n"
+	ours := "# Title\n\nintro, fixed\n\n## A\nalpha\n\n## B\nbeta\n"
+	theirs := "# Title\n\nintro\n\n## A\nalpha\n\n## B\nbeta\ngamma\n"
+
+	merged, conflicts := Merge3(base, ours, theirs, "phone", "disk")
+	if conflicts {
+		t.Fatalf("Expected a clean merge, got:\n%s", merged)
+	}
+	want := "# Title\n\nintro, fixed\n\n## A\nalpha\n\n## B\nbeta\ngamma\n"
+	if merged != want {
+		t.Errorf("Merged:\n%s\nwant:\n%s", merged, want)
+	}
+
+	// Both sides making the same change is not a conflict
+	if merged, conflicts := Merge3(base, ours, ours, "phone", "disk"); conflicts || merged != ours {
+		t.Errorf("Expected identical edits to merge cleanly, got:\n%s", merged)
+	}
+}
+
+func TestMerge3Conflict(t *testing.T) {
+	base := "one\ntwo\nthree\n"
+	ours := "one\n2\nthree\n"
+	theirs := "one\nTWO\nthree\n"
+
+	merged, conflicts := Merge3(base, ours, theirs, "phone", "disk")
+	if !conflicts {
+		t.Fatalf("Expected a conflict")
+	}
+	want := "one\n<<<<<<< phone\n2\n=======\nTWO\n>>>>>>> disk\nthree\n"
+	if merged != want {
+		t.Errorf("Merged:\n%q\nwant:\n%q", merged, want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	if d := Unified("a", "b", "same\n", "same\n"); d != "" {
+		t.Errorf("Expected no diff, got %q", d)
+	}
+
+	var a, b strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&a, "line %d\n", i)
+		switch i {
+		case 2:
+			b.WriteString("line two\n")
+		case 15:
+		default:
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+	want := `--- disk
++++ phone
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++line two
+ line 3
+ line 4
+ line 5
+@@ -12,7 +12,6 @@
+ line 12
+ line 13
+ line 14
+-line 15
+ line 16
+ line 17
+ line 18
+`
+	if d := Unified("disk", "phone", a.String(), b.String()); d != want {
+		t.Errorf("Unified diff:\n%s\nwant:\n%s", d, want)
+	}
+}
+
+func TestMyersFindsLongestMatch(t *testing.T) {
+	a := Lines("a\nb\nc\na\nb\nb\na\n")
+	b := Lines("c\nb\na\nb\na\nc\n")
+	n := 0
+	for _, j := range match(a, b) {
+		if j >= 0 {
+			n++
+		}
+	}
+	// The classic example from Myers' paper has an LCS of 4
+	if n != 4 {
+		t.Errorf("Expected 4 matching lines, got %d", n)
+	}
+}
@@ -0,0 +1,445 @@
+// Package upload receives files from clients in resumable, checksummed
+// chunks and moves them into place once they are complete.
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zelland/daemon/internal/assets"
+)
+
+const (
+	// MaxChunk is the largest chunk accepted in one request.
+	MaxChunk = 8 << 20
+	// staleAfter is how long an unfinished upload is kept without progress.
+	staleAfter = 24 * time.Hour
+	// maxNameBytes keeps sanitized names well under filesystem limits.
+	maxNameBytes = 200
+)
+
+var (
+	ErrNotFound = errors.New("no such upload")
+	ErrTooLarge = errors.New("file is larger than the upload limit")
+	ErrQuota    = errors.New("upload would exceed the inbox quota")
+	ErrChecksum = errors.New("checksum mismatch")
+	ErrBusy     = errors.New("another chunk of this upload is being written")
+)
+
+// OffsetError is returned when a chunk does not start where the upload left
+// off. Clients resume from Want.
+type OffsetError struct {
+	Want int64
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("chunk must start at offset %d", e.Want)
+}
+
+// Upload is a file being received.
+type Upload struct {
+	ID string `json:"id"`
+	// Sanitized name the file will get in Dir
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	Dir    string `json:"dir"`
+	// Token name of the client that started the upload
+	Owner string `json:"owner"`
+	// Opaque value for the caller, e.g. the `zelland receive` it answers
+	Tag       string    `json:"tag,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	mu sync.Mutex
+}
+
+// partPath is where the upload's bytes are collected. It lives in the
+// destination directory so that completing the upload is a rename.
+func (u *Upload) partPath() string {
+	return filepath.Join(u.Dir, ".zelland-"+u.ID+".part")
+}
+
+// Store tracks unfinished uploads. Their metadata is persisted under a state
+// directory so uploads can resume after the daemon restarts.
+type Store struct {
+	metaDir string
+	inbox   string
+	maxFile int64
+	quota   int64
+
+	mu      sync.Mutex
+	uploads map[string]*Upload
+}
+
+// Open loads the unfinished uploads recorded in metaDir. An empty metaDir
+// keeps them in memory only. Uploads go to inbox unless Create names
+// another directory; quota (0 for none) limits the inbox size including
+// unfinished uploads.
+func Open(metaDir, inbox string, maxFile, quota int64) (*Store, error) {
+	st := &Store{
+		metaDir: metaDir,
+		inbox:   inbox,
+		maxFile: maxFile,
+		quota:   quota,
+		uploads: make(map[string]*Upload),
+	}
+	if metaDir == "" {
+		return st, nil
+	}
+	if err := os.MkdirAll(metaDir, 0700); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(metaDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(metaDir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var u Upload
+		if err := json.Unmarshal(data, &u); err != nil || u.ID == "" {
+			os.Remove(filepath.Join(metaDir, e.Name()))
+			continue
+		}
+		st.uploads[u.ID] = &u
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.pruneLocked(time.Now())
+	return st, nil
+}
+
+// Inbox returns the default destination directory.
+func (st *Store) Inbox() string {
+	return st.inbox
+}
+
+// Create starts an upload of size bytes whose SHA-256 is sum. The file goes
+// to dir, or to the inbox if dir is empty.
+func (st *Store) Create(name string, size int64, sum, dir, owner, tag string) (*Upload, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	if st.maxFile > 0 && size > st.maxFile {
+		return nil, ErrTooLarge
+	}
+	sum = strings.ToLower(sum)
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("sha256 must be %d hex digits", 2*sha256.Size)
+	}
+	if dir == "" {
+		dir = st.inbox
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	st.pruneLocked(now)
+	// Other directories were picked on the host, and may hold anything
+	if dir == st.inbox && st.quota > 0 {
+		if used := st.inboxUsageLocked(); used+size > st.quota {
+			return nil, ErrQuota
+		}
+	}
+
+	u := &Upload{
+		ID:        assets.NewID(),
+		Name:      Sanitize(name),
+		Size:      size,
+		SHA256:    sum,
+		Dir:       dir,
+		Owner:     owner,
+		Tag:       tag,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	f, err := os.OpenFile(u.partPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	if err := st.saveLocked(u); err != nil {
+		os.Remove(u.partPath())
+		return nil, err
+	}
+	st.uploads[u.ID] = u
+	return u, nil
+}
+
+// Get returns an upload and how many bytes of it have been received.
+func (st *Store) Get(id string) (*Upload, int64, error) {
+	st.mu.Lock()
+	u, ok := st.uploads[id]
+	st.mu.Unlock()
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	fi, err := os.Stat(u.partPath())
+	if err != nil {
+		return nil, 0, err
+	}
+	return u, fi.Size(), nil
+}
+
+// Write appends a chunk starting at offset. If sum is not empty it must be
+// the chunk's SHA-256; a chunk that does not match is discarded. It returns
+// the new offset and, once the last chunk is in and the whole file matches
+// its checksum, the path the file was moved to. If the whole file does not
+// match, the upload restarts from offset 0.
+func (st *Store) Write(id string, offset int64, r io.Reader, sum string) (int64, string, error) {
+	st.mu.Lock()
+	u, ok := st.uploads[id]
+	st.mu.Unlock()
+	if !ok {
+		return 0, "", ErrNotFound
+	}
+	if !u.mu.TryLock() {
+		return 0, "", ErrBusy
+	}
+	defer u.mu.Unlock()
+
+	f, err := os.OpenFile(u.partPath(), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	if offset != fi.Size() {
+		return fi.Size(), "", &OffsetError{Want: fi.Size()}
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, "", err
+	}
+
+	// Read one byte past the limit so an oversized chunk is noticed before
+	// any of it is written. Chunks are all or nothing so the client can
+	// simply resend.
+	limit := min(u.Size-offset, MaxChunk)
+	chunk, err := io.ReadAll(io.LimitReader(r, limit+1))
+	switch {
+	case err != nil:
+		return offset, "", err
+	case int64(len(chunk)) > limit:
+		return offset, "", ErrTooLarge
+	case sum != "" && !strings.EqualFold(sum, chunkSHA256(chunk)):
+		return offset, "", ErrChecksum
+	}
+	if _, err := f.Write(chunk); err != nil {
+		f.Truncate(offset)
+		return offset, "", err
+	}
+	offset += int64(len(chunk))
+
+	st.mu.Lock()
+	u.UpdatedAt = time.Now()
+	st.saveLocked(u)
+	st.mu.Unlock()
+
+	if offset < u.Size {
+		return offset, "", nil
+	}
+	if err := f.Close(); err != nil {
+		return offset, "", err
+	}
+	dest, err := st.finish(u)
+	if errors.Is(err, ErrChecksum) {
+		return 0, "", err
+	}
+	// On other errors the client can retry with an empty chunk at the end
+	return offset, dest, err
+}
+
+// finish checks the whole file and moves it into place.
+func (st *Store) finish(u *Upload) (string, error) {
+	got, err := fileSHA256(u.partPath())
+	if err != nil {
+		return "", err
+	}
+	if got != u.SHA256 {
+		os.Truncate(u.partPath(), 0)
+		return "", ErrChecksum
+	}
+
+	// Unfinished uploads are private; finished ones get the usual mode
+	if err := os.Chmod(u.partPath(), 0644); err != nil {
+		return "", err
+	}
+	dest, err := claim(u.partPath(), u.Dir, u.Name)
+	if err != nil {
+		return "", err
+	}
+
+	st.mu.Lock()
+	st.forgetLocked(u)
+	st.mu.Unlock()
+	return dest, nil
+}
+
+// Cancel discards an unfinished upload.
+func (st *Store) Cancel(id string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u, ok := st.uploads[id]
+	if !ok {
+		return ErrNotFound
+	}
+	os.Remove(u.partPath())
+	st.forgetLocked(u)
+	return nil
+}
+
+// claim moves part to the first free name in dir based on name: "a.jpg",
+// then "a (1).jpg", "a (2).jpg", ... Existing files are never replaced.
+func claim(part, dir, name string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		dest := filepath.Join(dir, candidate)
+		// Link fails if dest exists, unlike rename
+		err := os.Link(part, dest)
+		if err == nil {
+			os.Remove(part)
+			return dest, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+	}
+}
+
+// Sanitize turns a client-supplied file name into a safe name for a single
+// file: no directories, no control characters, not hidden and not too long.
+func Sanitize(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+
+	if len(name) > maxNameBytes {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		base := name[:maxNameBytes-len(ext)]
+		// Don't cut a multi-byte character in half
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+		name = base + ext
+	}
+	if name == "" || name == "/" {
+		return "upload"
+	}
+	return name
+}
+
+// inboxUsageLocked returns the bytes used by files in the inbox plus those
+// still to come for unfinished uploads into it.
+func (st *Store) inboxUsageLocked() int64 {
+	var used int64
+	filepath.WalkDir(st.inbox, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if fi, err := d.Info(); err == nil {
+				used += fi.Size()
+			}
+		}
+		return nil
+	})
+	for _, u := range st.uploads {
+		if u.Dir != st.inbox {
+			continue
+		}
+		// The part file was counted above
+		if fi, err := os.Stat(u.partPath()); err == nil {
+			used += u.Size - fi.Size()
+		}
+	}
+	return used
+}
+
+// pruneLocked discards uploads that made no progress for staleAfter.
+func (st *Store) pruneLocked(now time.Time) {
+	for _, u := range st.uploads {
+		if now.Sub(u.UpdatedAt) > staleAfter {
+			os.Remove(u.partPath())
+			st.forgetLocked(u)
+		}
+	}
+}
+
+func (st *Store) forgetLocked(u *Upload) {
+	delete(st.uploads, u.ID)
+	if st.metaDir != "" {
+		os.Remove(filepath.Join(st.metaDir, u.ID+".json"))
+	}
+}
+
+func (st *Store) saveLocked(u *Upload) error {
+	if st.metaDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(st.metaDir, u.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func chunkSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func TestChunkedUploadAndResume(t *testing.T) {
+	dir := t.TempDir()
+	inbox := filepath.Join(dir, "inbox")
+	st, err := Open(filepath.Join(dir, "meta"), inbox, 1<<20, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	u, err := st.Create("../../etc/photo.jpg", int64(len(data)), sum(data), "", "phone", "")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	off, dest, err := st.Write(u.ID, 0, bytes.NewReader(data[:400]), sum(data[:400]))
+	if err != nil || off != 400 || dest != "" {
+		t.Fatalf("First chunk: offset %d, dest %q, err %v", off, dest, err)
+	}
+
+	// A corrupted chunk is discarded
+	if _, _, err := st.Write(u.ID, 400, bytes.NewReader(data[400:700]), sum([]byte("something else"))); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Expected checksum error, got %v", err)
+	}
+
+	// The daemon restarts; the client resumes from where the store says
+	st, err = Open(filepath.Join(dir, "meta"), inbox, 1<<20, 0)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	var offErr *OffsetError
+	if _, _, err := st.Write(u.ID, 700, bytes.NewReader(data[700:]), ""); !errors.As(err, &offErr) || offErr.Want != 400 {
+		t.Fatalf("Expected offset error wanting 400, got %v", err)
+	}
+
+	off, dest, err = st.Write(u.ID, 400, bytes.NewReader(data[400:]), "")
+	if err != nil || off != int64(len(data)) {
+		t.Fatalf("Last chunk: offset %d, err %v", off, err)
+	}
+	if dest != filepath.Join(inbox, "photo.jpg") {
+		t.Errorf("Expected file in inbox as photo.jpg, got %s", dest)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Errorf("Uploaded file differs from the data sent")
+	}
+	if _, _, err := st.Get(u.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected finished upload to be forgotten, got %v", err)
+	}
+
+	// A second upload with the same name does not replace the first
+	u, _ = st.Create("photo.jpg", 2, sum([]byte("hi")), "", "phone", "")
+	if _, dest, _ = st.Write(u.ID, 0, bytes.NewReader([]byte("hi")), ""); filepath.Base(dest) != "photo (1).jpg" {
+		t.Errorf("Expected photo (1).jpg, got %s", dest)
+	}
+}
+
+func TestWholeFileChecksum(t *testing.T) {
+	st, _ := Open("", t.TempDir(), 0, 0)
+	u, err := st.Create("a.txt", 5, sum([]byte("hello")), "", "phone", "")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	off, _, err := st.Write(u.ID, 0, bytes.NewReader([]byte("jello")), "")
+	if !errors.Is(err, ErrChecksum) || off != 0 {
+		t.Fatalf("Expected checksum error and a restart at 0, got %d, %v", off, err)
+	}
+	if _, _, err := st.Write(u.ID, 0, bytes.NewReader([]byte("hello")), ""); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	inbox := t.TempDir()
+	os.WriteFile(filepath.Join(inbox, "old"), make([]byte, 60), 0644)
+	st, _ := Open("", inbox, 50, 100)
+
+	if _, err := st.Create("big", 51, sum(nil), "", "phone", ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if _, err := st.Create("a", 30, sum(nil), "", "phone", ""); err != nil {
+		t.Fatalf("Create within quota failed: %v", err)
+	}
+	// 60 on disk plus 30 promised
+	if _, err := st.Create("b", 20, sum(nil), "", "phone", ""); !errors.Is(err, ErrQuota) {
+		t.Errorf("Expected ErrQuota, got %v", err)
+	}
+	// The quota only covers the inbox, not whatever else a directory holds
+	elsewhere := t.TempDir()
+	os.WriteFile(filepath.Join(elsewhere, "old"), make([]byte, 90), 0644)
+	if _, err := st.Create("c", 20, sum(nil), elsewhere, "phone", ""); err != nil {
+		t.Errorf("Expected upload elsewhere to ignore the quota, got %v", err)
+	}
+
+	u, _ := st.Create("d", 2, sum([]byte("hi")), t.TempDir(), "phone", "")
+	if _, _, err := st.Write(u.ID, 0, bytes.NewReader([]byte("hi!")), ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected oversized chunk to be refused, got %v", err)
+	}
+
+	// A chunk over MaxChunk is refused whole, even when more is still to come
+	big, err := Open("", t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ = big.Create("f", 2*MaxChunk, sum(nil), "", "phone", "")
+	off, _, err := big.Write(u.ID, 0, bytes.NewReader(make([]byte, MaxChunk+1)), "")
+	if !errors.Is(err, ErrTooLarge) || off != 0 {
+		t.Errorf("Expected chunk over MaxChunk to be refused at 0, got %d, %v", off, err)
+	}
+	if _, got, _ := big.Get(u.ID); got != 0 {
+		t.Errorf("Expected nothing written, got %d bytes", got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := map[string]string{
+		"photo.jpg":            "photo.jpg",
+		"../../etc/passwd":     "passwd",
+		`C:\Users\me\shot.png`: "shot.png",
+		".bashrc":              "bashrc",
+		"..":                   "upload",
+		"":                     "upload",
+		"/":                    "upload",
+		"a\x00b\nc.txt":        "abc.txt",
+		"  spaced name.pdf  ":  "spaced name.pdf",
+		"Screenshot (1).png":   "Screenshot (1).png",
+		"日本語のファイル名.txt":        "日本語のファイル名.txt",
+	}
+	for in, want := range tests {
+		if got := Sanitize(in); got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	long := Sanitize(string(bytes.Repeat([]byte("é"), 300)) + ".jpeg")
+	if len(long) > maxNameBytes || filepath.Ext(long) != ".jpeg" {
+		t.Errorf("Expected a short name keeping the extension, got %d bytes %q", len(long), filepath.Ext(long))
+	}
+}
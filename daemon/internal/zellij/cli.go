@@ -0,0 +1,88 @@
+package zellij
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/zelland/daemon/internal/ansi"
+)
+
+// CLI runs zellij commands against the local Zellij server.
+type CLI struct {
+	Binary string
+}
+
+// ErrInvalidName is returned for session or layout names that could be
+// mistaken for command-line flags or paths.
+var ErrInvalidName = errors.New("invalid name")
+
+// run executes a zellij command and returns its combined output without
+// colours.
+func run(ctx context.Context, binary string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, binary, args...).CombinedOutput()
+	clean := ansi.Strip(string(out))
+	if err != nil {
+		return clean, fmt.Errorf("zellij %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(clean))
+	}
+	return clean, nil
+}
+
+// DumpScreen writes the focused pane of session to path. With full the
+// whole scrollback is included, not just the visible screen.
+func (z CLI) DumpScreen(ctx context.Context, session, path string, full bool) error {
+	if err := validName(session); err != nil {
+		return err
+	}
+	args := []string{"--session", session, "action", "dump-screen", path}
+	if full {
+		args = append(args, "--full")
+	}
+	_, err := run(ctx, z.Binary, args...)
+	return err
+}
+
+// Action runs `zellij action name args...` in session and returns its
+// output. Arguments may not contain "--", which would start a command.
+func (z CLI) Action(ctx context.Context, session, name string, args []string) (string, error) {
+	if err := validName(session); err != nil {
+		return "", err
+	}
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	for _, a := range args {
+		if a == "--" {
+			return "", errors.New(`"--" is not allowed in action arguments; use run to start commands`)
+		}
+	}
+	return run(ctx, z.Binary, append([]string{"--session", session, "action", name}, args...)...)
+}
+
+// Run starts command in a new pane of session. args are `zellij run`
+// options such as --floating or --name.
+func (z CLI) Run(ctx context.Context, session string, args, command []string) (string, error) {
+	if err := validName(session); err != nil {
+		return "", err
+	}
+	if len(command) == 0 {
+		return "", errors.New("no command to run")
+	}
+	for _, a := range args {
+		if a == "--" {
+			return "", errors.New(`"--" is not allowed in run options`)
+		}
+	}
+	full := append([]string{"--session", session, "run"}, args...)
+	full = append(full, "--")
+	return run(ctx, z.Binary, append(full, command...)...)
+}
+
+func validName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, "/\x00\n") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package zellij
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Session is a Zellij session as reported by `zellij list-sessions`.
+type Session struct {
+	Name string
+	// Approximate; zellij only reports how long ago it was created
+	CreatedAt       time.Time
+	AttachedClients int
+	// Exited sessions are kept on disk and can be resurrected
+	Exited bool
+}
+
+// ChangeType describes how a session changed between two listings.
+type ChangeType int
+
+const (
+	SessionAdded ChangeType = iota
+	SessionRemoved
+	SessionExited
+)
+
+// Change is a session that appeared, disappeared or exited.
+type Change struct {
+	Type    ChangeType
+	Session Session
+}
+
+// ListSessions returns all sessions, running and exited, sorted by name.
+func (z CLI) ListSessions(ctx context.Context) ([]Session, error) {
+	out, err := run(ctx, z.Binary, "list-sessions", "--no-formatting")
+	if err != nil {
+		// zellij exits non-zero when there is nothing to list
+		if strings.Contains(out, "No active zellij sessions found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sessions := parseSessions(out, time.Now())
+	for i := range sessions {
+		if sessions[i].Exited {
+			continue
+		}
+		sessions[i].AttachedClients = z.countClients(ctx, sessions[i].Name)
+	}
+	return sessions, nil
+}
+
+// countClients returns how many clients are attached to a running session.
+// Errors count as none.
+func (z CLI) countClients(ctx context.Context, session string) int {
+	out, err := run(ctx, z.Binary, "--session", session, "action", "list-clients")
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for i, line := range strings.Split(out, "\n") {
+		// The first line is the CLIENT_ID ZELLIJ_PANE_ID ... header
+		if i == 0 || strings.TrimSpace(line) == "" {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// CreateSession starts a detached session, optionally from a named layout.
+func (z CLI) CreateSession(ctx context.Context, name, layout string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	args := []string{"attach", "--create-background", name}
+	if layout != "" {
+		if err := validName(layout); err != nil {
+			return err
+		}
+		args = append(args, "options", "--default-layout", layout)
+	}
+	_, err := run(ctx, z.Binary, args...)
+	return err
+}
+
+// RenameSession renames a running session.
+func (z CLI) RenameSession(ctx context.Context, name, newName string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	if err := validName(newName); err != nil {
+		return err
+	}
+	_, err := run(ctx, z.Binary, "--session", name, "action", "rename-session", newName)
+	return err
+}
+
+// KillSession stops a running session. It stays resurrectable until deleted.
+func (z CLI) KillSession(ctx context.Context, name string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	_, err := run(ctx, z.Binary, "kill-session", name)
+	return err
+}
+
+// DeleteSession removes an exited session. With force a running session is
+// killed first.
+func (z CLI) DeleteSession(ctx context.Context, name string, force bool) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	args := []string{"delete-session", name}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := run(ctx, z.Binary, args...)
+	return err
+}
+
+var (
+	createdRe = regexp.MustCompile(`\[Created (.*?) ago\]`)
+	unitRe    = regexp.MustCompile(`(\d+)\s*(years?|months?|weeks?|days?|h|m|s)\b`)
+)
+
+// parseSessions parses `zellij list-sessions --no-formatting` output:
+//
+//	work [Created 2h 3m 10s ago] (current)
+//	old [Created 3days 4h ago] (EXITED - attach to resurrect)
+func parseSessions(out string, now time.Time) []Session {
+	var sessions []Session
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s := Session{Name: line}
+		if i := strings.Index(line, " [Created "); i >= 0 {
+			s.Name = line[:i]
+			if m := createdRe.FindStringSubmatch(line); m != nil {
+				s.CreatedAt = now.Add(-parseAgo(m[1])).Truncate(time.Second)
+			}
+		} else if i := strings.IndexByte(line, ' '); i >= 0 {
+			s.Name = line[:i]
+		}
+		s.Exited = strings.Contains(line, "(EXITED")
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions
+}
+
+// parseAgo parses the humantime durations zellij prints, e.g. "1day 2h 5s".
+func parseAgo(s string) time.Duration {
+	var d time.Duration
+	for _, m := range unitRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		var unit time.Duration
+		switch strings.TrimSuffix(m[2], "s") {
+		case "year":
+			unit = 8766 * time.Hour
+		case "month":
+			unit = 730 * time.Hour
+		case "week":
+			unit = 7 * 24 * time.Hour
+		case "day":
+			unit = 24 * time.Hour
+		case "h":
+			unit = time.Hour
+		case "m":
+			unit = time.Minute
+		case "":
+			unit = time.Second
+		}
+		d += time.Duration(n) * unit
+	}
+	return d
+}
+
+// Diff reports the sessions that were added, removed or exited between two
+// listings.
+func Diff(before, after []Session) []Change {
+	old := make(map[string]Session, len(before))
+	for _, s := range before {
+		old[s.Name] = s
+	}
+
+	var changes []Change
+	for _, s := range after {
+		prev, ok := old[s.Name]
+		delete(old, s.Name)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: SessionAdded, Session: s})
+		case s.Exited && !prev.Exited:
+			changes = append(changes, Change{Type: SessionExited, Session: s})
+		case !s.Exited && prev.Exited:
+			// Resurrected
+			changes = append(changes, Change{Type: SessionAdded, Session: s})
+		}
+	}
+	for _, s := range before {
+		if _, gone := old[s.Name]; gone {
+			changes = append(changes, Change{Type: SessionRemoved, Session: s})
+		}
+	}
+	return changes
+}
+
+// SocketDir returns the directory where Zellij keeps its session sockets.
+func SocketDir() string {
+	if dir := os.Getenv("ZELLIJ_SOCKET_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "zellij")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("zellij-%d", os.Getuid()))
+}
+
+// Watch polls the socket directory every interval and calls fn whenever a
+// session socket appears or disappears, until ctx is cancelled. fn is also
+// called once on start.
+func Watch(ctx context.Context, dir string, interval time.Duration, fn func()) {
+	last := socketSignature(dir)
+	fn()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if sig := socketSignature(dir); sig != last {
+			last = sig
+			fn()
+		}
+	}
+}
+
+// socketSignature lists the sockets under dir. Zellij nests them in a
+// per-protocol directory such as contract_version_1/.
+func socketSignature(dir string) string {
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+			continue
+		}
+		sub, _ := os.ReadDir(filepath.Join(dir, e.Name()))
+		for _, s := range sub {
+			names = append(names, e.Name()+"/"+s.Name())
+		}
+	}
+	return strings.Join(names, "\n")
+}
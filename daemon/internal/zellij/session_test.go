@@ -0,0 +1,56 @@
+package zellij
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSessions(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	out := "work [Created 2h 3m 10s ago] (current)\n" +
+		"old [Created 3days 4h ago] (EXITED - attach to resurrect)\n" +
+		"\n"
+
+	sessions := parseSessions(out, now)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+
+	old, work := sessions[0], sessions[1]
+	if old.Name != "old" || !old.Exited {
+		t.Errorf("Unexpected session: %+v", old)
+	}
+	if want := now.Add(-76 * time.Hour); !old.CreatedAt.Equal(want) {
+		t.Errorf("old created at %v, want %v", old.CreatedAt, want)
+	}
+	if work.Name != "work" || work.Exited {
+		t.Errorf("Unexpected session: %+v", work)
+	}
+	if want := now.Add(-(2*time.Hour + 3*time.Minute + 10*time.Second)); !work.CreatedAt.Equal(want) {
+		t.Errorf("work created at %v, want %v", work.CreatedAt, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := []Session{{Name: "a"}, {Name: "b"}, {Name: "c", Exited: true}}
+	after := []Session{{Name: "a", Exited: true}, {Name: "c"}, {Name: "d"}}
+
+	got := map[string]ChangeType{}
+	for _, ch := range Diff(before, after) {
+		got[ch.Session.Name] = ch.Type
+	}
+	want := map[string]ChangeType{
+		"a": SessionExited,
+		"b": SessionRemoved,
+		"c": SessionAdded, // resurrected
+		"d": SessionAdded,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d changes, got %v", len(want), got)
+	}
+	for name, typ := range want {
+		if got[name] != typ {
+			t.Errorf("%s: got change %v, want %v", name, got[name], typ)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package zellij drives the local Zellij installation on behalf of the app.
+package zellij
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zelland/daemon/internal/ansi"
+	"github.com/zelland/daemon/internal/logtail"
+)
+
+const (
+	// stopTimeout is how long Stop waits after SIGTERM before killing.
+	stopTimeout = 5 * time.Second
+	// maxBackoff caps the delay between restarts of a crashing server.
+	maxBackoff = 30 * time.Second
+	// stableRun is how long the server must stay up for the restart
+	// backoff to reset.
+	stableRun = time.Minute
+)
+
+// ErrExternal is returned when a `zellij web` server that the daemon did not
+// start is already listening on the configured port.
+var ErrExternal = errors.New("zellij web is already running outside zelland")
+
+// WebStatus describes the state of the web server.
+type WebStatus struct {
+	// Running is true if something answers on the web port
+	Running bool
+	// Supervised is true if the daemon started the server and will
+	// restart it if it exits
+	Supervised bool
+	PID        int
+	StartedAt  time.Time
+	Restarts   int
+	// LastExit is why the supervised process last exited, if it did
+	LastExit string
+}
+
+// WebServer supervises a `zellij web` child process. Its output is captured
+// into a log stream; if it exits unexpectedly it is restarted with backoff.
+type WebServer struct {
+	binary string
+	port   int
+	args   []string
+	logs   *logtail.Stream
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	done      chan struct{}
+	wanted    bool
+	startedAt time.Time
+	restarts  int
+	lastExit  string
+}
+
+// NewWebServer returns a supervisor for `binary web`. Output of the child
+// process is appended to logs.
+func NewWebServer(binary string, port int, args []string, logs *logtail.Stream) *WebServer {
+	return &WebServer{
+		binary: binary,
+		port:   port,
+		args:   args,
+		logs:   logs,
+	}
+}
+
+// Logs returns the stream the server's output is captured into.
+func (w *WebServer) Logs() *logtail.Stream {
+	return w.logs
+}
+
+// Port returns the port the web server listens on.
+func (w *WebServer) Port() int {
+	return w.port
+}
+
+// Start launches the server and keeps it running until Stop is called.
+func (w *WebServer) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cmd != nil {
+		return nil
+	}
+	if w.healthy(ctx) {
+		return ErrExternal
+	}
+
+	w.wanted = true
+	w.restarts = 0
+	return w.spawn()
+}
+
+// Stop terminates the server. A server the daemon did not start is asked to
+// stop with `zellij web --stop`.
+func (w *WebServer) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	w.wanted = false
+	cmd, done := w.cmd, w.done
+	w.mu.Unlock()
+
+	if cmd == nil {
+		if !w.healthy(ctx) {
+			return nil
+		}
+		_, err := run(ctx, w.binary, "web", "--stop")
+		return err
+	}
+
+	// The server runs in its own process group so its children go too
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	select {
+	case <-done:
+		return nil
+	case <-time.After(stopTimeout):
+	}
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Restart stops the server if it is running and starts it again.
+func (w *WebServer) Restart(ctx context.Context) error {
+	if err := w.Stop(ctx); err != nil {
+		return err
+	}
+	return w.Start(ctx)
+}
+
+// Status reports whether the server is running and answering requests.
+func (w *WebServer) Status(ctx context.Context) WebStatus {
+	w.mu.Lock()
+	st := WebStatus{
+		Supervised: w.cmd != nil,
+		Restarts:   w.restarts,
+		LastExit:   w.lastExit,
+	}
+	if w.cmd != nil {
+		st.PID = w.cmd.Process.Pid
+		st.StartedAt = w.startedAt
+	}
+	w.mu.Unlock()
+
+	st.Running = w.healthy(ctx)
+	return st
+}
+
+// CreateToken creates a login token for the web client and returns its
+// name and value.
+func (w *WebServer) CreateToken(ctx context.Context) (name, token string, err error) {
+	out, err := run(ctx, w.binary, "web", "--create-token")
+	if err != nil {
+		return "", "", err
+	}
+	return parseToken(out)
+}
+
+var (
+	namedTokenRe = regexp.MustCompile(`(token_\d+):\s*([0-9a-fA-F-]+)`)
+	uuidRe       = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+)
+
+// parseToken extracts the token from `zellij web --create-token` output,
+// which looks like "Created token successfully\n\ntoken_1: <uuid>".
+func parseToken(out string) (name, token string, err error) {
+	if m := namedTokenRe.FindStringSubmatch(out); m != nil {
+		return m[1], m[2], nil
+	}
+	if m := uuidRe.FindString(out); m != "" {
+		return "", m, nil
+	}
+	return "", "", fmt.Errorf("no token in zellij output: %q", out)
+}
+
+// spawn starts the child process. Callers must hold w.mu.
+func (w *WebServer) spawn() error {
+	args := []string{"web", "--port", strconv.Itoa(w.port)}
+	args = append(args, w.args...)
+	cmd := exec.Command(w.binary, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		w.logs.Append(fmt.Sprintf("zelland: failed to start zellij web: %v", err))
+		return fmt.Errorf("failed to start zellij web: %w", err)
+	}
+	pw.Close()
+
+	w.logs.Append(fmt.Sprintf("zelland: started zellij web (pid %d, port %d)", cmd.Process.Pid, w.port))
+	go w.capture(pr)
+
+	w.cmd = cmd
+	w.done = make(chan struct{})
+	w.startedAt = time.Now()
+	go w.supervise(cmd, w.done)
+	return nil
+}
+
+// supervise waits for cmd to exit and restarts it unless Stop was called.
+func (w *WebServer) supervise(cmd *exec.Cmd, done chan struct{}) {
+	err := cmd.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	exit := "exit status 0"
+	if err != nil {
+		exit = err.Error()
+	}
+	w.lastExit = exit
+	w.cmd = nil
+	close(done)
+
+	if !w.wanted {
+		w.logs.Append("zelland: zellij web stopped")
+		return
+	}
+
+	if time.Since(w.startedAt) > stableRun {
+		w.restarts = 0
+	}
+	delay := time.Second << min(w.restarts, 5)
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	w.restarts++
+	w.logs.Append(fmt.Sprintf("zelland: zellij web exited (%s); restarting in %s", exit, delay))
+
+	time.AfterFunc(delay, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.wanted || w.cmd != nil {
+			return
+		}
+		w.spawn()
+	})
+}
+
+// healthy reports whether the web server answers HTTP on its port. Zellij
+// serves plain HTTP unless it was given a certificate, so both are tried.
+func (w *WebServer) healthy(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			// The probe only checks liveness; the certificate is usually
+			// self-signed or issued for a different name.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	for _, scheme := range []string{"http", "https"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://127.0.0.1:%d/", scheme, w.port), nil)
+		if err != nil {
+			return false
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			return true
+		}
+	}
+	return false
+}
+
+// capture appends the child's output to the log stream without colours.
+func (w *WebServer) capture(r *os.File) {
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		w.logs.Append(ansi.Strip(scanner.Text()))
+	}
+}
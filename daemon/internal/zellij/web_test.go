@@ -0,0 +1,28 @@
+package zellij
+
+import (
+	"testing"
+
+	"github.com/zelland/daemon/internal/ansi"
+)
+
+func TestParseToken(t *testing.T) {
+	out := ansi.Strip("\x1b[32;1mCreated token successfully\x1b[0m\n\ntoken_3: 40cfd772-e052-43a0-8acf-e64b1b8825fb\n")
+	name, token, err := parseToken(out)
+	if err != nil {
+		t.Fatalf("parseToken failed: %v", err)
+	}
+	if name != "token_3" || token != "40cfd772-e052-43a0-8acf-e64b1b8825fb" {
+		t.Errorf("got %q %q", name, token)
+	}
+
+	// Older releases print only the UUID
+	_, token, err = parseToken("40cfd772-e052-43a0-8acf-e64b1b8825fb\n")
+	if err != nil || token != "40cfd772-e052-43a0-8acf-e64b1b8825fb" {
+		t.Errorf("bare token: got %q, %v", token, err)
+	}
+
+	if _, _, err := parseToken("error: web server is not enabled"); err == nil {
+		t.Errorf("expected an error for output without a token")
+	}
+}
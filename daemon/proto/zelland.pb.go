@@ -28,6 +28,9 @@ const (
 	OpenViewRequest_IMAGE    OpenViewRequest_FileType = 1
 	OpenViewRequest_MARKDOWN OpenViewRequest_FileType = 2
 	OpenViewRequest_PDF      OpenViewRequest_FileType = 3
+	OpenViewRequest_LOG      OpenViewRequest_FileType = 4
+	OpenViewRequest_TEXT     OpenViewRequest_FileType = 5 // Searchable text document (HTML or plain text, per Content-Type)
+	OpenViewRequest_CODE     OpenViewRequest_FileType = 6 // Source file; shown as plain text and editable like MARKDOWN
 )
 
 // Enum value maps for OpenViewRequest_FileType.
@@ -37,12 +40,18 @@ var (
 		1: "IMAGE",
 		2: "MARKDOWN",
 		3: "PDF",
+		4: "LOG",
+		5: "TEXT",
+		6: "CODE",
 	}
 	OpenViewRequest_FileType_value = map[string]int32{
 		"UNKNOWN":  0,
 		"IMAGE":    1,
 		"MARKDOWN": 2,
 		"PDF":      3,
+		"LOG":      4,
+		"TEXT":     5,
+		"CODE":     6,
 	}
 )
 
@@ -76,9 +85,12 @@ func (OpenViewRequest_FileType) EnumDescriptor() ([]byte, []int) {
 type AnnotationAction_ActionType int32
 
 const (
-	AnnotationAction_CREATE AnnotationAction_ActionType = 0
-	AnnotationAction_UPDATE AnnotationAction_ActionType = 1
-	AnnotationAction_DELETE AnnotationAction_ActionType = 2
+	AnnotationAction_CREATE  AnnotationAction_ActionType = 0
+	AnnotationAction_UPDATE  AnnotationAction_ActionType = 1
+	AnnotationAction_DELETE  AnnotationAction_ActionType = 2
+	AnnotationAction_REPLY   AnnotationAction_ActionType = 3 // data.parent_id is the annotation or reply answered
+	AnnotationAction_RESOLVE AnnotationAction_ActionType = 4
+	AnnotationAction_REOPEN  AnnotationAction_ActionType = 5
 )
 
 // Enum value maps for AnnotationAction_ActionType.
@@ -87,11 +99,17 @@ var (
 		0: "CREATE",
 		1: "UPDATE",
 		2: "DELETE",
+		3: "REPLY",
+		4: "RESOLVE",
+		5: "REOPEN",
 	}
 	AnnotationAction_ActionType_value = map[string]int32{
-		"CREATE": 0,
-		"UPDATE": 1,
-		"DELETE": 2,
+		"CREATE":  0,
+		"UPDATE":  1,
+		"DELETE":  2,
+		"REPLY":   3,
+		"RESOLVE": 4,
+		"REOPEN":  5,
 	}
 )
 
@@ -119,7 +137,105 @@ func (x AnnotationAction_ActionType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AnnotationAction_ActionType.Descriptor instead.
 func (AnnotationAction_ActionType) EnumDescriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{3, 0}
+	return file_proto_zelland_proto_rawDescGZIP(), []int{4, 0}
+}
+
+type AnnotationData_Status int32
+
+const (
+	AnnotationData_OPEN     AnnotationData_Status = 0
+	AnnotationData_RESOLVED AnnotationData_Status = 1
+)
+
+// Enum value maps for AnnotationData_Status.
+var (
+	AnnotationData_Status_name = map[int32]string{
+		0: "OPEN",
+		1: "RESOLVED",
+	}
+	AnnotationData_Status_value = map[string]int32{
+		"OPEN":     0,
+		"RESOLVED": 1,
+	}
+)
+
+func (x AnnotationData_Status) Enum() *AnnotationData_Status {
+	p := new(AnnotationData_Status)
+	*p = x
+	return p
+}
+
+func (x AnnotationData_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AnnotationData_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[2].Descriptor()
+}
+
+func (AnnotationData_Status) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[2]
+}
+
+func (x AnnotationData_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AnnotationData_Status.Descriptor instead.
+func (AnnotationData_Status) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{5, 0}
+}
+
+type Anchor_Kind int32
+
+const (
+	Anchor_TEXT  Anchor_Kind = 0
+	Anchor_RECT  Anchor_Kind = 1 // A rectangle in an IMAGE
+	Anchor_PAGE  Anchor_Kind = 2 // A PDF page, with a quote or a rectangle on it
+	Anchor_LINES Anchor_Kind = 3 // A range of lines in CODE, MARKDOWN or TEXT
+)
+
+// Enum value maps for Anchor_Kind.
+var (
+	Anchor_Kind_name = map[int32]string{
+		0: "TEXT",
+		1: "RECT",
+		2: "PAGE",
+		3: "LINES",
+	}
+	Anchor_Kind_value = map[string]int32{
+		"TEXT":  0,
+		"RECT":  1,
+		"PAGE":  2,
+		"LINES": 3,
+	}
+)
+
+func (x Anchor_Kind) Enum() *Anchor_Kind {
+	p := new(Anchor_Kind)
+	*p = x
+	return p
+}
+
+func (x Anchor_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Anchor_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[3].Descriptor()
+}
+
+func (Anchor_Kind) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[3]
+}
+
+func (x Anchor_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Anchor_Kind.Descriptor instead.
+func (Anchor_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{6, 0}
 }
 
 type ClientStatus_ViewState int32
@@ -152,11 +268,11 @@ func (x ClientStatus_ViewState) String() string {
 }
 
 func (ClientStatus_ViewState) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_zelland_proto_enumTypes[2].Descriptor()
+	return file_proto_zelland_proto_enumTypes[4].Descriptor()
 }
 
 func (ClientStatus_ViewState) Type() protoreflect.EnumType {
-	return &file_proto_zelland_proto_enumTypes[2]
+	return &file_proto_zelland_proto_enumTypes[4]
 }
 
 func (x ClientStatus_ViewState) Number() protoreflect.EnumNumber {
@@ -165,7 +281,411 @@ func (x ClientStatus_ViewState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ClientStatus_ViewState.Descriptor instead.
 func (ClientStatus_ViewState) EnumDescriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{5, 0}
+	return file_proto_zelland_proto_rawDescGZIP(), []int{7, 0}
+}
+
+type LogChunk_Event int32
+
+const (
+	LogChunk_NONE      LogChunk_Event = 0
+	LogChunk_TRUNCATED LogChunk_Event = 1 // The file was truncated; following lines start over
+	LogChunk_ROTATED   LogChunk_Event = 2 // The file was replaced (e.g. logrotate)
+	LogChunk_EOF       LogChunk_Event = 3 // The stream has ended
+)
+
+// Enum value maps for LogChunk_Event.
+var (
+	LogChunk_Event_name = map[int32]string{
+		0: "NONE",
+		1: "TRUNCATED",
+		2: "ROTATED",
+		3: "EOF",
+	}
+	LogChunk_Event_value = map[string]int32{
+		"NONE":      0,
+		"TRUNCATED": 1,
+		"ROTATED":   2,
+		"EOF":       3,
+	}
+)
+
+func (x LogChunk_Event) Enum() *LogChunk_Event {
+	p := new(LogChunk_Event)
+	*p = x
+	return p
+}
+
+func (x LogChunk_Event) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogChunk_Event) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[5].Descriptor()
+}
+
+func (LogChunk_Event) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[5]
+}
+
+func (x LogChunk_Event) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogChunk_Event.Descriptor instead.
+func (LogChunk_Event) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{8, 0}
+}
+
+type Notification_Urgency int32
+
+const (
+	Notification_NORMAL Notification_Urgency = 0
+	Notification_LOW    Notification_Urgency = 1
+	Notification_HIGH   Notification_Urgency = 2
+)
+
+// Enum value maps for Notification_Urgency.
+var (
+	Notification_Urgency_name = map[int32]string{
+		0: "NORMAL",
+		1: "LOW",
+		2: "HIGH",
+	}
+	Notification_Urgency_value = map[string]int32{
+		"NORMAL": 0,
+		"LOW":    1,
+		"HIGH":   2,
+	}
+)
+
+func (x Notification_Urgency) Enum() *Notification_Urgency {
+	p := new(Notification_Urgency)
+	*p = x
+	return p
+}
+
+func (x Notification_Urgency) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Notification_Urgency) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[6].Descriptor()
+}
+
+func (Notification_Urgency) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[6]
+}
+
+func (x Notification_Urgency) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Notification_Urgency.Descriptor instead.
+func (Notification_Urgency) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{11, 0}
+}
+
+type Ack_Status int32
+
+const (
+	Ack_RECEIVED  Ack_Status = 0 // Parsed, but not (yet) shown to the user
+	Ack_DISPLAYED Ack_Status = 1 // Shown on screen
+	Ack_FAILED    Ack_Status = 2 // Could not be handled; see error
+)
+
+// Enum value maps for Ack_Status.
+var (
+	Ack_Status_name = map[int32]string{
+		0: "RECEIVED",
+		1: "DISPLAYED",
+		2: "FAILED",
+	}
+	Ack_Status_value = map[string]int32{
+		"RECEIVED":  0,
+		"DISPLAYED": 1,
+		"FAILED":    2,
+	}
+)
+
+func (x Ack_Status) Enum() *Ack_Status {
+	p := new(Ack_Status)
+	*p = x
+	return p
+}
+
+func (x Ack_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Ack_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[7].Descriptor()
+}
+
+func (Ack_Status) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[7]
+}
+
+func (x Ack_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Ack_Status.Descriptor instead.
+func (Ack_Status) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{13, 0}
+}
+
+type QuickAction_Output int32
+
+const (
+	QuickAction_LOG    QuickAction_Output = 0 // Output is streamed to a LOG view
+	QuickAction_NOTIFY QuickAction_Output = 1 // A notification is sent when the command finishes
+)
+
+// Enum value maps for QuickAction_Output.
+var (
+	QuickAction_Output_name = map[int32]string{
+		0: "LOG",
+		1: "NOTIFY",
+	}
+	QuickAction_Output_value = map[string]int32{
+		"LOG":    0,
+		"NOTIFY": 1,
+	}
+)
+
+func (x QuickAction_Output) Enum() *QuickAction_Output {
+	p := new(QuickAction_Output)
+	*p = x
+	return p
+}
+
+func (x QuickAction_Output) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QuickAction_Output) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[8].Descriptor()
+}
+
+func (QuickAction_Output) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[8]
+}
+
+func (x QuickAction_Output) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QuickAction_Output.Descriptor instead.
+func (QuickAction_Output) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{16, 0}
+}
+
+type ZellijWebRequest_Action int32
+
+const (
+	ZellijWebRequest_STATUS       ZellijWebRequest_Action = 0
+	ZellijWebRequest_START        ZellijWebRequest_Action = 1
+	ZellijWebRequest_STOP         ZellijWebRequest_Action = 2
+	ZellijWebRequest_RESTART      ZellijWebRequest_Action = 3
+	ZellijWebRequest_CREATE_TOKEN ZellijWebRequest_Action = 4 // Create a login token for the web client
+)
+
+// Enum value maps for ZellijWebRequest_Action.
+var (
+	ZellijWebRequest_Action_name = map[int32]string{
+		0: "STATUS",
+		1: "START",
+		2: "STOP",
+		3: "RESTART",
+		4: "CREATE_TOKEN",
+	}
+	ZellijWebRequest_Action_value = map[string]int32{
+		"STATUS":       0,
+		"START":        1,
+		"STOP":         2,
+		"RESTART":      3,
+		"CREATE_TOKEN": 4,
+	}
+)
+
+func (x ZellijWebRequest_Action) Enum() *ZellijWebRequest_Action {
+	p := new(ZellijWebRequest_Action)
+	*p = x
+	return p
+}
+
+func (x ZellijWebRequest_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ZellijWebRequest_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[9].Descriptor()
+}
+
+func (ZellijWebRequest_Action) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[9]
+}
+
+func (x ZellijWebRequest_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ZellijWebRequest_Action.Descriptor instead.
+func (ZellijWebRequest_Action) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{17, 0}
+}
+
+type SessionRequest_Action int32
+
+const (
+	SessionRequest_LIST   SessionRequest_Action = 0
+	SessionRequest_CREATE SessionRequest_Action = 1 // Start a detached session, optionally from a layout
+	SessionRequest_RENAME SessionRequest_Action = 2
+	SessionRequest_KILL   SessionRequest_Action = 3 // Stop a running session; it stays resurrectable
+	SessionRequest_DELETE SessionRequest_Action = 4 // Remove an exited session
+)
+
+// Enum value maps for SessionRequest_Action.
+var (
+	SessionRequest_Action_name = map[int32]string{
+		0: "LIST",
+		1: "CREATE",
+		2: "RENAME",
+		3: "KILL",
+		4: "DELETE",
+	}
+	SessionRequest_Action_value = map[string]int32{
+		"LIST":   0,
+		"CREATE": 1,
+		"RENAME": 2,
+		"KILL":   3,
+		"DELETE": 4,
+	}
+)
+
+func (x SessionRequest_Action) Enum() *SessionRequest_Action {
+	p := new(SessionRequest_Action)
+	*p = x
+	return p
+}
+
+func (x SessionRequest_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionRequest_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[10].Descriptor()
+}
+
+func (SessionRequest_Action) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[10]
+}
+
+func (x SessionRequest_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionRequest_Action.Descriptor instead.
+func (SessionRequest_Action) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{19, 0}
+}
+
+type SessionEvent_Type int32
+
+const (
+	SessionEvent_ADDED   SessionEvent_Type = 0
+	SessionEvent_REMOVED SessionEvent_Type = 1
+	SessionEvent_EXITED  SessionEvent_Type = 2
+)
+
+// Enum value maps for SessionEvent_Type.
+var (
+	SessionEvent_Type_name = map[int32]string{
+		0: "ADDED",
+		1: "REMOVED",
+		2: "EXITED",
+	}
+	SessionEvent_Type_value = map[string]int32{
+		"ADDED":   0,
+		"REMOVED": 1,
+		"EXITED":  2,
+	}
+)
+
+func (x SessionEvent_Type) Enum() *SessionEvent_Type {
+	p := new(SessionEvent_Type)
+	*p = x
+	return p
+}
+
+func (x SessionEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[11].Descriptor()
+}
+
+func (SessionEvent_Type) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[11]
+}
+
+func (x SessionEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionEvent_Type.Descriptor instead.
+func (SessionEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{22, 0}
+}
+
+type BrowseRequest_Action int32
+
+const (
+	BrowseRequest_LIST   BrowseRequest_Action = 0 // Entries of path; with no root, only the roots are returned
+	BrowseRequest_SEARCH BrowseRequest_Action = 1 // Fuzzy match file names below path against query
+	BrowseRequest_OPEN   BrowseRequest_Action = 2 // Register the file at path as an asset to view
+)
+
+// Enum value maps for BrowseRequest_Action.
+var (
+	BrowseRequest_Action_name = map[int32]string{
+		0: "LIST",
+		1: "SEARCH",
+		2: "OPEN",
+	}
+	BrowseRequest_Action_value = map[string]int32{
+		"LIST":   0,
+		"SEARCH": 1,
+		"OPEN":   2,
+	}
+)
+
+func (x BrowseRequest_Action) Enum() *BrowseRequest_Action {
+	p := new(BrowseRequest_Action)
+	*p = x
+	return p
+}
+
+func (x BrowseRequest_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BrowseRequest_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_zelland_proto_enumTypes[12].Descriptor()
+}
+
+func (BrowseRequest_Action) Type() protoreflect.EnumType {
+	return &file_proto_zelland_proto_enumTypes[12]
+}
+
+func (x BrowseRequest_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BrowseRequest_Action.Descriptor instead.
+func (BrowseRequest_Action) EnumDescriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{36, 0}
 }
 
 // Wrapper for all WebSocket messages
@@ -177,26 +697,3017 @@ type Envelope struct {
 	//	*Envelope_OpenView
 	//	*Envelope_Annotation
 	//	*Envelope_Status
-	Payload       isEnvelope_Payload `protobuf_oneof:"payload"`
+	//	*Envelope_Log
+	//	*Envelope_LogSubscribe
+	//	*Envelope_Notification
+	//	*Envelope_Resume
+	//	*Envelope_Ack
+	//	*Envelope_Hello
+	//	*Envelope_Welcome
+	//	*Envelope_ZellijWebRequest
+	//	*Envelope_ZellijWebResponse
+	//	*Envelope_SessionRequest
+	//	*Envelope_SessionResponse
+	//	*Envelope_SessionEvent
+	//	*Envelope_CaptureRequest
+	//	*Envelope_CaptureResponse
+	//	*Envelope_ActionRequest
+	//	*Envelope_ActionResponse
+	//	*Envelope_RunQuickAction
+	//	*Envelope_QuickActionResult
+	//	*Envelope_Prompt
+	//	*Envelope_PromptResponse
+	//	*Envelope_PromptClosed
+	//	*Envelope_ClipboardSet
+	//	*Envelope_ClipboardRequest
+	//	*Envelope_ClipboardContent
+	//	*Envelope_ReceiveRequest
+	//	*Envelope_BrowseRequest
+	//	*Envelope_BrowseResponse
+	//	*Envelope_AssetUpdated
+	//	*Envelope_AnnotationWarning
+	Payload isEnvelope_Payload `protobuf_oneof:"payload"`
+	// Assigned by the server to every outbound envelope; monotonically
+	// increasing across daemon restarts. Zero for client -> server messages.
+	Seq uint64 `protobuf:"varint,100,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Set by the server on envelopes that expect an Ack (e.g. OpenView).
+	// Set by the client on RPC requests; the server echoes it on the
+	// response, which is not acknowledged.
+	RequestId     string `protobuf:"bytes,101,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_proto_zelland_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetPayload() isEnvelope_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Envelope) GetPing() *KeepAlive {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetOpenView() *OpenViewRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_OpenView); ok {
+			return x.OpenView
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAnnotation() *AnnotationAction {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Annotation); ok {
+			return x.Annotation
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetStatus() *ClientStatus {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Status); ok {
+			return x.Status
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetLog() *LogChunk {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Log); ok {
+			return x.Log
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetLogSubscribe() *LogSubscribe {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_LogSubscribe); ok {
+			return x.LogSubscribe
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetNotification() *Notification {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Notification); ok {
+			return x.Notification
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetResume() *Resume {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Resume); ok {
+			return x.Resume
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAck() *Ack {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetHello() *Hello {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Hello); ok {
+			return x.Hello
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetWelcome() *Welcome {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Welcome); ok {
+			return x.Welcome
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetZellijWebRequest() *ZellijWebRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ZellijWebRequest); ok {
+			return x.ZellijWebRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetZellijWebResponse() *ZellijWebResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ZellijWebResponse); ok {
+			return x.ZellijWebResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSessionRequest() *SessionRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_SessionRequest); ok {
+			return x.SessionRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSessionResponse() *SessionResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_SessionResponse); ok {
+			return x.SessionResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSessionEvent() *SessionEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_SessionEvent); ok {
+			return x.SessionEvent
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCaptureRequest() *CaptureRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_CaptureRequest); ok {
+			return x.CaptureRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCaptureResponse() *CaptureResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_CaptureResponse); ok {
+			return x.CaptureResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetActionRequest() *ActionRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ActionRequest); ok {
+			return x.ActionRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetActionResponse() *ActionResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ActionResponse); ok {
+			return x.ActionResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetRunQuickAction() *RunQuickAction {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_RunQuickAction); ok {
+			return x.RunQuickAction
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetQuickActionResult() *QuickActionResult {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_QuickActionResult); ok {
+			return x.QuickActionResult
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPrompt() *Prompt {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Prompt); ok {
+			return x.Prompt
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPromptResponse() *PromptResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PromptResponse); ok {
+			return x.PromptResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPromptClosed() *PromptClosed {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PromptClosed); ok {
+			return x.PromptClosed
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetClipboardSet() *ClipboardSet {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ClipboardSet); ok {
+			return x.ClipboardSet
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetClipboardRequest() *ClipboardRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ClipboardRequest); ok {
+			return x.ClipboardRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetClipboardContent() *ClipboardContent {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ClipboardContent); ok {
+			return x.ClipboardContent
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetReceiveRequest() *ReceiveRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ReceiveRequest); ok {
+			return x.ReceiveRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetBrowseRequest() *BrowseRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_BrowseRequest); ok {
+			return x.BrowseRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetBrowseResponse() *BrowseResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_BrowseResponse); ok {
+			return x.BrowseResponse
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAssetUpdated() *AssetUpdated {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_AssetUpdated); ok {
+			return x.AssetUpdated
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAnnotationWarning() *AnnotationWarning {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_AnnotationWarning); ok {
+			return x.AnnotationWarning
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Envelope) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type isEnvelope_Payload interface {
+	isEnvelope_Payload()
+}
+
+type Envelope_Ping struct {
+	Ping *KeepAlive `protobuf:"bytes,1,opt,name=ping,proto3,oneof"`
+}
+
+type Envelope_OpenView struct {
+	OpenView *OpenViewRequest `protobuf:"bytes,2,opt,name=open_view,json=openView,proto3,oneof"`
+}
+
+type Envelope_Annotation struct {
+	Annotation *AnnotationAction `protobuf:"bytes,3,opt,name=annotation,proto3,oneof"`
+}
+
+type Envelope_Status struct {
+	Status *ClientStatus `protobuf:"bytes,4,opt,name=status,proto3,oneof"`
+}
+
+type Envelope_Log struct {
+	Log *LogChunk `protobuf:"bytes,5,opt,name=log,proto3,oneof"`
+}
+
+type Envelope_LogSubscribe struct {
+	LogSubscribe *LogSubscribe `protobuf:"bytes,6,opt,name=log_subscribe,json=logSubscribe,proto3,oneof"`
+}
+
+type Envelope_Notification struct {
+	Notification *Notification `protobuf:"bytes,7,opt,name=notification,proto3,oneof"`
+}
+
+type Envelope_Resume struct {
+	Resume *Resume `protobuf:"bytes,8,opt,name=resume,proto3,oneof"`
+}
+
+type Envelope_Ack struct {
+	Ack *Ack `protobuf:"bytes,9,opt,name=ack,proto3,oneof"`
+}
+
+type Envelope_Hello struct {
+	Hello *Hello `protobuf:"bytes,10,opt,name=hello,proto3,oneof"`
+}
+
+type Envelope_Welcome struct {
+	Welcome *Welcome `protobuf:"bytes,11,opt,name=welcome,proto3,oneof"`
+}
+
+type Envelope_ZellijWebRequest struct {
+	ZellijWebRequest *ZellijWebRequest `protobuf:"bytes,12,opt,name=zellij_web_request,json=zellijWebRequest,proto3,oneof"`
+}
+
+type Envelope_ZellijWebResponse struct {
+	ZellijWebResponse *ZellijWebResponse `protobuf:"bytes,13,opt,name=zellij_web_response,json=zellijWebResponse,proto3,oneof"`
+}
+
+type Envelope_SessionRequest struct {
+	SessionRequest *SessionRequest `protobuf:"bytes,14,opt,name=session_request,json=sessionRequest,proto3,oneof"`
+}
+
+type Envelope_SessionResponse struct {
+	SessionResponse *SessionResponse `protobuf:"bytes,15,opt,name=session_response,json=sessionResponse,proto3,oneof"`
+}
+
+type Envelope_SessionEvent struct {
+	SessionEvent *SessionEvent `protobuf:"bytes,16,opt,name=session_event,json=sessionEvent,proto3,oneof"`
+}
+
+type Envelope_CaptureRequest struct {
+	CaptureRequest *CaptureRequest `protobuf:"bytes,17,opt,name=capture_request,json=captureRequest,proto3,oneof"`
+}
+
+type Envelope_CaptureResponse struct {
+	CaptureResponse *CaptureResponse `protobuf:"bytes,18,opt,name=capture_response,json=captureResponse,proto3,oneof"`
+}
+
+type Envelope_ActionRequest struct {
+	ActionRequest *ActionRequest `protobuf:"bytes,19,opt,name=action_request,json=actionRequest,proto3,oneof"`
+}
+
+type Envelope_ActionResponse struct {
+	ActionResponse *ActionResponse `protobuf:"bytes,20,opt,name=action_response,json=actionResponse,proto3,oneof"`
+}
+
+type Envelope_RunQuickAction struct {
+	RunQuickAction *RunQuickAction `protobuf:"bytes,21,opt,name=run_quick_action,json=runQuickAction,proto3,oneof"`
+}
+
+type Envelope_QuickActionResult struct {
+	QuickActionResult *QuickActionResult `protobuf:"bytes,22,opt,name=quick_action_result,json=quickActionResult,proto3,oneof"`
+}
+
+type Envelope_Prompt struct {
+	Prompt *Prompt `protobuf:"bytes,23,opt,name=prompt,proto3,oneof"`
+}
+
+type Envelope_PromptResponse struct {
+	PromptResponse *PromptResponse `protobuf:"bytes,24,opt,name=prompt_response,json=promptResponse,proto3,oneof"`
+}
+
+type Envelope_PromptClosed struct {
+	PromptClosed *PromptClosed `protobuf:"bytes,25,opt,name=prompt_closed,json=promptClosed,proto3,oneof"`
+}
+
+type Envelope_ClipboardSet struct {
+	ClipboardSet *ClipboardSet `protobuf:"bytes,26,opt,name=clipboard_set,json=clipboardSet,proto3,oneof"`
+}
+
+type Envelope_ClipboardRequest struct {
+	ClipboardRequest *ClipboardRequest `protobuf:"bytes,27,opt,name=clipboard_request,json=clipboardRequest,proto3,oneof"`
+}
+
+type Envelope_ClipboardContent struct {
+	ClipboardContent *ClipboardContent `protobuf:"bytes,28,opt,name=clipboard_content,json=clipboardContent,proto3,oneof"`
+}
+
+type Envelope_ReceiveRequest struct {
+	ReceiveRequest *ReceiveRequest `protobuf:"bytes,29,opt,name=receive_request,json=receiveRequest,proto3,oneof"`
+}
+
+type Envelope_BrowseRequest struct {
+	BrowseRequest *BrowseRequest `protobuf:"bytes,30,opt,name=browse_request,json=browseRequest,proto3,oneof"`
+}
+
+type Envelope_BrowseResponse struct {
+	BrowseResponse *BrowseResponse `protobuf:"bytes,31,opt,name=browse_response,json=browseResponse,proto3,oneof"`
+}
+
+type Envelope_AssetUpdated struct {
+	AssetUpdated *AssetUpdated `protobuf:"bytes,32,opt,name=asset_updated,json=assetUpdated,proto3,oneof"`
+}
+
+type Envelope_AnnotationWarning struct {
+	AnnotationWarning *AnnotationWarning `protobuf:"bytes,33,opt,name=annotation_warning,json=annotationWarning,proto3,oneof"`
+}
+
+func (*Envelope_Ping) isEnvelope_Payload() {}
+
+func (*Envelope_OpenView) isEnvelope_Payload() {}
+
+func (*Envelope_Annotation) isEnvelope_Payload() {}
+
+func (*Envelope_Status) isEnvelope_Payload() {}
+
+func (*Envelope_Log) isEnvelope_Payload() {}
+
+func (*Envelope_LogSubscribe) isEnvelope_Payload() {}
+
+func (*Envelope_Notification) isEnvelope_Payload() {}
+
+func (*Envelope_Resume) isEnvelope_Payload() {}
+
+func (*Envelope_Ack) isEnvelope_Payload() {}
+
+func (*Envelope_Hello) isEnvelope_Payload() {}
+
+func (*Envelope_Welcome) isEnvelope_Payload() {}
+
+func (*Envelope_ZellijWebRequest) isEnvelope_Payload() {}
+
+func (*Envelope_ZellijWebResponse) isEnvelope_Payload() {}
+
+func (*Envelope_SessionRequest) isEnvelope_Payload() {}
+
+func (*Envelope_SessionResponse) isEnvelope_Payload() {}
+
+func (*Envelope_SessionEvent) isEnvelope_Payload() {}
+
+func (*Envelope_CaptureRequest) isEnvelope_Payload() {}
+
+func (*Envelope_CaptureResponse) isEnvelope_Payload() {}
+
+func (*Envelope_ActionRequest) isEnvelope_Payload() {}
+
+func (*Envelope_ActionResponse) isEnvelope_Payload() {}
+
+func (*Envelope_RunQuickAction) isEnvelope_Payload() {}
+
+func (*Envelope_QuickActionResult) isEnvelope_Payload() {}
+
+func (*Envelope_Prompt) isEnvelope_Payload() {}
+
+func (*Envelope_PromptResponse) isEnvelope_Payload() {}
+
+func (*Envelope_PromptClosed) isEnvelope_Payload() {}
+
+func (*Envelope_ClipboardSet) isEnvelope_Payload() {}
+
+func (*Envelope_ClipboardRequest) isEnvelope_Payload() {}
+
+func (*Envelope_ClipboardContent) isEnvelope_Payload() {}
+
+func (*Envelope_ReceiveRequest) isEnvelope_Payload() {}
+
+func (*Envelope_BrowseRequest) isEnvelope_Payload() {}
+
+func (*Envelope_BrowseResponse) isEnvelope_Payload() {}
+
+func (*Envelope_AssetUpdated) isEnvelope_Payload() {}
+
+func (*Envelope_AnnotationWarning) isEnvelope_Payload() {}
+
+type KeepAlive struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeepAlive) Reset() {
+	*x = KeepAlive{}
+	mi := &file_proto_zelland_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeepAlive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeepAlive) ProtoMessage() {}
+
+func (x *KeepAlive) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeepAlive.ProtoReflect.Descriptor instead.
+func (*KeepAlive) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *KeepAlive) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type OpenViewRequest struct {
+	state    protoimpl.MessageState   `protogen:"open.v1"`
+	AssetId  string                   `protobuf:"bytes,1,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	Url      string                   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"` // https://host/assets/xyz
+	FileType OpenViewRequest_FileType `protobuf:"varint,3,opt,name=file_type,json=fileType,proto3,enum=zelland.OpenViewRequest_FileType" json:"file_type,omitempty"`
+	Title    string                   `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	// For Markdown, initial state might be included here or fetched separately
+	Origin        *Origin `protobuf:"bytes,5,opt,name=origin,proto3" json:"origin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenViewRequest) Reset() {
+	*x = OpenViewRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenViewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenViewRequest) ProtoMessage() {}
+
+func (x *OpenViewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenViewRequest.ProtoReflect.Descriptor instead.
+func (*OpenViewRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OpenViewRequest) GetAssetId() string {
+	if x != nil {
+		return x.AssetId
+	}
+	return ""
+}
+
+func (x *OpenViewRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *OpenViewRequest) GetFileType() OpenViewRequest_FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return OpenViewRequest_UNKNOWN
+}
+
+func (x *OpenViewRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *OpenViewRequest) GetOrigin() *Origin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+// Where a CLI request was made, so the app can relate it to a terminal tab.
+type Origin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZellijSession string                 `protobuf:"bytes,1,opt,name=zellij_session,json=zellijSession,proto3" json:"zellij_session,omitempty"` // $ZELLIJ_SESSION_NAME
+	ZellijPaneId  string                 `protobuf:"bytes,2,opt,name=zellij_pane_id,json=zellijPaneId,proto3" json:"zellij_pane_id,omitempty"`  // $ZELLIJ_PANE_ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Origin) Reset() {
+	*x = Origin{}
+	mi := &file_proto_zelland_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Origin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Origin) ProtoMessage() {}
+
+func (x *Origin) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Origin.ProtoReflect.Descriptor instead.
+func (*Origin) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Origin) GetZellijSession() string {
+	if x != nil {
+		return x.ZellijSession
+	}
+	return ""
+}
+
+func (x *Origin) GetZellijPaneId() string {
+	if x != nil {
+		return x.ZellijPaneId
+	}
+	return ""
+}
+
+type AnnotationAction struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Type          AnnotationAction_ActionType `protobuf:"varint,1,opt,name=type,proto3,enum=zelland.AnnotationAction_ActionType" json:"type,omitempty"`
+	FilePath      string                      `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"` // Or asset_id
+	Data          *AnnotationData             `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnotationAction) Reset() {
+	*x = AnnotationAction{}
+	mi := &file_proto_zelland_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnotationAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnotationAction) ProtoMessage() {}
+
+func (x *AnnotationAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnotationAction.ProtoReflect.Descriptor instead.
+func (*AnnotationAction) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AnnotationAction) GetType() AnnotationAction_ActionType {
+	if x != nil {
+		return x.Type
+	}
+	return AnnotationAction_CREATE
+}
+
+func (x *AnnotationAction) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *AnnotationAction) GetData() *AnnotationData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type AnnotationData struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TargetText  string                 `protobuf:"bytes,2,opt,name=target_text,json=targetText,proto3" json:"target_text,omitempty"`
+	ContextHash string                 `protobuf:"bytes,3,opt,name=context_hash,json=contextHash,proto3" json:"context_hash,omitempty"` // SHA of surrounding paragraph for robust anchoring
+	Body        string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`                                  // The user's note
+	Timestamp   int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	User        string                 `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	ParentId    string                 `protobuf:"bytes,7,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Replies: the annotation or reply answered
+	Status      AnnotationData_Status  `protobuf:"varint,8,opt,name=status,proto3,enum=zelland.AnnotationData_Status" json:"status,omitempty"`
+	ResolvedBy  string                 `protobuf:"bytes,9,opt,name=resolved_by,json=resolvedBy,proto3" json:"resolved_by,omitempty"`
+	ResolvedAt  int64                  `protobuf:"varint,10,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	// Server -> client: the thread's replies, oldest first
+	Replies []*AnnotationData `protobuf:"bytes,11,rep,name=replies,proto3" json:"replies,omitempty"`
+	// Where the annotation points in an image, PDF or source file. Without
+	// one, target_text and context_hash anchor it in text.
+	Anchor        *Anchor `protobuf:"bytes,12,opt,name=anchor,proto3" json:"anchor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnotationData) Reset() {
+	*x = AnnotationData{}
+	mi := &file_proto_zelland_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnotationData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnotationData) ProtoMessage() {}
+
+func (x *AnnotationData) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnotationData.ProtoReflect.Descriptor instead.
+func (*AnnotationData) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AnnotationData) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetTargetText() string {
+	if x != nil {
+		return x.TargetText
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetContextHash() string {
+	if x != nil {
+		return x.ContextHash
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AnnotationData) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetStatus() AnnotationData_Status {
+	if x != nil {
+		return x.Status
+	}
+	return AnnotationData_OPEN
+}
+
+func (x *AnnotationData) GetResolvedBy() string {
+	if x != nil {
+		return x.ResolvedBy
+	}
+	return ""
+}
+
+func (x *AnnotationData) GetResolvedAt() int64 {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return 0
+}
+
+func (x *AnnotationData) GetReplies() []*AnnotationData {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
+func (x *AnnotationData) GetAnchor() *Anchor {
+	if x != nil {
+		return x.Anchor
+	}
+	return nil
+}
+
+type Anchor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  Anchor_Kind            `protobuf:"varint,1,opt,name=kind,proto3,enum=zelland.Anchor_Kind" json:"kind,omitempty"`
+	// RECT, and PAGE without a quote: fractions of the image or page size,
+	// from the top left
+	X             float64 `protobuf:"fixed64,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64 `protobuf:"fixed64,3,opt,name=y,proto3" json:"y,omitempty"`
+	W             float64 `protobuf:"fixed64,4,opt,name=w,proto3" json:"w,omitempty"`
+	H             float64 `protobuf:"fixed64,5,opt,name=h,proto3" json:"h,omitempty"`
+	Page          uint32  `protobuf:"varint,6,opt,name=page,proto3" json:"page,omitempty"`                            // PAGE, from 1
+	Quote         string  `protobuf:"bytes,7,opt,name=quote,proto3" json:"quote,omitempty"`                           // PAGE: text on the page
+	StartLine     uint32  `protobuf:"varint,8,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"` // LINES, from 1, inclusive
+	EndLine       uint32  `protobuf:"varint,9,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Anchor) Reset() {
+	*x = Anchor{}
+	mi := &file_proto_zelland_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Anchor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Anchor) ProtoMessage() {}
+
+func (x *Anchor) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Anchor.ProtoReflect.Descriptor instead.
+func (*Anchor) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Anchor) GetKind() Anchor_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Anchor_TEXT
+}
+
+func (x *Anchor) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Anchor) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Anchor) GetW() float64 {
+	if x != nil {
+		return x.W
+	}
+	return 0
+}
+
+func (x *Anchor) GetH() float64 {
+	if x != nil {
+		return x.H
+	}
+	return 0
+}
+
+func (x *Anchor) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *Anchor) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *Anchor) GetStartLine() uint32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *Anchor) GetEndLine() uint32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+type ClientStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         ClientStatus_ViewState `protobuf:"varint,1,opt,name=state,proto3,enum=zelland.ClientStatus_ViewState" json:"state,omitempty"`
+	ActiveAssetId string                 `protobuf:"bytes,2,opt,name=active_asset_id,json=activeAssetId,proto3" json:"active_asset_id,omitempty"`
+	Foreground    bool                   `protobuf:"varint,3,opt,name=foreground,proto3" json:"foreground,omitempty"`                           // The app is visible on screen
+	ZellijSession string                 `protobuf:"bytes,4,opt,name=zellij_session,json=zellijSession,proto3" json:"zellij_session,omitempty"` // Zellij web session of the active terminal tab
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientStatus) Reset() {
+	*x = ClientStatus{}
+	mi := &file_proto_zelland_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientStatus) ProtoMessage() {}
+
+func (x *ClientStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientStatus.ProtoReflect.Descriptor instead.
+func (*ClientStatus) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ClientStatus) GetState() ClientStatus_ViewState {
+	if x != nil {
+		return x.State
+	}
+	return ClientStatus_TERMINAL
+}
+
+func (x *ClientStatus) GetActiveAssetId() string {
+	if x != nil {
+		return x.ActiveAssetId
+	}
+	return ""
+}
+
+func (x *ClientStatus) GetForeground() bool {
+	if x != nil {
+		return x.Foreground
+	}
+	return false
+}
+
+func (x *ClientStatus) GetZellijSession() string {
+	if x != nil {
+		return x.ZellijSession
+	}
+	return ""
+}
+
+// A batch of lines pushed for a LOG view. asset_id in the OpenViewRequest is
+// the stream_id.
+type LogChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Lines         []*LogLine             `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	Event         LogChunk_Event         `protobuf:"varint,3,opt,name=event,proto3,enum=zelland.LogChunk_Event" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogChunk) Reset() {
+	*x = LogChunk{}
+	mi := &file_proto_zelland_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogChunk) ProtoMessage() {}
+
+func (x *LogChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogChunk.ProtoReflect.Descriptor instead.
+func (*LogChunk) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LogChunk) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *LogChunk) GetLines() []*LogLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *LogChunk) GetEvent() LogChunk_Event {
+	if x != nil {
+		return x.Event
+	}
+	return LogChunk_NONE
+}
+
+type LogLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"` // Monotonically increasing per stream; gaps mean filtered or evicted lines
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_proto_zelland_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LogLine) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *LogLine) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Sent by the client to start (or change) receiving a log stream.
+type LogSubscribe struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	AfterSeq      uint64                 `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"` // Replay buffered lines with seq greater than this
+	Filter        string                 `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`                      // Optional RE2 regular expression applied server-side
+	Unsubscribe   bool                   `protobuf:"varint,4,opt,name=unsubscribe,proto3" json:"unsubscribe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogSubscribe) Reset() {
+	*x = LogSubscribe{}
+	mi := &file_proto_zelland_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogSubscribe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogSubscribe) ProtoMessage() {}
+
+func (x *LogSubscribe) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogSubscribe.ProtoReflect.Descriptor instead.
+func (*LogSubscribe) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LogSubscribe) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *LogSubscribe) GetAfterSeq() uint64 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+func (x *LogSubscribe) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *LogSubscribe) GetUnsubscribe() bool {
+	if x != nil {
+		return x.Unsubscribe
+	}
+	return false
+}
+
+// A user-facing alert, e.g. from `zelland notify` or `zelland done`.
+type Notification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Urgency       Notification_Urgency   `protobuf:"varint,4,opt,name=urgency,proto3,enum=zelland.Notification_Urgency" json:"urgency,omitempty"`
+	Action        *OpenViewRequest       `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"` // Optional view to open when the notification is tapped
+	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Origin        *Origin                `protobuf:"bytes,7,opt,name=origin,proto3" json:"origin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_proto_zelland_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Notification) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Notification) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Notification) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Notification) GetUrgency() Notification_Urgency {
+	if x != nil {
+		return x.Urgency
+	}
+	return Notification_NORMAL
+}
+
+func (x *Notification) GetAction() *OpenViewRequest {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *Notification) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Notification) GetOrigin() *Origin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+// Sent by a (re)connecting client so the server can replay retained
+// envelopes it missed.
+type Resume struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LastSeq       uint64                 `protobuf:"varint,1,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"` // Highest Envelope.seq the client has processed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resume) Reset() {
+	*x = Resume{}
+	mi := &file_proto_zelland_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resume) ProtoMessage() {}
+
+func (x *Resume) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resume.ProtoReflect.Descriptor instead.
+func (*Resume) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Resume) GetLastSeq() uint64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+// Sent by the client for every envelope that carries a request_id.
+type Ack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Status        Ack_Status             `protobuf:"varint,2,opt,name=status,proto3,enum=zelland.Ack_Status" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	mi := &file_proto_zelland_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Ack) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Ack) GetStatus() Ack_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Ack_RECEIVED
+}
+
+func (x *Ack) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// First message a client sends after connecting.
+type Hello struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolVersion uint32                 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	AppVersion      string                 `protobuf:"bytes,2,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
+	DeviceName      string                 `protobuf:"bytes,3,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	// View types the client can render. Others are downgraded to UNKNOWN.
+	SupportedFileTypes []OpenViewRequest_FileType `protobuf:"varint,4,rep,packed,name=supported_file_types,json=supportedFileTypes,proto3,enum=zelland.OpenViewRequest_FileType" json:"supported_file_types,omitempty"`
+	// Optional capabilities, e.g. "ack", "notifications", "log_stream"
+	Features      []string `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
+	LastSeq       uint64   `protobuf:"varint,6,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`   // Same as Resume.last_seq
+	DeviceId      string   `protobuf:"bytes,7,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // Stable per installation; used for targeted delivery
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hello) Reset() {
+	*x = Hello{}
+	mi := &file_proto_zelland_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hello) ProtoMessage() {}
+
+func (x *Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hello.ProtoReflect.Descriptor instead.
+func (*Hello) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Hello) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *Hello) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *Hello) GetSupportedFileTypes() []OpenViewRequest_FileType {
+	if x != nil {
+		return x.SupportedFileTypes
+	}
+	return nil
+}
+
+func (x *Hello) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Hello) GetLastSeq() uint64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *Hello) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+// The server's answer to Hello.
+type Welcome struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolVersion uint32                 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	ServerVersion   string                 `protobuf:"bytes,2,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	Features        []string               `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"`
+	ZellijActions   []string               `protobuf:"bytes,4,rep,name=zellij_actions,json=zellijActions,proto3" json:"zellij_actions,omitempty"` // Actions accepted in ActionRequest
+	QuickActions    []*QuickAction         `protobuf:"bytes,5,rep,name=quick_actions,json=quickActions,proto3" json:"quick_actions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Welcome) Reset() {
+	*x = Welcome{}
+	mi := &file_proto_zelland_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Welcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Welcome) ProtoMessage() {}
+
+func (x *Welcome) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Welcome.ProtoReflect.Descriptor instead.
+func (*Welcome) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Welcome) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Welcome) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+func (x *Welcome) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Welcome) GetZellijActions() []string {
+	if x != nil {
+		return x.ZellijActions
+	}
+	return nil
+}
+
+func (x *Welcome) GetQuickActions() []*QuickAction {
+	if x != nil {
+		return x.QuickActions
+	}
+	return nil
+}
+
+// A named command from the daemon config, shown as a button.
+type QuickAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	Confirm       bool                   `protobuf:"varint,4,opt,name=confirm,proto3" json:"confirm,omitempty"` // Ask the user before sending RunQuickAction
+	Output        QuickAction_Output     `protobuf:"varint,5,opt,name=output,proto3,enum=zelland.QuickAction_Output" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuickAction) Reset() {
+	*x = QuickAction{}
+	mi := &file_proto_zelland_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuickAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuickAction) ProtoMessage() {}
+
+func (x *QuickAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuickAction.ProtoReflect.Descriptor instead.
+func (*QuickAction) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *QuickAction) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QuickAction) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *QuickAction) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *QuickAction) GetConfirm() bool {
+	if x != nil {
+		return x.Confirm
+	}
+	return false
+}
+
+func (x *QuickAction) GetOutput() QuickAction_Output {
+	if x != nil {
+		return x.Output
+	}
+	return QuickAction_LOG
+}
+
+// Controls the `zellij web` server supervised by the daemon. Requires an
+// authenticated connection.
+type ZellijWebRequest struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Action        ZellijWebRequest_Action `protobuf:"varint,1,opt,name=action,proto3,enum=zelland.ZellijWebRequest_Action" json:"action,omitempty"`
+	LogLines      uint32                  `protobuf:"varint,2,opt,name=log_lines,json=logLines,proto3" json:"log_lines,omitempty"` // Include up to this many recent output lines
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ZellijWebRequest) Reset() {
+	*x = ZellijWebRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ZellijWebRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZellijWebRequest) ProtoMessage() {}
+
+func (x *ZellijWebRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZellijWebRequest.ProtoReflect.Descriptor instead.
+func (*ZellijWebRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ZellijWebRequest) GetAction() ZellijWebRequest_Action {
+	if x != nil {
+		return x.Action
+	}
+	return ZellijWebRequest_STATUS
+}
+
+func (x *ZellijWebRequest) GetLogLines() uint32 {
+	if x != nil {
+		return x.LogLines
+	}
+	return 0
+}
+
+type ZellijWebResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Running       bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`       // The web server answers on its port
+	Supervised    bool                   `protobuf:"varint,2,opt,name=supervised,proto3" json:"supervised,omitempty"` // Started by the daemon and restarted if it exits
+	Pid           int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	Port          uint32                 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	StartedAt     int64                  `protobuf:"varint,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Restarts      uint32                 `protobuf:"varint,6,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	LastExit      string                 `protobuf:"bytes,7,opt,name=last_exit,json=lastExit,proto3" json:"last_exit,omitempty"`
+	TokenName     string                 `protobuf:"bytes,8,opt,name=token_name,json=tokenName,proto3" json:"token_name,omitempty"` // CREATE_TOKEN only
+	Token         string                 `protobuf:"bytes,9,opt,name=token,proto3" json:"token,omitempty"`                          // CREATE_TOKEN only
+	LogLines      []string               `protobuf:"bytes,10,rep,name=log_lines,json=logLines,proto3" json:"log_lines,omitempty"`
+	LogStreamId   string                 `protobuf:"bytes,11,opt,name=log_stream_id,json=logStreamId,proto3" json:"log_stream_id,omitempty"` // Subscribe with LogSubscribe for live output
+	Error         string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`                                  // Empty on success
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ZellijWebResponse) Reset() {
+	*x = ZellijWebResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ZellijWebResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZellijWebResponse) ProtoMessage() {}
+
+func (x *ZellijWebResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZellijWebResponse.ProtoReflect.Descriptor instead.
+func (*ZellijWebResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ZellijWebResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ZellijWebResponse) GetSupervised() bool {
+	if x != nil {
+		return x.Supervised
+	}
+	return false
+}
+
+func (x *ZellijWebResponse) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ZellijWebResponse) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ZellijWebResponse) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *ZellijWebResponse) GetRestarts() uint32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *ZellijWebResponse) GetLastExit() string {
+	if x != nil {
+		return x.LastExit
+	}
+	return ""
+}
+
+func (x *ZellijWebResponse) GetTokenName() string {
+	if x != nil {
+		return x.TokenName
+	}
+	return ""
+}
+
+func (x *ZellijWebResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ZellijWebResponse) GetLogLines() []string {
+	if x != nil {
+		return x.LogLines
+	}
+	return nil
+}
+
+func (x *ZellijWebResponse) GetLogStreamId() string {
+	if x != nil {
+		return x.LogStreamId
+	}
+	return ""
+}
+
+func (x *ZellijWebResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Lists and manages Zellij sessions. Requires an authenticated connection.
+type SessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        SessionRequest_Action  `protobuf:"varint,1,opt,name=action,proto3,enum=zelland.SessionRequest_Action" json:"action,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Layout        string                 `protobuf:"bytes,3,opt,name=layout,proto3" json:"layout,omitempty"`                  // CREATE: layout name, e.g. "compact"
+	NewName       string                 `protobuf:"bytes,4,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"` // RENAME
+	Force         bool                   `protobuf:"varint,5,opt,name=force,proto3" json:"force,omitempty"`                   // DELETE: kill the session first if it is running
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionRequest) Reset() {
+	*x = SessionRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionRequest) ProtoMessage() {}
+
+func (x *SessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionRequest.ProtoReflect.Descriptor instead.
+func (*SessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SessionRequest) GetAction() SessionRequest_Action {
+	if x != nil {
+		return x.Action
+	}
+	return SessionRequest_LIST
+}
+
+func (x *SessionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SessionRequest) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+func (x *SessionRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+func (x *SessionRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type SessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*ZellijSession       `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"` // All sessions after the action
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`       // Empty on success
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionResponse) Reset() {
+	*x = SessionResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionResponse) ProtoMessage() {}
+
+func (x *SessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionResponse.ProtoReflect.Descriptor instead.
+func (*SessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SessionResponse) GetSessions() []*ZellijSession {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *SessionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ZellijSession struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt       int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix seconds; approximate
+	AttachedClients uint32                 `protobuf:"varint,3,opt,name=attached_clients,json=attachedClients,proto3" json:"attached_clients,omitempty"`
+	Exited          bool                   `protobuf:"varint,4,opt,name=exited,proto3" json:"exited,omitempty"` // Can be resurrected by attaching
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ZellijSession) Reset() {
+	*x = ZellijSession{}
+	mi := &file_proto_zelland_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ZellijSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZellijSession) ProtoMessage() {}
+
+func (x *ZellijSession) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZellijSession.ProtoReflect.Descriptor instead.
+func (*ZellijSession) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ZellijSession) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ZellijSession) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ZellijSession) GetAttachedClients() uint32 {
+	if x != nil {
+		return x.AttachedClients
+	}
+	return 0
+}
+
+func (x *ZellijSession) GetExited() bool {
+	if x != nil {
+		return x.Exited
+	}
+	return false
+}
+
+// Pushed to authenticated clients with the "sessions" feature when a
+// session appears, disappears or exits.
+type SessionEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          SessionEvent_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=zelland.SessionEvent_Type" json:"type,omitempty"`
+	Session       *ZellijSession         `protobuf:"bytes,2,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionEvent) Reset() {
+	*x = SessionEvent{}
+	mi := &file_proto_zelland_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionEvent) ProtoMessage() {}
+
+func (x *SessionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionEvent.ProtoReflect.Descriptor instead.
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SessionEvent) GetType() SessionEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return SessionEvent_ADDED
+}
+
+func (x *SessionEvent) GetSession() *ZellijSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+// Snapshots a pane's scrollback as a TEXT view. Requires an authenticated
+// connection.
+type CaptureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       string                 `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`                          // Defaults to ClientStatus.zellij_session
+	ScreenOnly    bool                   `protobuf:"varint,2,opt,name=screen_only,json=screenOnly,proto3" json:"screen_only,omitempty"` // Only the visible screen, not the full scrollback
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureRequest) Reset() {
+	*x = CaptureRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureRequest) ProtoMessage() {}
+
+func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureRequest.ProtoReflect.Descriptor instead.
+func (*CaptureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CaptureRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *CaptureRequest) GetScreenOnly() bool {
+	if x != nil {
+		return x.ScreenOnly
+	}
+	return false
+}
+
+type CaptureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	View          *OpenViewRequest       `protobuf:"bytes,1,opt,name=view,proto3" json:"view,omitempty"` // Open this to show the capture
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureResponse) Reset() {
+	*x = CaptureResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureResponse) ProtoMessage() {}
+
+func (x *CaptureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureResponse.ProtoReflect.Descriptor instead.
+func (*CaptureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CaptureResponse) GetView() *OpenViewRequest {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *CaptureResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Runs `zellij action <action> <args>` in a session, or with action "run",
+// `zellij run <args> -- <command>`. Only actions allowed in the daemon config
+// are accepted. Requires an authenticated connection.
+type ActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       string                 `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"` // Defaults to ClientStatus.zellij_session
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`   // e.g. "new-pane", "focus-next-pane", "run"
+	Args          []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	Command       []string               `protobuf:"bytes,4,rep,name=command,proto3" json:"command,omitempty"` // "run" only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionRequest) Reset() {
+	*x = ActionRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionRequest) ProtoMessage() {}
+
+func (x *ActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionRequest.ProtoReflect.Descriptor instead.
+func (*ActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ActionRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *ActionRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ActionRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ActionRequest) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+type ActionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Output        string                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // Empty on success
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionResponse) Reset() {
+	*x = ActionResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionResponse) ProtoMessage() {}
+
+func (x *ActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionResponse.ProtoReflect.Descriptor instead.
+func (*ActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ActionResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ActionResponse) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ActionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Runs (or stops) a quick action. Requires an authenticated connection. The
+// server answers with QuickActionResult once the command exits.
+type RunQuickAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Confirmed     bool                   `protobuf:"varint,2,opt,name=confirmed,proto3" json:"confirmed,omitempty"` // Required for actions with confirm set
+	Cancel        bool                   `protobuf:"varint,3,opt,name=cancel,proto3" json:"cancel,omitempty"`       // Stop the running instance instead
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunQuickAction) Reset() {
+	*x = RunQuickAction{}
+	mi := &file_proto_zelland_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunQuickAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunQuickAction) ProtoMessage() {}
+
+func (x *RunQuickAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunQuickAction.ProtoReflect.Descriptor instead.
+func (*RunQuickAction) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RunQuickAction) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RunQuickAction) GetConfirmed() bool {
+	if x != nil {
+		return x.Confirmed
+	}
+	return false
+}
+
+func (x *RunQuickAction) GetCancel() bool {
+	if x != nil {
+		return x.Cancel
+	}
+	return false
+}
+
+type QuickActionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	StreamId      string                 `protobuf:"bytes,2,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`  // LOG stream with the command's output
+	ExitCode      int32                  `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"` // -1 if it could not be started or was killed
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuickActionResult) Reset() {
+	*x = QuickActionResult{}
+	mi := &file_proto_zelland_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuickActionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuickActionResult) ProtoMessage() {}
+
+func (x *QuickActionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuickActionResult.ProtoReflect.Descriptor instead.
+func (*QuickActionResult) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *QuickActionResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QuickActionResult) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *QuickActionResult) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *QuickActionResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *QuickActionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A question from `zelland ask` that a script is blocked on.
+type Prompt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	Choices       []string               `protobuf:"bytes,3,rep,name=choices,proto3" json:"choices,omitempty"`                       // Empty means a free-text answer
+	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix seconds; the script gives up then
+	Origin        *Origin                `protobuf:"bytes,5,opt,name=origin,proto3" json:"origin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prompt) Reset() {
+	*x = Prompt{}
+	mi := &file_proto_zelland_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prompt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prompt) ProtoMessage() {}
+
+func (x *Prompt) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prompt.ProtoReflect.Descriptor instead.
+func (*Prompt) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Prompt) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Prompt) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *Prompt) GetChoices() []string {
+	if x != nil {
+		return x.Choices
+	}
+	return nil
+}
+
+func (x *Prompt) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Prompt) GetOrigin() *Origin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+// Sent by the client when the user answers (or dismisses) a Prompt.
+type PromptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Answer        string                 `protobuf:"bytes,2,opt,name=answer,proto3" json:"answer,omitempty"`        // One of the choices, or free text
+	Dismissed     bool                   `protobuf:"varint,3,opt,name=dismissed,proto3" json:"dismissed,omitempty"` // The user declined to answer
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptResponse) Reset() {
+	*x = PromptResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptResponse) ProtoMessage() {}
+
+func (x *PromptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptResponse.ProtoReflect.Descriptor instead.
+func (*PromptResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PromptResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PromptResponse) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *PromptResponse) GetDismissed() bool {
+	if x != nil {
+		return x.Dismissed
+	}
+	return false
+}
+
+// Tells clients to remove a Prompt, ClipboardRequest or ReceiveRequest that
+// was answered elsewhere or expired.
+type PromptClosed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptClosed) Reset() {
+	*x = PromptClosed{}
+	mi := &file_proto_zelland_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptClosed) ProtoMessage() {}
+
+func (x *PromptClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptClosed.ProtoReflect.Descriptor instead.
+func (*PromptClosed) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PromptClosed) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Puts content on the device's clipboard (`zelland copy`). Sent only to
+// clients with the "clipboard" feature and never retained in the outbox.
+type ClipboardSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"` // "text/plain; charset=utf-8", "image/png", ...
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Origin        *Origin                `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClipboardSet) Reset() {
+	*x = ClipboardSet{}
+	mi := &file_proto_zelland_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClipboardSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClipboardSet) ProtoMessage() {}
+
+func (x *ClipboardSet) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClipboardSet.ProtoReflect.Descriptor instead.
+func (*ClipboardSet) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ClipboardSet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClipboardSet) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *ClipboardSet) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ClipboardSet) GetOrigin() *Origin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+// Asks for the device's current clipboard (`zelland paste`). The client must
+// ask the user before answering with a ClipboardContent of the same id.
+type ClipboardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`    // Larger content must be refused with an error
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix seconds; the script gives up then
+	Origin        *Origin                `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClipboardRequest) Reset() {
+	*x = ClipboardRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClipboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClipboardRequest) ProtoMessage() {}
+
+func (x *ClipboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClipboardRequest.ProtoReflect.Descriptor instead.
+func (*ClipboardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ClipboardRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClipboardRequest) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *ClipboardRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *ClipboardRequest) GetOrigin() *Origin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+// Sent by the client in reply to a ClipboardRequest.
+type ClipboardContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Denied        bool                   `protobuf:"varint,4,opt,name=denied,proto3" json:"denied,omitempty"` // The user declined to share the clipboard
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`    // E.g. empty clipboard or content over max_bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClipboardContent) Reset() {
+	*x = ClipboardContent{}
+	mi := &file_proto_zelland_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClipboardContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClipboardContent) ProtoMessage() {}
+
+func (x *ClipboardContent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClipboardContent.ProtoReflect.Descriptor instead.
+func (*ClipboardContent) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ClipboardContent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClipboardContent) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *ClipboardContent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ClipboardContent) GetDenied() bool {
+	if x != nil {
+		return x.Denied
+	}
+	return false
+}
+
+func (x *ClipboardContent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Asks the user to pick files to upload (`zelland receive`). Uploads that
+// name this id as receive_id go to the waiting directory. Sent only to
+// clients with the "upload" feature.
+type ReceiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Dir           string                 `protobuf:"bytes,2,opt,name=dir,proto3" json:"dir,omitempty"`                               // Destination shown to the user
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix seconds; the script gives up then
+	Origin        *Origin                `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Envelope) Reset() {
-	*x = Envelope{}
-	mi := &file_proto_zelland_proto_msgTypes[0]
+func (x *ReceiveRequest) Reset() {
+	*x = ReceiveRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Envelope) String() string {
+func (x *ReceiveRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Envelope) ProtoMessage() {}
+func (*ReceiveRequest) ProtoMessage() {}
 
-func (x *Envelope) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[0]
+func (x *ReceiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,104 +3718,68 @@ func (x *Envelope) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
-func (*Envelope) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{0}
-}
-
-func (x *Envelope) GetPayload() isEnvelope_Payload {
-	if x != nil {
-		return x.Payload
-	}
-	return nil
+// Deprecated: Use ReceiveRequest.ProtoReflect.Descriptor instead.
+func (*ReceiveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *Envelope) GetPing() *KeepAlive {
+func (x *ReceiveRequest) GetId() string {
 	if x != nil {
-		if x, ok := x.Payload.(*Envelope_Ping); ok {
-			return x.Ping
-		}
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *Envelope) GetOpenView() *OpenViewRequest {
+func (x *ReceiveRequest) GetDir() string {
 	if x != nil {
-		if x, ok := x.Payload.(*Envelope_OpenView); ok {
-			return x.OpenView
-		}
+		return x.Dir
 	}
-	return nil
+	return ""
 }
 
-func (x *Envelope) GetAnnotation() *AnnotationAction {
+func (x *ReceiveRequest) GetExpiresAt() int64 {
 	if x != nil {
-		if x, ok := x.Payload.(*Envelope_Annotation); ok {
-			return x.Annotation
-		}
+		return x.ExpiresAt
 	}
-	return nil
+	return 0
 }
 
-func (x *Envelope) GetStatus() *ClientStatus {
+func (x *ReceiveRequest) GetOrigin() *Origin {
 	if x != nil {
-		if x, ok := x.Payload.(*Envelope_Status); ok {
-			return x.Status
-		}
+		return x.Origin
 	}
 	return nil
 }
 
-type isEnvelope_Payload interface {
-	isEnvelope_Payload()
-}
-
-type Envelope_Ping struct {
-	Ping *KeepAlive `protobuf:"bytes,1,opt,name=ping,proto3,oneof"`
-}
-
-type Envelope_OpenView struct {
-	OpenView *OpenViewRequest `protobuf:"bytes,2,opt,name=open_view,json=openView,proto3,oneof"`
-}
-
-type Envelope_Annotation struct {
-	Annotation *AnnotationAction `protobuf:"bytes,3,opt,name=annotation,proto3,oneof"`
-}
-
-type Envelope_Status struct {
-	Status *ClientStatus `protobuf:"bytes,4,opt,name=status,proto3,oneof"`
-}
-
-func (*Envelope_Ping) isEnvelope_Payload() {}
-
-func (*Envelope_OpenView) isEnvelope_Payload() {}
-
-func (*Envelope_Annotation) isEnvelope_Payload() {}
-
-func (*Envelope_Status) isEnvelope_Payload() {}
-
-type KeepAlive struct {
+// Lists or searches files under the daemon's browse_roots, or opens one as a
+// view. Requires an authenticated connection.
+type BrowseRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Action        BrowseRequest_Action   `protobuf:"varint,1,opt,name=action,proto3,enum=zelland.BrowseRequest_Action" json:"action,omitempty"`
+	Root          string                 `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`   // One of BrowseResponse.roots
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`   // Relative to root, "/"-separated
+	Query         string                 `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"` // SEARCH
+	Offset        uint32                 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         uint32                 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"` // Default 100, at most 1000
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *KeepAlive) Reset() {
-	*x = KeepAlive{}
-	mi := &file_proto_zelland_proto_msgTypes[1]
+func (x *BrowseRequest) Reset() {
+	*x = BrowseRequest{}
+	mi := &file_proto_zelland_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *KeepAlive) String() string {
+func (x *BrowseRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KeepAlive) ProtoMessage() {}
+func (*BrowseRequest) ProtoMessage() {}
 
-func (x *KeepAlive) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[1]
+func (x *BrowseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,43 +3790,81 @@ func (x *KeepAlive) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KeepAlive.ProtoReflect.Descriptor instead.
-func (*KeepAlive) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use BrowseRequest.ProtoReflect.Descriptor instead.
+func (*BrowseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *KeepAlive) GetTimestamp() int64 {
+func (x *BrowseRequest) GetAction() BrowseRequest_Action {
 	if x != nil {
-		return x.Timestamp
+		return x.Action
+	}
+	return BrowseRequest_LIST
+}
+
+func (x *BrowseRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *BrowseRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *BrowseRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *BrowseRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
 	}
 	return 0
 }
 
-type OpenViewRequest struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	AssetId       string                   `protobuf:"bytes,1,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
-	Url           string                   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"` // https://host/assets/xyz
-	FileType      OpenViewRequest_FileType `protobuf:"varint,3,opt,name=file_type,json=fileType,proto3,enum=zelland.OpenViewRequest_FileType" json:"file_type,omitempty"`
-	Title         string                   `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"` // For Markdown, initial state might be included here or fetched separately
+func (x *BrowseRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type BrowseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roots         []string               `protobuf:"bytes,1,rep,name=roots,proto3" json:"roots,omitempty"`
+	Root          string                 `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Entries       []*BrowseEntry         `protobuf:"bytes,4,rep,name=entries,proto3" json:"entries,omitempty"`
+	Total         uint32                 `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"` // Number of entries before pagination
+	View          *OpenViewRequest       `protobuf:"bytes,6,opt,name=view,proto3" json:"view,omitempty"`    // OPEN: show it as if it came in an OpenView
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`  // Empty on success
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OpenViewRequest) Reset() {
-	*x = OpenViewRequest{}
-	mi := &file_proto_zelland_proto_msgTypes[2]
+func (x *BrowseResponse) Reset() {
+	*x = BrowseResponse{}
+	mi := &file_proto_zelland_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OpenViewRequest) String() string {
+func (x *BrowseResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OpenViewRequest) ProtoMessage() {}
+func (*BrowseResponse) ProtoMessage() {}
 
-func (x *OpenViewRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[2]
+func (x *BrowseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -362,63 +3875,89 @@ func (x *OpenViewRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OpenViewRequest.ProtoReflect.Descriptor instead.
-func (*OpenViewRequest) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BrowseResponse.ProtoReflect.Descriptor instead.
+func (*BrowseResponse) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *OpenViewRequest) GetAssetId() string {
+func (x *BrowseResponse) GetRoots() []string {
 	if x != nil {
-		return x.AssetId
+		return x.Roots
+	}
+	return nil
+}
+
+func (x *BrowseResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
 	}
 	return ""
 }
 
-func (x *OpenViewRequest) GetUrl() string {
+func (x *BrowseResponse) GetPath() string {
 	if x != nil {
-		return x.Url
+		return x.Path
 	}
 	return ""
 }
 
-func (x *OpenViewRequest) GetFileType() OpenViewRequest_FileType {
+func (x *BrowseResponse) GetEntries() []*BrowseEntry {
 	if x != nil {
-		return x.FileType
+		return x.Entries
 	}
-	return OpenViewRequest_UNKNOWN
+	return nil
 }
 
-func (x *OpenViewRequest) GetTitle() string {
+func (x *BrowseResponse) GetTotal() uint32 {
 	if x != nil {
-		return x.Title
+		return x.Total
+	}
+	return 0
+}
+
+func (x *BrowseResponse) GetView() *OpenViewRequest {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *BrowseResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type AnnotationAction struct {
-	state         protoimpl.MessageState      `protogen:"open.v1"`
-	Type          AnnotationAction_ActionType `protobuf:"varint,1,opt,name=type,proto3,enum=zelland.AnnotationAction_ActionType" json:"type,omitempty"`
-	FilePath      string                      `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"` // Or asset_id
-	Data          *AnnotationData             `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+type BrowseEntry struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Name       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path       string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // Relative to the root; pass back as BrowseRequest.path
+	IsDir      bool                   `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Size       int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	ModifiedAt int64                  `protobuf:"varint,5,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"` // Unix seconds
+	// How OPEN would show the file; UNKNOWN for directories and files that
+	// cannot be viewed
+	FileType      OpenViewRequest_FileType `protobuf:"varint,6,opt,name=file_type,json=fileType,proto3,enum=zelland.OpenViewRequest_FileType" json:"file_type,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AnnotationAction) Reset() {
-	*x = AnnotationAction{}
-	mi := &file_proto_zelland_proto_msgTypes[3]
+func (x *BrowseEntry) Reset() {
+	*x = BrowseEntry{}
+	mi := &file_proto_zelland_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AnnotationAction) String() string {
+func (x *BrowseEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AnnotationAction) ProtoMessage() {}
+func (*BrowseEntry) ProtoMessage() {}
 
-func (x *AnnotationAction) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[3]
+func (x *BrowseEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,58 +3968,80 @@ func (x *AnnotationAction) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AnnotationAction.ProtoReflect.Descriptor instead.
-func (*AnnotationAction) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use BrowseEntry.ProtoReflect.Descriptor instead.
+func (*BrowseEntry) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *AnnotationAction) GetType() AnnotationAction_ActionType {
+func (x *BrowseEntry) GetName() string {
 	if x != nil {
-		return x.Type
+		return x.Name
 	}
-	return AnnotationAction_CREATE
+	return ""
 }
 
-func (x *AnnotationAction) GetFilePath() string {
+func (x *BrowseEntry) GetPath() string {
 	if x != nil {
-		return x.FilePath
+		return x.Path
 	}
 	return ""
 }
 
-func (x *AnnotationAction) GetData() *AnnotationData {
+func (x *BrowseEntry) GetIsDir() bool {
 	if x != nil {
-		return x.Data
+		return x.IsDir
 	}
-	return nil
+	return false
 }
 
-type AnnotationData struct {
+func (x *BrowseEntry) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BrowseEntry) GetModifiedAt() int64 {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return 0
+}
+
+func (x *BrowseEntry) GetFileType() OpenViewRequest_FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return OpenViewRequest_UNKNOWN
+}
+
+// Broadcast when a client saved new content for an asset, so other viewers
+// can reload it.
+type AssetUpdated struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	TargetText    string                 `protobuf:"bytes,2,opt,name=target_text,json=targetText,proto3" json:"target_text,omitempty"`
-	ContextHash   string                 `protobuf:"bytes,3,opt,name=context_hash,json=contextHash,proto3" json:"context_hash,omitempty"` // SHA of surrounding paragraph for robust anchoring
-	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`                                  // The user's note
-	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	AssetId       string                 `protobuf:"bytes,1,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`                        // Hash of the new content, as in the asset's ETag
+	UpdatedBy     string                 `protobuf:"bytes,3,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"` // Token name of the client that saved it
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AnnotationData) Reset() {
-	*x = AnnotationData{}
-	mi := &file_proto_zelland_proto_msgTypes[4]
+func (x *AssetUpdated) Reset() {
+	*x = AssetUpdated{}
+	mi := &file_proto_zelland_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AnnotationData) String() string {
+func (x *AssetUpdated) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AnnotationData) ProtoMessage() {}
+func (*AssetUpdated) ProtoMessage() {}
 
-func (x *AnnotationData) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[4]
+func (x *AssetUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -491,69 +4052,65 @@ func (x *AnnotationData) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AnnotationData.ProtoReflect.Descriptor instead.
-func (*AnnotationData) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *AnnotationData) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
+// Deprecated: Use AssetUpdated.ProtoReflect.Descriptor instead.
+func (*AssetUpdated) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *AnnotationData) GetTargetText() string {
+func (x *AssetUpdated) GetAssetId() string {
 	if x != nil {
-		return x.TargetText
+		return x.AssetId
 	}
 	return ""
 }
 
-func (x *AnnotationData) GetContextHash() string {
+func (x *AssetUpdated) GetSha256() string {
 	if x != nil {
-		return x.ContextHash
+		return x.Sha256
 	}
 	return ""
 }
 
-func (x *AnnotationData) GetBody() string {
+func (x *AssetUpdated) GetUpdatedBy() string {
 	if x != nil {
-		return x.Body
+		return x.UpdatedBy
 	}
 	return ""
 }
 
-func (x *AnnotationData) GetTimestamp() int64 {
+func (x *AssetUpdated) GetSize() int64 {
 	if x != nil {
-		return x.Timestamp
+		return x.Size
 	}
 	return 0
 }
 
-type ClientStatus struct {
+// Sent when the annotations of an open file could not be read, e.g. after a
+// bad hand edit of its sidecar. Keep showing the annotations already
+// received; changes are sent again once the file can be read.
+type AnnotationWarning struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	State         ClientStatus_ViewState `protobuf:"varint,1,opt,name=state,proto3,enum=zelland.ClientStatus_ViewState" json:"state,omitempty"`
-	ActiveAssetId string                 `protobuf:"bytes,2,opt,name=active_asset_id,json=activeAssetId,proto3" json:"active_asset_id,omitempty"`
+	FilePath      string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"` // Asset ID, as in AnnotationAction
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ClientStatus) Reset() {
-	*x = ClientStatus{}
-	mi := &file_proto_zelland_proto_msgTypes[5]
+func (x *AnnotationWarning) Reset() {
+	*x = AnnotationWarning{}
+	mi := &file_proto_zelland_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ClientStatus) String() string {
+func (x *AnnotationWarning) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientStatus) ProtoMessage() {}
+func (*AnnotationWarning) ProtoMessage() {}
 
-func (x *ClientStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_zelland_proto_msgTypes[5]
+func (x *AnnotationWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_zelland_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -564,21 +4121,21 @@ func (x *ClientStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientStatus.ProtoReflect.Descriptor instead.
-func (*ClientStatus) Descriptor() ([]byte, []int) {
-	return file_proto_zelland_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use AnnotationWarning.ProtoReflect.Descriptor instead.
+func (*AnnotationWarning) Descriptor() ([]byte, []int) {
+	return file_proto_zelland_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *ClientStatus) GetState() ClientStatus_ViewState {
+func (x *AnnotationWarning) GetFilePath() string {
 	if x != nil {
-		return x.State
+		return x.FilePath
 	}
-	return ClientStatus_TERMINAL
+	return ""
 }
 
-func (x *ClientStatus) GetActiveAssetId() string {
+func (x *AnnotationWarning) GetMessage() string {
 	if x != nil {
-		return x.ActiveAssetId
+		return x.Message
 	}
 	return ""
 }
@@ -587,31 +4144,71 @@ var File_proto_zelland_proto protoreflect.FileDescriptor
 
 const file_proto_zelland_proto_rawDesc = "" +
 	"\n" +
-	"\x13proto/zelland.proto\x12\azelland\"\xe6\x01\n" +
+	"\x13proto/zelland.proto\x12\azelland\"\xb4\x10\n" +
 	"\bEnvelope\x12(\n" +
 	"\x04ping\x18\x01 \x01(\v2\x12.zelland.KeepAliveH\x00R\x04ping\x127\n" +
 	"\topen_view\x18\x02 \x01(\v2\x18.zelland.OpenViewRequestH\x00R\bopenView\x12;\n" +
 	"\n" +
 	"annotation\x18\x03 \x01(\v2\x19.zelland.AnnotationActionH\x00R\n" +
 	"annotation\x12/\n" +
-	"\x06status\x18\x04 \x01(\v2\x15.zelland.ClientStatusH\x00R\x06statusB\t\n" +
+	"\x06status\x18\x04 \x01(\v2\x15.zelland.ClientStatusH\x00R\x06status\x12%\n" +
+	"\x03log\x18\x05 \x01(\v2\x11.zelland.LogChunkH\x00R\x03log\x12<\n" +
+	"\rlog_subscribe\x18\x06 \x01(\v2\x15.zelland.LogSubscribeH\x00R\flogSubscribe\x12;\n" +
+	"\fnotification\x18\a \x01(\v2\x15.zelland.NotificationH\x00R\fnotification\x12)\n" +
+	"\x06resume\x18\b \x01(\v2\x0f.zelland.ResumeH\x00R\x06resume\x12 \n" +
+	"\x03ack\x18\t \x01(\v2\f.zelland.AckH\x00R\x03ack\x12&\n" +
+	"\x05hello\x18\n" +
+	" \x01(\v2\x0e.zelland.HelloH\x00R\x05hello\x12,\n" +
+	"\awelcome\x18\v \x01(\v2\x10.zelland.WelcomeH\x00R\awelcome\x12I\n" +
+	"\x12zellij_web_request\x18\f \x01(\v2\x19.zelland.ZellijWebRequestH\x00R\x10zellijWebRequest\x12L\n" +
+	"\x13zellij_web_response\x18\r \x01(\v2\x1a.zelland.ZellijWebResponseH\x00R\x11zellijWebResponse\x12B\n" +
+	"\x0fsession_request\x18\x0e \x01(\v2\x17.zelland.SessionRequestH\x00R\x0esessionRequest\x12E\n" +
+	"\x10session_response\x18\x0f \x01(\v2\x18.zelland.SessionResponseH\x00R\x0fsessionResponse\x12<\n" +
+	"\rsession_event\x18\x10 \x01(\v2\x15.zelland.SessionEventH\x00R\fsessionEvent\x12B\n" +
+	"\x0fcapture_request\x18\x11 \x01(\v2\x17.zelland.CaptureRequestH\x00R\x0ecaptureRequest\x12E\n" +
+	"\x10capture_response\x18\x12 \x01(\v2\x18.zelland.CaptureResponseH\x00R\x0fcaptureResponse\x12?\n" +
+	"\x0eaction_request\x18\x13 \x01(\v2\x16.zelland.ActionRequestH\x00R\ractionRequest\x12B\n" +
+	"\x0faction_response\x18\x14 \x01(\v2\x17.zelland.ActionResponseH\x00R\x0eactionResponse\x12C\n" +
+	"\x10run_quick_action\x18\x15 \x01(\v2\x17.zelland.RunQuickActionH\x00R\x0erunQuickAction\x12L\n" +
+	"\x13quick_action_result\x18\x16 \x01(\v2\x1a.zelland.QuickActionResultH\x00R\x11quickActionResult\x12)\n" +
+	"\x06prompt\x18\x17 \x01(\v2\x0f.zelland.PromptH\x00R\x06prompt\x12B\n" +
+	"\x0fprompt_response\x18\x18 \x01(\v2\x17.zelland.PromptResponseH\x00R\x0epromptResponse\x12<\n" +
+	"\rprompt_closed\x18\x19 \x01(\v2\x15.zelland.PromptClosedH\x00R\fpromptClosed\x12<\n" +
+	"\rclipboard_set\x18\x1a \x01(\v2\x15.zelland.ClipboardSetH\x00R\fclipboardSet\x12H\n" +
+	"\x11clipboard_request\x18\x1b \x01(\v2\x19.zelland.ClipboardRequestH\x00R\x10clipboardRequest\x12H\n" +
+	"\x11clipboard_content\x18\x1c \x01(\v2\x19.zelland.ClipboardContentH\x00R\x10clipboardContent\x12B\n" +
+	"\x0freceive_request\x18\x1d \x01(\v2\x17.zelland.ReceiveRequestH\x00R\x0ereceiveRequest\x12?\n" +
+	"\x0ebrowse_request\x18\x1e \x01(\v2\x16.zelland.BrowseRequestH\x00R\rbrowseRequest\x12B\n" +
+	"\x0fbrowse_response\x18\x1f \x01(\v2\x17.zelland.BrowseResponseH\x00R\x0ebrowseResponse\x12<\n" +
+	"\rasset_updated\x18  \x01(\v2\x15.zelland.AssetUpdatedH\x00R\fassetUpdated\x12K\n" +
+	"\x12annotation_warning\x18! \x01(\v2\x1a.zelland.AnnotationWarningH\x00R\x11annotationWarning\x12\x10\n" +
+	"\x03seq\x18d \x01(\x04R\x03seq\x12\x1d\n" +
+	"\n" +
+	"request_id\x18e \x01(\tR\trequestIdB\t\n" +
 	"\apayload\")\n" +
 	"\tKeepAlive\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"\xcf\x01\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"\x95\x02\n" +
 	"\x0fOpenViewRequest\x12\x19\n" +
 	"\basset_id\x18\x01 \x01(\tR\aassetId\x12\x10\n" +
 	"\x03url\x18\x02 \x01(\tR\x03url\x12>\n" +
 	"\tfile_type\x18\x03 \x01(\x0e2!.zelland.OpenViewRequest.FileTypeR\bfileType\x12\x14\n" +
-	"\x05title\x18\x04 \x01(\tR\x05title\"9\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12'\n" +
+	"\x06origin\x18\x05 \x01(\v2\x0f.zelland.OriginR\x06origin\"V\n" +
 	"\bFileType\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\t\n" +
 	"\x05IMAGE\x10\x01\x12\f\n" +
 	"\bMARKDOWN\x10\x02\x12\a\n" +
-	"\x03PDF\x10\x03\"\xc8\x01\n" +
+	"\x03PDF\x10\x03\x12\a\n" +
+	"\x03LOG\x10\x04\x12\b\n" +
+	"\x04TEXT\x10\x05\x12\b\n" +
+	"\x04CODE\x10\x06\"U\n" +
+	"\x06Origin\x12%\n" +
+	"\x0ezellij_session\x18\x01 \x01(\tR\rzellijSession\x12$\n" +
+	"\x0ezellij_pane_id\x18\x02 \x01(\tR\fzellijPaneId\"\xec\x01\n" +
 	"\x10AnnotationAction\x128\n" +
 	"\x04type\x18\x01 \x01(\x0e2$.zelland.AnnotationAction.ActionTypeR\x04type\x12\x1b\n" +
 	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12+\n" +
-	"\x04data\x18\x03 \x01(\v2\x17.zelland.AnnotationDataR\x04data\"0\n" +
+	"\x04data\x18\x03 \x01(\v2\x17.zelland.AnnotationDataR\x04data\"T\n" +
 	"\n" +
 	"ActionType\x12\n" +
 	"\n" +
@@ -619,21 +4216,286 @@ const file_proto_zelland_proto_rawDesc = "" +
 	"\n" +
 	"\x06UPDATE\x10\x01\x12\n" +
 	"\n" +
-	"\x06DELETE\x10\x02\"\x96\x01\n" +
+	"\x06DELETE\x10\x02\x12\t\n" +
+	"\x05REPLY\x10\x03\x12\v\n" +
+	"\aRESOLVE\x10\x04\x12\n" +
+	"\n" +
+	"\x06REOPEN\x10\x05\"\xbf\x03\n" +
 	"\x0eAnnotationData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
 	"\vtarget_text\x18\x02 \x01(\tR\n" +
 	"targetText\x12!\n" +
 	"\fcontext_hash\x18\x03 \x01(\tR\vcontextHash\x12\x12\n" +
 	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1c\n" +
-	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\x94\x01\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04user\x18\x06 \x01(\tR\x04user\x12\x1b\n" +
+	"\tparent_id\x18\a \x01(\tR\bparentId\x126\n" +
+	"\x06status\x18\b \x01(\x0e2\x1e.zelland.AnnotationData.StatusR\x06status\x12\x1f\n" +
+	"\vresolved_by\x18\t \x01(\tR\n" +
+	"resolvedBy\x12\x1f\n" +
+	"\vresolved_at\x18\n" +
+	" \x01(\x03R\n" +
+	"resolvedAt\x121\n" +
+	"\areplies\x18\v \x03(\v2\x17.zelland.AnnotationDataR\areplies\x12'\n" +
+	"\x06anchor\x18\f \x01(\v2\x0f.zelland.AnchorR\x06anchor\" \n" +
+	"\x06Status\x12\b\n" +
+	"\x04OPEN\x10\x00\x12\f\n" +
+	"\bRESOLVED\x10\x01\"\xff\x01\n" +
+	"\x06Anchor\x12(\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x14.zelland.Anchor.KindR\x04kind\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x01R\x01y\x12\f\n" +
+	"\x01w\x18\x04 \x01(\x01R\x01w\x12\f\n" +
+	"\x01h\x18\x05 \x01(\x01R\x01h\x12\x12\n" +
+	"\x04page\x18\x06 \x01(\rR\x04page\x12\x14\n" +
+	"\x05quote\x18\a \x01(\tR\x05quote\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\b \x01(\rR\tstartLine\x12\x19\n" +
+	"\bend_line\x18\t \x01(\rR\aendLine\"/\n" +
+	"\x04Kind\x12\b\n" +
+	"\x04TEXT\x10\x00\x12\b\n" +
+	"\x04RECT\x10\x01\x12\b\n" +
+	"\x04PAGE\x10\x02\x12\t\n" +
+	"\x05LINES\x10\x03\"\xdb\x01\n" +
 	"\fClientStatus\x125\n" +
 	"\x05state\x18\x01 \x01(\x0e2\x1f.zelland.ClientStatus.ViewStateR\x05state\x12&\n" +
-	"\x0factive_asset_id\x18\x02 \x01(\tR\ractiveAssetId\"%\n" +
+	"\x0factive_asset_id\x18\x02 \x01(\tR\ractiveAssetId\x12\x1e\n" +
+	"\n" +
+	"foreground\x18\x03 \x01(\bR\n" +
+	"foreground\x12%\n" +
+	"\x0ezellij_session\x18\x04 \x01(\tR\rzellijSession\"%\n" +
 	"\tViewState\x12\f\n" +
 	"\bTERMINAL\x10\x00\x12\n" +
 	"\n" +
-	"\x06VIEWER\x10\x01B>\n" +
+	"\x06VIEWER\x10\x01\"\xb6\x01\n" +
+	"\bLogChunk\x12\x1b\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12&\n" +
+	"\x05lines\x18\x02 \x03(\v2\x10.zelland.LogLineR\x05lines\x12-\n" +
+	"\x05event\x18\x03 \x01(\x0e2\x17.zelland.LogChunk.EventR\x05event\"6\n" +
+	"\x05Event\x12\b\n" +
+	"\x04NONE\x10\x00\x12\r\n" +
+	"\tTRUNCATED\x10\x01\x12\v\n" +
+	"\aROTATED\x10\x02\x12\a\n" +
+	"\x03EOF\x10\x03\"/\n" +
+	"\aLogLine\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"\x82\x01\n" +
+	"\fLogSubscribe\x12\x1b\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x04R\bafterSeq\x12\x16\n" +
+	"\x06filter\x18\x03 \x01(\tR\x06filter\x12 \n" +
+	"\vunsubscribe\x18\x04 \x01(\bR\vunsubscribe\"\xa4\x02\n" +
+	"\fNotification\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x127\n" +
+	"\aurgency\x18\x04 \x01(\x0e2\x1d.zelland.Notification.UrgencyR\aurgency\x120\n" +
+	"\x06action\x18\x05 \x01(\v2\x18.zelland.OpenViewRequestR\x06action\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12'\n" +
+	"\x06origin\x18\a \x01(\v2\x0f.zelland.OriginR\x06origin\"(\n" +
+	"\aUrgency\x12\n" +
+	"\n" +
+	"\x06NORMAL\x10\x00\x12\a\n" +
+	"\x03LOW\x10\x01\x12\b\n" +
+	"\x04HIGH\x10\x02\"#\n" +
+	"\x06Resume\x12\x19\n" +
+	"\blast_seq\x18\x01 \x01(\x04R\alastSeq\"\x9a\x01\n" +
+	"\x03Ack\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.zelland.Ack.StatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"1\n" +
+	"\x06Status\x12\f\n" +
+	"\bRECEIVED\x10\x00\x12\r\n" +
+	"\tDISPLAYED\x10\x01\x12\n" +
+	"\n" +
+	"\x06FAILED\x10\x02\"\x9d\x02\n" +
+	"\x05Hello\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\rR\x0fprotocolVersion\x12\x1f\n" +
+	"\vapp_version\x18\x02 \x01(\tR\n" +
+	"appVersion\x12\x1f\n" +
+	"\vdevice_name\x18\x03 \x01(\tR\n" +
+	"deviceName\x12S\n" +
+	"\x14supported_file_types\x18\x04 \x03(\x0e2!.zelland.OpenViewRequest.FileTypeR\x12supportedFileTypes\x12\x1a\n" +
+	"\bfeatures\x18\x05 \x03(\tR\bfeatures\x12\x19\n" +
+	"\blast_seq\x18\x06 \x01(\x04R\alastSeq\x12\x1b\n" +
+	"\tdevice_id\x18\a \x01(\tR\bdeviceId\"\xd9\x01\n" +
+	"\aWelcome\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\rR\x0fprotocolVersion\x12%\n" +
+	"\x0eserver_version\x18\x02 \x01(\tR\rserverVersion\x12\x1a\n" +
+	"\bfeatures\x18\x03 \x03(\tR\bfeatures\x12%\n" +
+	"\x0ezellij_actions\x18\x04 \x03(\tR\rzellijActions\x129\n" +
+	"\rquick_actions\x18\x05 \x03(\v2\x14.zelland.QuickActionR\fquickActions\"\xcb\x01\n" +
+	"\vQuickAction\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\bR\aconfirm\x123\n" +
+	"\x06output\x18\x05 \x01(\x0e2\x1b.zelland.QuickAction.OutputR\x06output\"\x1d\n" +
+	"\x06Output\x12\a\n" +
+	"\x03LOG\x10\x00\x12\n" +
+	"\n" +
+	"\x06NOTIFY\x10\x01\"\xb3\x01\n" +
+	"\x10ZellijWebRequest\x128\n" +
+	"\x06action\x18\x01 \x01(\x0e2 .zelland.ZellijWebRequest.ActionR\x06action\x12\x1b\n" +
+	"\tlog_lines\x18\x02 \x01(\rR\blogLines\"H\n" +
+	"\x06Action\x12\n" +
+	"\n" +
+	"\x06STATUS\x10\x00\x12\t\n" +
+	"\x05START\x10\x01\x12\b\n" +
+	"\x04STOP\x10\x02\x12\v\n" +
+	"\aRESTART\x10\x03\x12\x10\n" +
+	"\fCREATE_TOKEN\x10\x04\"\xd7\x02\n" +
+	"\x11ZellijWebResponse\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12\x1e\n" +
+	"\n" +
+	"supervised\x18\x02 \x01(\bR\n" +
+	"supervised\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04port\x18\x04 \x01(\rR\x04port\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\x03R\tstartedAt\x12\x1a\n" +
+	"\brestarts\x18\x06 \x01(\rR\brestarts\x12\x1b\n" +
+	"\tlast_exit\x18\a \x01(\tR\blastExit\x12\x1d\n" +
+	"\n" +
+	"token_name\x18\b \x01(\tR\ttokenName\x12\x14\n" +
+	"\x05token\x18\t \x01(\tR\x05token\x12\x1b\n" +
+	"\tlog_lines\x18\n" +
+	" \x03(\tR\blogLines\x12\"\n" +
+	"\rlog_stream_id\x18\v \x01(\tR\vlogStreamId\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\"\xe7\x01\n" +
+	"\x0eSessionRequest\x126\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x1e.zelland.SessionRequest.ActionR\x06action\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06layout\x18\x03 \x01(\tR\x06layout\x12\x19\n" +
+	"\bnew_name\x18\x04 \x01(\tR\anewName\x12\x14\n" +
+	"\x05force\x18\x05 \x01(\bR\x05force\"@\n" +
+	"\x06Action\x12\b\n" +
+	"\x04LIST\x10\x00\x12\n" +
+	"\n" +
+	"\x06CREATE\x10\x01\x12\n" +
+	"\n" +
+	"\x06RENAME\x10\x02\x12\b\n" +
+	"\x04KILL\x10\x03\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x04\"[\n" +
+	"\x0fSessionResponse\x122\n" +
+	"\bsessions\x18\x01 \x03(\v2\x16.zelland.ZellijSessionR\bsessions\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x85\x01\n" +
+	"\rZellijSession\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12)\n" +
+	"\x10attached_clients\x18\x03 \x01(\rR\x0fattachedClients\x12\x16\n" +
+	"\x06exited\x18\x04 \x01(\bR\x06exited\"\x9c\x01\n" +
+	"\fSessionEvent\x12.\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1a.zelland.SessionEvent.TypeR\x04type\x120\n" +
+	"\asession\x18\x02 \x01(\v2\x16.zelland.ZellijSessionR\asession\"*\n" +
+	"\x04Type\x12\t\n" +
+	"\x05ADDED\x10\x00\x12\v\n" +
+	"\aREMOVED\x10\x01\x12\n" +
+	"\n" +
+	"\x06EXITED\x10\x02\"K\n" +
+	"\x0eCaptureRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\x12\x1f\n" +
+	"\vscreen_only\x18\x02 \x01(\bR\n" +
+	"screenOnly\"U\n" +
+	"\x0fCaptureResponse\x12,\n" +
+	"\x04view\x18\x01 \x01(\v2\x18.zelland.OpenViewRequestR\x04view\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"o\n" +
+	"\rActionRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x12\n" +
+	"\x04args\x18\x03 \x03(\tR\x04args\x12\x18\n" +
+	"\acommand\x18\x04 \x03(\tR\acommand\"[\n" +
+	"\x0eActionResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\tR\x06output\x12\x1b\n" +
+	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"Z\n" +
+	"\x0eRunQuickAction\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tconfirmed\x18\x02 \x01(\bR\tconfirmed\x12\x16\n" +
+	"\x06cancel\x18\x03 \x01(\bR\x06cancel\"\x98\x01\n" +
+	"\x11QuickActionResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tstream_id\x18\x02 \x01(\tR\bstreamId\x12\x1b\n" +
+	"\texit_code\x18\x03 \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\x96\x01\n" +
+	"\x06Prompt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x18\n" +
+	"\achoices\x18\x03 \x03(\tR\achoices\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12'\n" +
+	"\x06origin\x18\x05 \x01(\v2\x0f.zelland.OriginR\x06origin\"V\n" +
+	"\x0ePromptResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06answer\x18\x02 \x01(\tR\x06answer\x12\x1c\n" +
+	"\tdismissed\x18\x03 \x01(\bR\tdismissed\"\x1e\n" +
+	"\fPromptClosed\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"x\n" +
+	"\fClipboardSet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12'\n" +
+	"\x06origin\x18\x04 \x01(\v2\x0f.zelland.OriginR\x06origin\"\x87\x01\n" +
+	"\x10ClipboardRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x03R\bmaxBytes\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12'\n" +
+	"\x06origin\x18\x04 \x01(\v2\x0f.zelland.OriginR\x06origin\"\x81\x01\n" +
+	"\x10ClipboardContent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x16\n" +
+	"\x06denied\x18\x04 \x01(\bR\x06denied\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"z\n" +
+	"\x0eReceiveRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03dir\x18\x02 \x01(\tR\x03dir\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12'\n" +
+	"\x06origin\x18\x04 \x01(\v2\x0f.zelland.OriginR\x06origin\"\xdc\x01\n" +
+	"\rBrowseRequest\x125\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x1d.zelland.BrowseRequest.ActionR\x06action\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\rR\x06offset\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\rR\x05limit\"(\n" +
+	"\x06Action\x12\b\n" +
+	"\x04LIST\x10\x00\x12\n" +
+	"\n" +
+	"\x06SEARCH\x10\x01\x12\b\n" +
+	"\x04OPEN\x10\x02\"\xd8\x01\n" +
+	"\x0eBrowseResponse\x12\x14\n" +
+	"\x05roots\x18\x01 \x03(\tR\x05roots\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12.\n" +
+	"\aentries\x18\x04 \x03(\v2\x14.zelland.BrowseEntryR\aentries\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\rR\x05total\x12,\n" +
+	"\x04view\x18\x06 \x01(\v2\x18.zelland.OpenViewRequestR\x04view\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xc1\x01\n" +
+	"\vBrowseEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x15\n" +
+	"\x06is_dir\x18\x03 \x01(\bR\x05isDir\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vmodified_at\x18\x05 \x01(\x03R\n" +
+	"modifiedAt\x12>\n" +
+	"\tfile_type\x18\x06 \x01(\x0e2!.zelland.OpenViewRequest.FileTypeR\bfileType\"t\n" +
+	"\fAssetUpdated\x12\x19\n" +
+	"\basset_id\x18\x01 \x01(\tR\aassetId\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x03 \x01(\tR\tupdatedBy\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"J\n" +
+	"\x11AnnotationWarning\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessageB>\n" +
 	"\x11com.zelland.protoP\x01Z'github.com/zelland/daemon/proto/zellandb\x06proto3"
 
 var (
@@ -648,33 +4510,135 @@ func file_proto_zelland_proto_rawDescGZIP() []byte {
 	return file_proto_zelland_proto_rawDescData
 }
 
-var file_proto_zelland_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_proto_zelland_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_zelland_proto_enumTypes = make([]protoimpl.EnumInfo, 13)
+var file_proto_zelland_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_proto_zelland_proto_goTypes = []any{
 	(OpenViewRequest_FileType)(0),    // 0: zelland.OpenViewRequest.FileType
 	(AnnotationAction_ActionType)(0), // 1: zelland.AnnotationAction.ActionType
-	(ClientStatus_ViewState)(0),      // 2: zelland.ClientStatus.ViewState
-	(*Envelope)(nil),                 // 3: zelland.Envelope
-	(*KeepAlive)(nil),                // 4: zelland.KeepAlive
-	(*OpenViewRequest)(nil),          // 5: zelland.OpenViewRequest
-	(*AnnotationAction)(nil),         // 6: zelland.AnnotationAction
-	(*AnnotationData)(nil),           // 7: zelland.AnnotationData
-	(*ClientStatus)(nil),             // 8: zelland.ClientStatus
+	(AnnotationData_Status)(0),       // 2: zelland.AnnotationData.Status
+	(Anchor_Kind)(0),                 // 3: zelland.Anchor.Kind
+	(ClientStatus_ViewState)(0),      // 4: zelland.ClientStatus.ViewState
+	(LogChunk_Event)(0),              // 5: zelland.LogChunk.Event
+	(Notification_Urgency)(0),        // 6: zelland.Notification.Urgency
+	(Ack_Status)(0),                  // 7: zelland.Ack.Status
+	(QuickAction_Output)(0),          // 8: zelland.QuickAction.Output
+	(ZellijWebRequest_Action)(0),     // 9: zelland.ZellijWebRequest.Action
+	(SessionRequest_Action)(0),       // 10: zelland.SessionRequest.Action
+	(SessionEvent_Type)(0),           // 11: zelland.SessionEvent.Type
+	(BrowseRequest_Action)(0),        // 12: zelland.BrowseRequest.Action
+	(*Envelope)(nil),                 // 13: zelland.Envelope
+	(*KeepAlive)(nil),                // 14: zelland.KeepAlive
+	(*OpenViewRequest)(nil),          // 15: zelland.OpenViewRequest
+	(*Origin)(nil),                   // 16: zelland.Origin
+	(*AnnotationAction)(nil),         // 17: zelland.AnnotationAction
+	(*AnnotationData)(nil),           // 18: zelland.AnnotationData
+	(*Anchor)(nil),                   // 19: zelland.Anchor
+	(*ClientStatus)(nil),             // 20: zelland.ClientStatus
+	(*LogChunk)(nil),                 // 21: zelland.LogChunk
+	(*LogLine)(nil),                  // 22: zelland.LogLine
+	(*LogSubscribe)(nil),             // 23: zelland.LogSubscribe
+	(*Notification)(nil),             // 24: zelland.Notification
+	(*Resume)(nil),                   // 25: zelland.Resume
+	(*Ack)(nil),                      // 26: zelland.Ack
+	(*Hello)(nil),                    // 27: zelland.Hello
+	(*Welcome)(nil),                  // 28: zelland.Welcome
+	(*QuickAction)(nil),              // 29: zelland.QuickAction
+	(*ZellijWebRequest)(nil),         // 30: zelland.ZellijWebRequest
+	(*ZellijWebResponse)(nil),        // 31: zelland.ZellijWebResponse
+	(*SessionRequest)(nil),           // 32: zelland.SessionRequest
+	(*SessionResponse)(nil),          // 33: zelland.SessionResponse
+	(*ZellijSession)(nil),            // 34: zelland.ZellijSession
+	(*SessionEvent)(nil),             // 35: zelland.SessionEvent
+	(*CaptureRequest)(nil),           // 36: zelland.CaptureRequest
+	(*CaptureResponse)(nil),          // 37: zelland.CaptureResponse
+	(*ActionRequest)(nil),            // 38: zelland.ActionRequest
+	(*ActionResponse)(nil),           // 39: zelland.ActionResponse
+	(*RunQuickAction)(nil),           // 40: zelland.RunQuickAction
+	(*QuickActionResult)(nil),        // 41: zelland.QuickActionResult
+	(*Prompt)(nil),                   // 42: zelland.Prompt
+	(*PromptResponse)(nil),           // 43: zelland.PromptResponse
+	(*PromptClosed)(nil),             // 44: zelland.PromptClosed
+	(*ClipboardSet)(nil),             // 45: zelland.ClipboardSet
+	(*ClipboardRequest)(nil),         // 46: zelland.ClipboardRequest
+	(*ClipboardContent)(nil),         // 47: zelland.ClipboardContent
+	(*ReceiveRequest)(nil),           // 48: zelland.ReceiveRequest
+	(*BrowseRequest)(nil),            // 49: zelland.BrowseRequest
+	(*BrowseResponse)(nil),           // 50: zelland.BrowseResponse
+	(*BrowseEntry)(nil),              // 51: zelland.BrowseEntry
+	(*AssetUpdated)(nil),             // 52: zelland.AssetUpdated
+	(*AnnotationWarning)(nil),        // 53: zelland.AnnotationWarning
 }
 var file_proto_zelland_proto_depIdxs = []int32{
-	4, // 0: zelland.Envelope.ping:type_name -> zelland.KeepAlive
-	5, // 1: zelland.Envelope.open_view:type_name -> zelland.OpenViewRequest
-	6, // 2: zelland.Envelope.annotation:type_name -> zelland.AnnotationAction
-	8, // 3: zelland.Envelope.status:type_name -> zelland.ClientStatus
-	0, // 4: zelland.OpenViewRequest.file_type:type_name -> zelland.OpenViewRequest.FileType
-	1, // 5: zelland.AnnotationAction.type:type_name -> zelland.AnnotationAction.ActionType
-	7, // 6: zelland.AnnotationAction.data:type_name -> zelland.AnnotationData
-	2, // 7: zelland.ClientStatus.state:type_name -> zelland.ClientStatus.ViewState
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	14, // 0: zelland.Envelope.ping:type_name -> zelland.KeepAlive
+	15, // 1: zelland.Envelope.open_view:type_name -> zelland.OpenViewRequest
+	17, // 2: zelland.Envelope.annotation:type_name -> zelland.AnnotationAction
+	20, // 3: zelland.Envelope.status:type_name -> zelland.ClientStatus
+	21, // 4: zelland.Envelope.log:type_name -> zelland.LogChunk
+	23, // 5: zelland.Envelope.log_subscribe:type_name -> zelland.LogSubscribe
+	24, // 6: zelland.Envelope.notification:type_name -> zelland.Notification
+	25, // 7: zelland.Envelope.resume:type_name -> zelland.Resume
+	26, // 8: zelland.Envelope.ack:type_name -> zelland.Ack
+	27, // 9: zelland.Envelope.hello:type_name -> zelland.Hello
+	28, // 10: zelland.Envelope.welcome:type_name -> zelland.Welcome
+	30, // 11: zelland.Envelope.zellij_web_request:type_name -> zelland.ZellijWebRequest
+	31, // 12: zelland.Envelope.zellij_web_response:type_name -> zelland.ZellijWebResponse
+	32, // 13: zelland.Envelope.session_request:type_name -> zelland.SessionRequest
+	33, // 14: zelland.Envelope.session_response:type_name -> zelland.SessionResponse
+	35, // 15: zelland.Envelope.session_event:type_name -> zelland.SessionEvent
+	36, // 16: zelland.Envelope.capture_request:type_name -> zelland.CaptureRequest
+	37, // 17: zelland.Envelope.capture_response:type_name -> zelland.CaptureResponse
+	38, // 18: zelland.Envelope.action_request:type_name -> zelland.ActionRequest
+	39, // 19: zelland.Envelope.action_response:type_name -> zelland.ActionResponse
+	40, // 20: zelland.Envelope.run_quick_action:type_name -> zelland.RunQuickAction
+	41, // 21: zelland.Envelope.quick_action_result:type_name -> zelland.QuickActionResult
+	42, // 22: zelland.Envelope.prompt:type_name -> zelland.Prompt
+	43, // 23: zelland.Envelope.prompt_response:type_name -> zelland.PromptResponse
+	44, // 24: zelland.Envelope.prompt_closed:type_name -> zelland.PromptClosed
+	45, // 25: zelland.Envelope.clipboard_set:type_name -> zelland.ClipboardSet
+	46, // 26: zelland.Envelope.clipboard_request:type_name -> zelland.ClipboardRequest
+	47, // 27: zelland.Envelope.clipboard_content:type_name -> zelland.ClipboardContent
+	48, // 28: zelland.Envelope.receive_request:type_name -> zelland.ReceiveRequest
+	49, // 29: zelland.Envelope.browse_request:type_name -> zelland.BrowseRequest
+	50, // 30: zelland.Envelope.browse_response:type_name -> zelland.BrowseResponse
+	52, // 31: zelland.Envelope.asset_updated:type_name -> zelland.AssetUpdated
+	53, // 32: zelland.Envelope.annotation_warning:type_name -> zelland.AnnotationWarning
+	0,  // 33: zelland.OpenViewRequest.file_type:type_name -> zelland.OpenViewRequest.FileType
+	16, // 34: zelland.OpenViewRequest.origin:type_name -> zelland.Origin
+	1,  // 35: zelland.AnnotationAction.type:type_name -> zelland.AnnotationAction.ActionType
+	18, // 36: zelland.AnnotationAction.data:type_name -> zelland.AnnotationData
+	2,  // 37: zelland.AnnotationData.status:type_name -> zelland.AnnotationData.Status
+	18, // 38: zelland.AnnotationData.replies:type_name -> zelland.AnnotationData
+	19, // 39: zelland.AnnotationData.anchor:type_name -> zelland.Anchor
+	3,  // 40: zelland.Anchor.kind:type_name -> zelland.Anchor.Kind
+	4,  // 41: zelland.ClientStatus.state:type_name -> zelland.ClientStatus.ViewState
+	22, // 42: zelland.LogChunk.lines:type_name -> zelland.LogLine
+	5,  // 43: zelland.LogChunk.event:type_name -> zelland.LogChunk.Event
+	6,  // 44: zelland.Notification.urgency:type_name -> zelland.Notification.Urgency
+	15, // 45: zelland.Notification.action:type_name -> zelland.OpenViewRequest
+	16, // 46: zelland.Notification.origin:type_name -> zelland.Origin
+	7,  // 47: zelland.Ack.status:type_name -> zelland.Ack.Status
+	0,  // 48: zelland.Hello.supported_file_types:type_name -> zelland.OpenViewRequest.FileType
+	29, // 49: zelland.Welcome.quick_actions:type_name -> zelland.QuickAction
+	8,  // 50: zelland.QuickAction.output:type_name -> zelland.QuickAction.Output
+	9,  // 51: zelland.ZellijWebRequest.action:type_name -> zelland.ZellijWebRequest.Action
+	10, // 52: zelland.SessionRequest.action:type_name -> zelland.SessionRequest.Action
+	34, // 53: zelland.SessionResponse.sessions:type_name -> zelland.ZellijSession
+	11, // 54: zelland.SessionEvent.type:type_name -> zelland.SessionEvent.Type
+	34, // 55: zelland.SessionEvent.session:type_name -> zelland.ZellijSession
+	15, // 56: zelland.CaptureResponse.view:type_name -> zelland.OpenViewRequest
+	16, // 57: zelland.Prompt.origin:type_name -> zelland.Origin
+	16, // 58: zelland.ClipboardSet.origin:type_name -> zelland.Origin
+	16, // 59: zelland.ClipboardRequest.origin:type_name -> zelland.Origin
+	16, // 60: zelland.ReceiveRequest.origin:type_name -> zelland.Origin
+	12, // 61: zelland.BrowseRequest.action:type_name -> zelland.BrowseRequest.Action
+	51, // 62: zelland.BrowseResponse.entries:type_name -> zelland.BrowseEntry
+	15, // 63: zelland.BrowseResponse.view:type_name -> zelland.OpenViewRequest
+	0,  // 64: zelland.BrowseEntry.file_type:type_name -> zelland.OpenViewRequest.FileType
+	65, // [65:65] is the sub-list for method output_type
+	65, // [65:65] is the sub-list for method input_type
+	65, // [65:65] is the sub-list for extension type_name
+	65, // [65:65] is the sub-list for extension extendee
+	0,  // [0:65] is the sub-list for field type_name
 }
 
 func init() { file_proto_zelland_proto_init() }
@@ -687,14 +4651,43 @@ func file_proto_zelland_proto_init() {
 		(*Envelope_OpenView)(nil),
 		(*Envelope_Annotation)(nil),
 		(*Envelope_Status)(nil),
+		(*Envelope_Log)(nil),
+		(*Envelope_LogSubscribe)(nil),
+		(*Envelope_Notification)(nil),
+		(*Envelope_Resume)(nil),
+		(*Envelope_Ack)(nil),
+		(*Envelope_Hello)(nil),
+		(*Envelope_Welcome)(nil),
+		(*Envelope_ZellijWebRequest)(nil),
+		(*Envelope_ZellijWebResponse)(nil),
+		(*Envelope_SessionRequest)(nil),
+		(*Envelope_SessionResponse)(nil),
+		(*Envelope_SessionEvent)(nil),
+		(*Envelope_CaptureRequest)(nil),
+		(*Envelope_CaptureResponse)(nil),
+		(*Envelope_ActionRequest)(nil),
+		(*Envelope_ActionResponse)(nil),
+		(*Envelope_RunQuickAction)(nil),
+		(*Envelope_QuickActionResult)(nil),
+		(*Envelope_Prompt)(nil),
+		(*Envelope_PromptResponse)(nil),
+		(*Envelope_PromptClosed)(nil),
+		(*Envelope_ClipboardSet)(nil),
+		(*Envelope_ClipboardRequest)(nil),
+		(*Envelope_ClipboardContent)(nil),
+		(*Envelope_ReceiveRequest)(nil),
+		(*Envelope_BrowseRequest)(nil),
+		(*Envelope_BrowseResponse)(nil),
+		(*Envelope_AssetUpdated)(nil),
+		(*Envelope_AnnotationWarning)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_zelland_proto_rawDesc), len(file_proto_zelland_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   6,
+			NumEnums:      13,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   0,
 		},